@@ -45,8 +45,9 @@ interface ISequencerInbox is IDAProvider {
      * txBatchData format:
      *   txBatchData = version || batchData (|| is concatenation)
      *   where:
-     *   - version: uint8
+     *   - version: uint8 (0: RLP-encoded batchData; 1: compressed RLP-encoded batchData)
      *   - data: bytes
+     *   or, if the batch is carried in the tx's blobs, txBatchData = 0xb0 (the blob marker alone).
      * batchData format:
      *   batchData = RLP([firstL2BlockNum, batchList])
      *   where:
@@ -32,8 +32,10 @@ import "./libraries/DeserializationLib.sol";
 import "./libraries/Errors.sol";
 
 contract SequencerInbox is ISequencerInbox, Initializable, UUPSUpgradeable, OwnableUpgradeable, PausableUpgradeable {
-    // Current txBatch serialization version
-    uint8 public constant currentTxBatchVersion = 0;
+    // Latest txBatch serialization version (all versions up to it are accepted)
+    uint8 public constant currentTxBatchVersion = 1;
+    // Marker of a txBatch carried in the blobs of the appending tx (the marker being the whole txBatchData)
+    uint8 public constant blobTxBatchMarker = 0xb0;
 
     address public sequencerAddress;
 
@@ -71,7 +73,9 @@ contract SequencerInbox is ISequencerInbox, Initializable, UUPSUpgradeable, Owna
             revert TxBatchDataUnderflow();
         }
         uint8 txBatchVersion = uint8(txBatchData[0]);
-        if (txBatchVersion != currentTxBatchVersion) {
+        // TODO: check that the tx carries blobs (BLOBHASH), once compiling for Cancun.
+        bool isBlobTxBatch = txBatchVersion == blobTxBatchMarker && txBatchData.length == 1;
+        if (txBatchVersion > currentTxBatchVersion && !isBlobTxBatch) {
             revert TxBatchVersionIncorrect();
         }
         emit TxBatchAppended();
@@ -71,6 +71,7 @@ contract AsymChallenge is ChallengeBase, IAsymChallenge {
         resultReceiver = _resultReceiver;
 
         turn = Turn.Defender;
+        creationBlock = block.number;
         lastMoveBlock = block.number;
         defenderTimeLeft = challengePeriod;
         challengerTimeLeft = challengePeriod;
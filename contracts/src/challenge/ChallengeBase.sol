@@ -41,6 +41,8 @@ abstract contract ChallengeBase is IChallenge {
     address public defender;
     address public challenger;
     uint256 public lastMoveBlock;
+    // L1 block the challenge was created in (from which its events can be looked up).
+    uint256 public creationBlock;
     uint256 public defenderTimeLeft;
     uint256 public challengerTimeLeft;
 
@@ -51,7 +51,7 @@ contract SymChallenge is ChallengeBase, ISymChallenge {
      * @notice Ensures challenge has been initialized.
      */
     modifier postInitialization() {
-        if (bisectionHash != 0) {
+        if (bisectionHash == 0) {
             revert NotInitialized();
         }
         _;
@@ -96,6 +96,7 @@ contract SymChallenge is ChallengeBase, ISymChallenge {
         endStateChallengeHash = _endStateChallengeHash;
 
         turn = Turn.Defender;
+        creationBlock = block.number;
         lastMoveBlock = block.number;
         defenderTimeLeft = challengePeriod;
         challengerTimeLeft = challengePeriod;
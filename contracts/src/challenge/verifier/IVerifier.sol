@@ -25,7 +25,9 @@ interface IVerifier {
      * @notice Simulates and verifies execution of a single EVM step.
      * @param startStateHash The state hash before the step.
      * @param ctx Associated transaction and its context (already verified to be consistent).
-     * @param encodedProof The one-step proof. TODO: describe format.
+     * @param encodedProof The one-step proof, as generated by the sidecar's prover (see `OneStepProver` in
+     * services/sidecar/proof/prover): the VM state, code, stack, memory, and account/storage proofs of the step,
+     * concatenated (see services/sidecar/proof/proof/encoding.go).
      */
     function verifyOneStepProof(
         bytes32 startStateHash,
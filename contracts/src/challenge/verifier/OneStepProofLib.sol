@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+ * Copyright 2022, Specular contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+pragma solidity ^0.8.0;
+
+import "../../libraries/BytesLib.sol";
+import "../../libraries/DeserializationLib.sol";
+
+/**
+ * @notice Decodes and hashes one-step proofs, as encoded by the sidecar's prover (see services/sidecar/proof/proof).
+ * Integers are big-endian (uint64s in 8 bytes), and lists are prefixed by their length (as a uint64).
+ */
+library OneStepProofLib {
+    // Memory is merkleized in 32-byte cells, padded with zero cells to a power of two (see `MemoryRoot`).
+    uint256 internal constant MEMORY_CELL_SIZE = 32;
+
+    // See `VMStateProof`.
+    struct VMState {
+        uint64 blockNumber;
+        uint64 transactionIdx;
+        uint64 depth;
+        bytes32 callStackHash;
+        uint64 gas;
+        uint64 refund;
+        uint64 pc;
+        address contractAddress;
+        bytes32 codeHash;
+        uint64 stackSize;
+        bytes32 stackHash;
+        uint64 memorySize;
+        bytes32 memoryRoot;
+        bytes32 returnDataHash;
+        bytes32 stateRoot;
+    }
+
+    // See `StackProof`.
+    struct StackProof {
+        bytes32 restHash; // Hash of the stack below the items read.
+        uint256[] items; // Items read, from the bottom-most up to the top of the stack.
+    }
+
+    // See `MemoryCell`.
+    struct MemoryCell {
+        uint64 index;
+        bytes32 value;
+        bytes32[] siblings; // From the leaf up.
+    }
+
+    function decodeUint64(bytes memory proof, uint256 offset) internal pure returns (uint256, uint64) {
+        return (offset + 8, BytesLib.toUint64(proof, offset));
+    }
+
+    function decodeBytes(bytes memory proof, uint256 offset) internal pure returns (uint256, bytes memory) {
+        uint64 length;
+        (offset, length) = decodeUint64(proof, offset);
+        return (offset + length, BytesLib.slice(proof, offset, length));
+    }
+
+    function decodeHashes(bytes memory proof, uint256 offset) internal pure returns (uint256, bytes32[] memory) {
+        uint64 length;
+        (offset, length) = decodeUint64(proof, offset);
+        bytes32[] memory hashes = new bytes32[](length);
+        for (uint256 i = 0; i < length; i++) {
+            (offset, hashes[i]) = DeserializationLib.deserializeBytes32(proof, offset);
+        }
+        return (offset, hashes);
+    }
+
+    function decodeVMState(bytes memory proof, uint256 offset) internal pure returns (uint256, VMState memory) {
+        VMState memory s;
+        (offset, s.blockNumber) = decodeUint64(proof, offset);
+        (offset, s.transactionIdx) = decodeUint64(proof, offset);
+        (offset, s.depth) = decodeUint64(proof, offset);
+        (offset, s.callStackHash) = DeserializationLib.deserializeBytes32(proof, offset);
+        (offset, s.gas) = decodeUint64(proof, offset);
+        (offset, s.refund) = decodeUint64(proof, offset);
+        (offset, s.pc) = decodeUint64(proof, offset);
+        (offset, s.contractAddress) = DeserializationLib.deserializeAddress(proof, offset);
+        (offset, s.codeHash) = DeserializationLib.deserializeBytes32(proof, offset);
+        (offset, s.stackSize) = decodeUint64(proof, offset);
+        (offset, s.stackHash) = DeserializationLib.deserializeBytes32(proof, offset);
+        (offset, s.memorySize) = decodeUint64(proof, offset);
+        (offset, s.memoryRoot) = DeserializationLib.deserializeBytes32(proof, offset);
+        (offset, s.returnDataHash) = DeserializationLib.deserializeBytes32(proof, offset);
+        (offset, s.stateRoot) = DeserializationLib.deserializeBytes32(proof, offset);
+        return (offset, s);
+    }
+
+    function decodeStackProof(bytes memory proof, uint256 offset) internal pure returns (uint256, StackProof memory) {
+        StackProof memory p;
+        (offset, p.restHash) = DeserializationLib.deserializeBytes32(proof, offset);
+        uint64 numItems;
+        (offset, numItems) = decodeUint64(proof, offset);
+        p.items = new uint256[](numItems);
+        for (uint256 i = 0; i < numItems; i++) {
+            (offset, p.items[i]) = DeserializationLib.deserializeUint256(proof, offset);
+        }
+        return (offset, p);
+    }
+
+    function decodeMemoryProof(bytes memory proof, uint256 offset)
+        internal
+        pure
+        returns (uint256, MemoryCell[] memory)
+    {
+        uint64 numCells;
+        (offset, numCells) = decodeUint64(proof, offset);
+        MemoryCell[] memory cells = new MemoryCell[](numCells);
+        for (uint256 i = 0; i < numCells; i++) {
+            (offset, cells[i].index) = decodeUint64(proof, offset);
+            (offset, cells[i].value) = DeserializationLib.deserializeBytes32(proof, offset);
+            (offset, cells[i].siblings) = decodeHashes(proof, offset);
+        }
+        return (offset, cells);
+    }
+
+    /**
+     * @notice Hashes the VM state, as committed to in bisections (see `VMStateProof.Hash`).
+     */
+    function hashVMState(VMState memory s) internal pure returns (bytes32) {
+        // Encoded in two parts, to keep the stack shallow.
+        bytes memory head = abi.encodePacked(
+            s.blockNumber, s.transactionIdx, s.depth, s.callStackHash, s.gas, s.refund, s.pc, s.contractAddress
+        );
+        bytes memory tail = abi.encodePacked(
+            s.codeHash, s.stackSize, s.stackHash, s.memorySize, s.memoryRoot, s.returnDataHash, s.stateRoot
+        );
+        return keccak256(bytes.concat(head, tail));
+    }
+
+    /**
+     * @notice Returns the hash of the stack with hash `hash` after pushing `items` onto it, from the bottom-most up
+     * (see `StackHash`).
+     */
+    function pushStackHash(bytes32 hash, uint256[] memory items) internal pure returns (bytes32) {
+        for (uint256 i = 0; i < items.length; i++) {
+            hash = keccak256(abi.encodePacked(hash, items[i]));
+        }
+        return hash;
+    }
+
+    /**
+     * @notice Returns the number of leaves of the merkle tree of a memory of `size` bytes.
+     */
+    function numMemoryLeaves(uint256 size) internal pure returns (uint256 numLeaves) {
+        numLeaves = 1;
+        while (numLeaves * MEMORY_CELL_SIZE < size) {
+            numLeaves *= 2;
+        }
+    }
+
+    /**
+     * @notice Returns the depth of the merkle tree of a memory of `size` bytes (i.e. the number of siblings of a cell).
+     */
+    function memoryTreeDepth(uint256 size) internal pure returns (uint256 depth) {
+        for (uint256 numLeaves = numMemoryLeaves(size); numLeaves > 1; numLeaves /= 2) {
+            depth++;
+        }
+    }
+
+    /**
+     * @notice Returns the root of a memory of `size` bytes with root `root`, zero-extended to `newSize` bytes.
+     */
+    function extendMemoryRoot(bytes32 root, uint256 size, uint256 newSize) internal pure returns (bytes32) {
+        if (newSize <= size) {
+            return root;
+        }
+        uint256 newNumLeaves = numMemoryLeaves(newSize);
+        // The empty memory has a zero root, rather than that of a zero cell.
+        uint256 numLeaves = size == 0 ? 1 : numMemoryLeaves(size);
+        bytes32 zeroRoot = keccak256(abi.encodePacked(bytes32(0)));
+        for (uint256 n = 1; n < numLeaves; n *= 2) {
+            zeroRoot = keccak256(abi.encodePacked(zeroRoot, zeroRoot));
+        }
+        if (size == 0) {
+            root = zeroRoot;
+        }
+        for (; numLeaves < newNumLeaves; numLeaves *= 2) {
+            root = keccak256(abi.encodePacked(root, zeroRoot));
+            zeroRoot = keccak256(abi.encodePacked(zeroRoot, zeroRoot));
+        }
+        return root;
+    }
+
+    /**
+     * @notice Returns the memory root proven by a cell (with value `value`) and its siblings.
+     */
+    function memoryRootFromCell(uint256 index, bytes32 value, bytes32[] memory siblings)
+        internal
+        pure
+        returns (bytes32 hash)
+    {
+        hash = keccak256(abi.encodePacked(value));
+        for (uint256 i = 0; i < siblings.length; i++) {
+            if (index % 2 == 0) {
+                hash = keccak256(abi.encodePacked(hash, siblings[i]));
+            } else {
+                hash = keccak256(abi.encodePacked(siblings[i], hash));
+            }
+            index /= 2;
+        }
+    }
+
+    /**
+     * @notice Returns the memory root after setting (up to two) consecutive cells to `values`, given their proofs
+     * against the current root.
+     */
+    function updateMemoryCells(MemoryCell[] memory cells, bytes32[] memory values)
+        internal
+        pure
+        returns (bytes32 root)
+    {
+        require(cells.length <= 2, "TOO_MANY_CELLS");
+        // Nodes on the path of the previously updated cell (from the leaf up), which later cells' siblings may be.
+        bytes32[] memory path;
+        uint256 prevIndex;
+        for (uint256 c = 0; c < cells.length; c++) {
+            MemoryCell memory cell = cells[c];
+            bytes32[] memory newPath = new bytes32[](cell.siblings.length + 1);
+            uint256 index = cell.index;
+            root = keccak256(abi.encodePacked(values[c]));
+            newPath[0] = root;
+            for (uint256 i = 0; i < cell.siblings.length; i++) {
+                bytes32 sibling = cell.siblings[i];
+                if (c > 0 && (index ^ 1) == (prevIndex >> i)) {
+                    sibling = path[i];
+                }
+                if (index % 2 == 0) {
+                    root = keccak256(abi.encodePacked(root, sibling));
+                } else {
+                    root = keccak256(abi.encodePacked(sibling, root));
+                }
+                index /= 2;
+                newPath[i + 1] = root;
+            }
+            path = newPath;
+            prevIndex = cell.index;
+        }
+    }
+}
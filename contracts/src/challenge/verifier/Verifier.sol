@@ -19,11 +19,42 @@
 pragma solidity ^0.8.0;
 
 import "./IVerifier.sol";
+import "./OneStepProofLib.sol";
+import "../../libraries/BytesLib.sol";
 import "@openzeppelin/contracts-upgradeable/proxy/utils/Initializable.sol";
 import "@openzeppelin/contracts-upgradeable/proxy/utils/UUPSUpgradeable.sol";
 import "@openzeppelin/contracts-upgradeable/access/OwnableUpgradeable.sol";
 
+/**
+ * @notice Verifies one-step proofs of steps within a call frame: arithmetic, comparison and bitwise ops, KECCAK256,
+ * stack and memory ops, jumps, and context ops whose results are in the VM state, code or tx context.
+ * Other steps (e.g. entering or exiting frames, state or calldata access, and failing steps) can't be verified yet,
+ * and revert with `UnsupportedStep`: challenges over them are only resolved by timeout.
+ */
 contract Verifier is IVerifier, Initializable, UUPSUpgradeable, OwnableUpgradeable {
+    // The proof's VM state doesn't hash to the start state.
+    error StateInconsistent();
+    // The proof is inconsistent with its VM state (e.g. its code, stack or memory), or malformed.
+    error ProofInconsistent();
+    // The tx context is inconsistent with the proof's VM state.
+    error ContextInconsistent();
+    // The step (of op `op`) can't be verified yet.
+    error UnsupportedStep(uint8 op);
+
+    uint256 private constant MAX_STACK_SIZE = 1024;
+    // Largest memory size whose expansion cost doesn't overflow (see geth's `memoryGasCost`).
+    uint256 private constant MAX_MEMORY_SIZE = 0x1FFFFFFFE0;
+
+    // A step being executed.
+    struct Step {
+        uint8 op;
+        OneStepProofLib.VMState state; // Updated to the end state.
+        bytes code;
+        uint256[] inputs; // Stack items read, from the bottom-most up to the top.
+        uint256[] outputs; // Stack items replacing them, from the bottom-most up to the top.
+        uint256 gasCost;
+    }
+
     function initialize() public initializer {
         __Ownable_init();
         __UUPSUpgradeable_init();
@@ -36,12 +67,374 @@ contract Verifier is IVerifier, Initializable, UUPSUpgradeable, OwnableUpgradeab
 
     function _authorizeUpgrade(address) internal override onlyOwner {}
 
-    function verifyOneStepProof(bytes32, VerificationContextLib.RawContext calldata, bytes calldata)
-        external
-        pure
-        override
-        returns (bytes32 endStateHash)
-    {
-        endStateHash = 0x0;
+    function verifyOneStepProof(
+        bytes32 startStateHash,
+        VerificationContextLib.RawContext calldata ctx,
+        bytes calldata encodedProof
+    ) external pure override returns (bytes32) {
+        bytes memory proof = encodedProof;
+        Step memory step;
+        uint256 offset;
+        (offset, step.state) = OneStepProofLib.decodeVMState(proof, 0);
+        if (OneStepProofLib.hashVMState(step.state) != startStateHash) {
+            revert StateInconsistent();
+        }
+        (offset, step.code) = OneStepProofLib.decodeBytes(proof, offset);
+        if (keccak256(step.code) != step.state.codeHash) {
+            revert ProofInconsistent();
+        }
+        // Executing past the end of the code stops.
+        step.op = step.state.pc < step.code.length ? uint8(step.code[step.state.pc]) : 0x00;
+        uint256 numInputs;
+        uint256 numOutputs;
+        (numInputs, numOutputs, step.gasCost) = opInfo(step.op);
+        OneStepProofLib.StackProof memory stack;
+        (offset, stack) = OneStepProofLib.decodeStackProof(proof, offset);
+        if (
+            stack.items.length != numInputs || step.state.stackSize < numInputs
+                || OneStepProofLib.pushStackHash(stack.restHash, stack.items) != step.state.stackHash
+        ) {
+            revert ProofInconsistent();
+        }
+        if (step.state.stackSize - numInputs + numOutputs > MAX_STACK_SIZE) {
+            revert UnsupportedStep(step.op); // Stack overflow.
+        }
+        step.inputs = stack.items;
+        step.outputs = new uint256[](numOutputs);
+
+        if (isMemoryOp(step.op)) {
+            offset = executeMemoryOp(step, proof, offset);
+        } else {
+            executeOp(step, ctx);
+        }
+        if (offset != proof.length) {
+            revert ProofInconsistent();
+        }
+        if (step.gasCost > step.state.gas) {
+            revert UnsupportedStep(step.op); // Out of gas.
+        }
+        step.state.gas -= uint64(step.gasCost);
+        step.state.stackSize = uint64(step.state.stackSize - numInputs + numOutputs);
+        step.state.stackHash = OneStepProofLib.pushStackHash(stack.restHash, step.outputs);
+        return OneStepProofLib.hashVMState(step.state);
+    }
+
+    /**
+     * @notice Returns the number of stack items read and written by `op`, and its constant gas cost.
+     * Reverts if the op isn't supported.
+     */
+    function opInfo(uint8 op) internal pure returns (uint256 numInputs, uint256 numOutputs, uint256 gasCost) {
+        if (op >= 0x60 && op <= 0x7f) {
+            return (0, 1, 3); // PUSH1-PUSH32
+        }
+        if (op >= 0x80 && op <= 0x8f) {
+            return (op - 0x7f, op - 0x7e, 3); // DUP1-DUP16
+        }
+        if (op >= 0x90 && op <= 0x9f) {
+            return (op - 0x8e, op - 0x8e, 3); // SWAP1-SWAP16
+        }
+        if (op == 0x02 || (op >= 0x04 && op <= 0x07) || op == 0x0b) {
+            return (2, 1, 5); // MUL, DIV, SDIV, MOD, SMOD, SIGNEXTEND
+        }
+        if (
+            op == 0x01 || op == 0x03 || (op >= 0x10 && op <= 0x14) || (op >= 0x16 && op <= 0x18)
+                || (op >= 0x1a && op <= 0x1d)
+        ) {
+            return (2, 1, 3); // ADD, SUB, LT, GT, SLT, SGT, EQ, AND, OR, XOR, BYTE, SHL, SHR, SAR
+        }
+        if (op == 0x15 || op == 0x19) {
+            return (1, 1, 3); // ISZERO, NOT
+        }
+        if (op == 0x08 || op == 0x09) {
+            return (3, 1, 8); // ADDMOD, MULMOD
+        }
+        if (op == 0x0a) {
+            return (2, 1, 10); // EXP
+        }
+        if (op == 0x20) {
+            return (2, 1, 30); // KECCAK256
+        }
+        if (op == 0x30 || op == 0x38 || (op >= 0x41 && op <= 0x43) || (op >= 0x58 && op <= 0x5a)) {
+            return (0, 1, 2); // ADDRESS, CODESIZE, COINBASE, TIMESTAMP, NUMBER, PC, MSIZE, GAS
+        }
+        if (op == 0x50) {
+            return (1, 0, 2); // POP
+        }
+        if (op == 0x51) {
+            return (1, 1, 3); // MLOAD
+        }
+        if (op == 0x52 || op == 0x53) {
+            return (2, 0, 3); // MSTORE, MSTORE8
+        }
+        if (op == 0x56) {
+            return (1, 0, 8); // JUMP
+        }
+        if (op == 0x57) {
+            return (2, 0, 10); // JUMPI
+        }
+        if (op == 0x5b) {
+            return (0, 0, 1); // JUMPDEST
+        }
+        revert UnsupportedStep(op);
+    }
+
+    function isMemoryOp(uint8 op) internal pure returns (bool) {
+        return op == 0x20 || op == 0x51 || op == 0x52 || op == 0x53; // KECCAK256, MLOAD, MSTORE, MSTORE8
+    }
+
+    /**
+     * @notice Executes a step that doesn't access memory.
+     */
+    function executeOp(Step memory step, VerificationContextLib.RawContext calldata ctx) internal pure {
+        uint8 op = step.op;
+        uint256[] memory inputs = step.inputs;
+        OneStepProofLib.VMState memory state = step.state;
+        uint64 nextPc = state.pc + 1;
+        if (op >= 0x60 && op <= 0x7f) {
+            // PUSH1-PUSH32: the code is zero-padded on the right.
+            uint256 size = op - 0x5f;
+            uint256 value;
+            for (uint256 i = 0; i < size; i++) {
+                uint256 pos = state.pc + 1 + i;
+                value = (value << 8) | (pos < step.code.length ? uint8(step.code[pos]) : 0);
+            }
+            step.outputs[0] = value;
+            nextPc += uint64(size);
+        } else if (op >= 0x80 && op <= 0x8f) {
+            // DUPn: the n-th item from the top is the bottom-most one read.
+            for (uint256 i = 0; i < inputs.length; i++) {
+                step.outputs[i] = inputs[i];
+            }
+            step.outputs[inputs.length] = inputs[0];
+        } else if (op >= 0x90 && op <= 0x9f) {
+            // SWAPn: swaps the top and the bottom-most item read.
+            for (uint256 i = 0; i < inputs.length; i++) {
+                step.outputs[i] = inputs[i];
+            }
+            step.outputs[0] = inputs[inputs.length - 1];
+            step.outputs[inputs.length - 1] = inputs[0];
+        } else if (op <= 0x1d) {
+            if (op == 0x0a) {
+                // EXP: 50 gas per byte of the exponent.
+                uint256 exponent = inputs[0];
+                while (exponent > 0) {
+                    step.gasCost += 50;
+                    exponent >>= 8;
+                }
+            }
+            step.outputs[0] = compute(op, inputs);
+        } else if (op == 0x30) {
+            step.outputs[0] = uint256(uint160(state.contractAddress)); // ADDRESS
+        } else if (op == 0x38) {
+            step.outputs[0] = step.code.length; // CODESIZE
+        } else if (op >= 0x41 && op <= 0x43) {
+            if (ctx.l2BlockNumber != state.blockNumber) {
+                revert ContextInconsistent();
+            }
+            if (op == 0x41) {
+                step.outputs[0] = uint256(uint160(ctx.l2BlockCoinbase)); // COINBASE
+            } else if (op == 0x42) {
+                step.outputs[0] = ctx.l2BlockTimestamp; // TIMESTAMP
+            } else {
+                step.outputs[0] = ctx.l2BlockNumber; // NUMBER
+            }
+        } else if (op == 0x58) {
+            step.outputs[0] = state.pc; // PC
+        } else if (op == 0x59) {
+            step.outputs[0] = state.memorySize; // MSIZE
+        } else if (op == 0x5a) {
+            // GAS: the gas left after the step.
+            if (state.gas < step.gasCost) {
+                revert UnsupportedStep(op);
+            }
+            step.outputs[0] = state.gas - step.gasCost;
+        } else if (op == 0x56 || op == 0x57) {
+            // JUMP, JUMPI: the destination is the top item, and JUMPI's condition the one below it.
+            uint256 dest = inputs[inputs.length - 1];
+            if (op == 0x56 || inputs[0] != 0) {
+                if (!isValidJumpDest(step.code, dest)) {
+                    revert UnsupportedStep(op); // Invalid jump.
+                }
+                nextPc = uint64(dest);
+            }
+        }
+        // POP and JUMPDEST only advance.
+        state.pc = nextPc;
+    }
+
+    /**
+     * @notice Computes the result of an arithmetic, comparison or bitwise op, with the EVM's semantics.
+     */
+    function compute(uint8 op, uint256[] memory inputs) internal pure returns (uint256 r) {
+        // The top of the stack is the first operand.
+        uint256 n = inputs.length;
+        uint256 a = inputs[n - 1];
+        uint256 b = n > 1 ? inputs[n - 2] : 0;
+        uint256 c = n > 2 ? inputs[n - 3] : 0;
+        assembly {
+            switch op
+            case 0x01 { r := add(a, b) }
+            case 0x02 { r := mul(a, b) }
+            case 0x03 { r := sub(a, b) }
+            case 0x04 { r := div(a, b) }
+            case 0x05 { r := sdiv(a, b) }
+            case 0x06 { r := mod(a, b) }
+            case 0x07 { r := smod(a, b) }
+            case 0x08 { r := addmod(a, b, c) }
+            case 0x09 { r := mulmod(a, b, c) }
+            case 0x0a { r := exp(a, b) }
+            case 0x0b { r := signextend(a, b) }
+            case 0x10 { r := lt(a, b) }
+            case 0x11 { r := gt(a, b) }
+            case 0x12 { r := slt(a, b) }
+            case 0x13 { r := sgt(a, b) }
+            case 0x14 { r := eq(a, b) }
+            case 0x15 { r := iszero(a) }
+            case 0x16 { r := and(a, b) }
+            case 0x17 { r := or(a, b) }
+            case 0x18 { r := xor(a, b) }
+            case 0x19 { r := not(a) }
+            case 0x1a { r := byte(a, b) }
+            case 0x1b { r := shl(a, b) }
+            case 0x1c { r := shr(a, b) }
+            case 0x1d { r := sar(a, b) }
+        }
+    }
+
+    /**
+     * @notice Returns true iff `dest` is a JUMPDEST instruction (rather than push data) in `code`.
+     */
+    function isValidJumpDest(bytes memory code, uint256 dest) internal pure returns (bool) {
+        if (dest >= code.length || uint8(code[dest]) != 0x5b) {
+            return false;
+        }
+        uint256 pc = 0;
+        while (pc < dest) {
+            uint8 op = uint8(code[pc]);
+            pc += op >= 0x60 && op <= 0x7f ? op - 0x5e : 1;
+        }
+        return pc == dest;
+    }
+
+    /**
+     * @notice Executes a step accessing a memory range (KECCAK256, MLOAD, MSTORE, MSTORE8), given its memory proof
+     * (at `offset`) against the memory as expanded by the step. Returns the offset past the memory proof.
+     */
+    function executeMemoryOp(Step memory step, bytes memory proof, uint256 offset) internal pure returns (uint256) {
+        OneStepProofLib.MemoryCell[] memory cells;
+        (offset, cells) = OneStepProofLib.decodeMemoryProof(proof, offset);
+        // The range's offset is the top item, and KECCAK256's size the one below it.
+        uint256 memOffset = step.inputs[step.inputs.length - 1];
+        uint256 size = step.op == 0x20 ? step.inputs[step.inputs.length - 2] : (step.op == 0x53 ? 1 : 32);
+        bytes memory data = expandMemory(step, cells, memOffset, size);
+        uint256 rel = memOffset % 32; // Offset of the range in `data`.
+        if (step.op == 0x20) {
+            // KECCAK256: 6 gas per word hashed.
+            step.gasCost += (size + 31) / 32 * 6;
+            bytes32 hash = size == 0 ? keccak256("") : keccak256(BytesLib.slice(data, rel, size));
+            step.outputs[0] = uint256(hash);
+        } else if (step.op == 0x51) {
+            step.outputs[0] = uint256(BytesLib.toBytes32(data, rel)); // MLOAD
+        } else {
+            writeMemory(step, cells, data, rel);
+        }
+        step.state.pc += 1;
+        return offset;
+    }
+
+    /**
+     * @notice Expands the memory to cover the range (charging for it), and returns the contents of the cells
+     * overlapping it (proven by `cells`).
+     */
+    function expandMemory(
+        Step memory step,
+        OneStepProofLib.MemoryCell[] memory cells,
+        uint256 memOffset,
+        uint256 size
+    ) internal pure returns (bytes memory data) {
+        OneStepProofLib.VMState memory state = step.state;
+        uint256 newSize = state.memorySize;
+        if (size > 0) {
+            if (memOffset > type(uint64).max || size > type(uint64).max || memOffset + size > MAX_MEMORY_SIZE) {
+                revert UnsupportedStep(step.op); // Out of gas.
+            }
+            uint256 end = (memOffset + size + 31) / 32 * 32;
+            if (end > newSize) {
+                newSize = end;
+            }
+            data = readMemoryCells(state, cells, memOffset, size, newSize);
+        } else if (cells.length > 0) {
+            revert ProofInconsistent();
+        }
+        step.gasCost += memoryGasCost(newSize) - memoryGasCost(state.memorySize);
+        state.memoryRoot = OneStepProofLib.extendMemoryRoot(state.memoryRoot, state.memorySize, newSize);
+        state.memorySize = uint64(newSize);
+    }
+
+    /**
+     * @notice Writes the value of an MSTORE or MSTORE8 (the item below the offset) at `rel` in `data`, and updates
+     * the memory root with the resulting cells.
+     */
+    function writeMemory(
+        Step memory step,
+        OneStepProofLib.MemoryCell[] memory cells,
+        bytes memory data,
+        uint256 rel
+    ) internal pure {
+        uint256 value = step.inputs[step.inputs.length - 2];
+        if (step.op == 0x53) {
+            data[rel] = bytes1(uint8(value));
+        } else {
+            assembly {
+                mstore(add(add(data, 32), rel), value)
+            }
+        }
+        bytes32[] memory values = new bytes32[](cells.length);
+        for (uint256 i = 0; i < cells.length; i++) {
+            values[i] = BytesLib.toBytes32(data, i * 32);
+        }
+        step.state.memoryRoot = OneStepProofLib.updateMemoryCells(cells, values);
+    }
+
+    /**
+     * @notice Verifies that `cells` are the cells overlapping the (non-empty) range, proven against the memory
+     * zero-extended to `newSize`, and returns their contents.
+     */
+    function readMemoryCells(
+        OneStepProofLib.VMState memory state,
+        OneStepProofLib.MemoryCell[] memory cells,
+        uint256 memOffset,
+        uint256 size,
+        uint256 newSize
+    ) internal pure returns (bytes memory data) {
+        uint256 first = memOffset / 32;
+        uint256 last = (memOffset + size - 1) / 32;
+        if (cells.length != last - first + 1) {
+            revert ProofInconsistent();
+        }
+        bytes32 root = OneStepProofLib.extendMemoryRoot(state.memoryRoot, state.memorySize, newSize);
+        uint256 depth = OneStepProofLib.memoryTreeDepth(newSize);
+        data = new bytes(cells.length * 32);
+        for (uint256 i = 0; i < cells.length; i++) {
+            OneStepProofLib.MemoryCell memory cell = cells[i];
+            if (
+                cell.index != first + i || cell.siblings.length != depth
+                    || OneStepProofLib.memoryRootFromCell(cell.index, cell.value, cell.siblings) != root
+            ) {
+                revert ProofInconsistent();
+            }
+            bytes32 value = cell.value;
+            assembly {
+                mstore(add(add(data, 32), mul(i, 32)), value)
+            }
+        }
+    }
+
+    /**
+     * @notice Returns the total cost of a memory of `size` bytes (at most `MAX_MEMORY_SIZE`).
+     */
+    function memoryGasCost(uint256 size) internal pure returns (uint256) {
+        uint256 words = (size + 31) / 32;
+        return words * 3 + words * words / 512;
     }
 }
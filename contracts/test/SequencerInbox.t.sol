@@ -91,7 +91,33 @@ contract SequencerInboxTest is SequencerBaseSetup {
     function test_appendTxBatch_invalidVersion_reverts() public {
         vm.expectRevert(ISequencerInbox.TxBatchVersionIncorrect.selector);
         vm.prank(sequencerAddress);
-        seqIn.appendTxBatch(hex"01"); // version 0 is the only valid version
+        seqIn.appendTxBatch(hex"02"); // versions 0 and 1 are the only valid versions
+    }
+
+    function test_appendTxBatch_v1_succeeds() public {
+        // The compressed payload isn't decoded on-chain, so any V1 data is accepted.
+        bytes memory txBatch = bytes.concat(hex"01", keccak256("compressed batch"));
+        vm.prank(sequencerAddress);
+        seqIn.appendTxBatch(txBatch);
+    }
+
+    function test_appendTxBatch_blobMarker_succeeds() public {
+        vm.prank(sequencerAddress);
+        seqIn.appendTxBatch(hex"b0");
+    }
+
+    // The blob marker must be the whole calldata payload.
+    function test_appendTxBatch_blobMarkerWithData_reverts() public {
+        vm.expectRevert(ISequencerInbox.TxBatchVersionIncorrect.selector);
+        vm.prank(sequencerAddress);
+        seqIn.appendTxBatch(hex"b000");
+    }
+
+    // Commitments to batches stored off-chain (0xda || hash || pointer) can't be verified, so are rejected.
+    function test_appendTxBatch_daCommitment_reverts() public {
+        vm.expectRevert(ISequencerInbox.TxBatchVersionIncorrect.selector);
+        vm.prank(sequencerAddress);
+        seqIn.appendTxBatch(bytes.concat(hex"da", keccak256("batch"), "pointer"));
     }
 
     //////////////////////////////
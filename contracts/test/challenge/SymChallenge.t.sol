@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+ * Modifications Copyright 2022, Specular contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+pragma solidity ^0.8.13;
+
+import "forge-std/Test.sol";
+import "../../src/challenge/IChallenge.sol";
+import "../../src/challenge/ChallengeBase.sol";
+import "../../src/challenge/verifier/IVerifier.sol";
+import "../../src/IDAProvider.sol";
+import {SymChallenge} from "../../src/challenge/SymChallenge.sol";
+
+contract SymChallengeTest is Test, IChallengeResultReceiver {
+    SymChallenge internal challenge;
+    address internal defender;
+    address internal challenger;
+
+    bytes32 internal startStateHash = keccak256("start");
+    bytes32 internal endStateDefenseHash = keccak256("defense end");
+    bytes32 internal endStateChallengeHash = keccak256("challenge end");
+
+    function completeChallenge(address, address) external override {}
+
+    function setUp() public {
+        defender = makeAddr("defender");
+        challenger = makeAddr("challenger");
+        challenge = new SymChallenge();
+        challenge.initialize(
+            defender,
+            challenger,
+            IVerifier(address(0)),
+            IDAProvider(address(0)),
+            this,
+            startStateHash,
+            endStateDefenseHash,
+            endStateChallengeHash,
+            100
+        );
+    }
+
+    function test_initialize_recordsCreationBlock() external {
+        assertEq(challenge.creationBlock(), block.number);
+        assertEq(challenge.lastMoveBlock(), block.number);
+    }
+
+    function _initializeChallengeLength(uint256 numSteps) internal {
+        vm.prank(defender);
+        challenge.initializeChallengeLength(numSteps);
+        vm.prank(challenger);
+        challenge.initializeChallengeLength(numSteps);
+    }
+
+    function test_bisectExecution_beforeInitialization_reverts() external {
+        bytes32[] memory bisection = new bytes32[](3);
+        bytes32[] memory prevBisection = new bytes32[](2);
+        vm.expectRevert(IChallenge.NotInitialized.selector);
+        vm.prank(defender);
+        challenge.bisectExecution(bisection, 1, prevBisection, 0, 4);
+    }
+
+    function test_bisectExecution_succeeds() external {
+        _initializeChallengeLength(4);
+        // Both agree on the length, so the defender's end state is challenged, and the challenger moves first.
+        assertEq(uint256(challenge.turn()), uint256(ChallengeBase.Turn.Challenger));
+
+        // Challenger bisects the initial segment [start, defense end] (4 steps).
+        bytes32[] memory prevBisection = new bytes32[](2);
+        prevBisection[0] = startStateHash;
+        prevBisection[1] = endStateDefenseHash;
+        bytes32[] memory bisection = new bytes32[](3);
+        bisection[0] = startStateHash;
+        bisection[1] = keccak256("mid");
+        bisection[2] = endStateChallengeHash;
+        vm.prank(challenger);
+        challenge.bisectExecution(bisection, 1, prevBisection, 0, 4);
+        assertEq(challenge.bisectionHash(), keccak256(abi.encodePacked(bisection, uint256(0), uint256(4))));
+        assertEq(uint256(challenge.turn()), uint256(ChallengeBase.Turn.Defender));
+
+        // Defender disagrees with the second half (steps 2-4), and bisects it.
+        prevBisection = bisection;
+        bisection = new bytes32[](3);
+        bisection[0] = prevBisection[1];
+        bisection[1] = keccak256("3/4");
+        bisection[2] = endStateDefenseHash;
+        vm.prank(defender);
+        challenge.bisectExecution(bisection, 2, prevBisection, 0, 4);
+        assertEq(challenge.bisectionHash(), keccak256(abi.encodePacked(bisection, uint256(2), uint256(2))));
+        assertEq(uint256(challenge.turn()), uint256(ChallengeBase.Turn.Challenger));
+
+        // Moves must be made against the latest bisection.
+        vm.expectRevert(SymChallenge.PreviousStateInconsistent.selector);
+        vm.prank(challenger);
+        challenge.bisectExecution(bisection, 1, prevBisection, 0, 4);
+    }
+}
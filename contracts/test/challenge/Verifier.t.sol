@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+ * Copyright 2022, Specular contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+pragma solidity ^0.8.13;
+
+import "forge-std/Test.sol";
+import "../../src/challenge/verifier/OneStepProofLib.sol";
+import "../../src/challenge/verifier/VerificationContextLib.sol";
+import {Verifier} from "../../src/challenge/verifier/Verifier.sol";
+
+contract VerifierTest is Test {
+    Verifier internal verifier;
+    VerificationContextLib.RawContext internal ctx;
+
+    function setUp() public {
+        verifier = new Verifier();
+        ctx.l2BlockNumber = 1;
+    }
+
+    function newState(bytes memory code, uint256[] memory stack)
+        internal
+        pure
+        returns (OneStepProofLib.VMState memory s)
+    {
+        s.blockNumber = 1;
+        s.gas = 100;
+        s.codeHash = keccak256(code);
+        s.stackSize = uint64(stack.length);
+        s.stackHash = OneStepProofLib.pushStackHash(bytes32(0), stack);
+    }
+
+    // Encodes the VM state, code and stack proof (of the whole stack) as the sidecar's prover does.
+    function encodeProof(OneStepProofLib.VMState memory s, bytes memory code, uint256[] memory stack)
+        internal
+        pure
+        returns (bytes memory)
+    {
+        bytes memory state = bytes.concat(
+            abi.encodePacked(
+                s.blockNumber, s.transactionIdx, s.depth, s.callStackHash, s.gas, s.refund, s.pc, s.contractAddress
+            ),
+            abi.encodePacked(
+                s.codeHash, s.stackSize, s.stackHash, s.memorySize, s.memoryRoot, s.returnDataHash, s.stateRoot
+            )
+        );
+        return bytes.concat(
+            state,
+            abi.encodePacked(uint64(code.length), code),
+            abi.encodePacked(bytes32(0), uint64(stack.length), stack)
+        );
+    }
+
+    function test_verifyOneStepProof_add() external {
+        bytes memory code = hex"01";
+        uint256[] memory stack = new uint256[](2);
+        stack[0] = 2;
+        stack[1] = 3;
+        OneStepProofLib.VMState memory s = newState(code, stack);
+        bytes memory proof = encodeProof(s, code, stack);
+        bytes32 startStateHash = OneStepProofLib.hashVMState(s);
+
+        uint256[] memory endStack = new uint256[](1);
+        endStack[0] = 5;
+        s.pc = 1;
+        s.gas -= 3;
+        s.stackSize = 1;
+        s.stackHash = OneStepProofLib.pushStackHash(bytes32(0), endStack);
+        assertEq(verifier.verifyOneStepProof(startStateHash, ctx, proof), OneStepProofLib.hashVMState(s));
+    }
+
+    function test_verifyOneStepProof_mstoreExpandsMemory() external {
+        bytes memory code = hex"52";
+        uint256[] memory stack = new uint256[](2);
+        stack[0] = 0x42;
+        stack[1] = 0;
+        OneStepProofLib.VMState memory s = newState(code, stack);
+        // A proof of the (zero) cell written, against the memory expanded to 32 bytes.
+        bytes memory memoryProof = abi.encodePacked(uint64(1), uint64(0), bytes32(0), uint64(0));
+        bytes memory proof = bytes.concat(encodeProof(s, code, stack), memoryProof);
+        bytes32 startStateHash = OneStepProofLib.hashVMState(s);
+
+        s.pc = 1;
+        s.gas -= 3 + 3; // MSTORE, and the expansion to one word.
+        s.stackSize = 0;
+        s.stackHash = bytes32(0);
+        s.memorySize = 32;
+        s.memoryRoot = keccak256(abi.encodePacked(bytes32(uint256(0x42))));
+        assertEq(verifier.verifyOneStepProof(startStateHash, ctx, proof), OneStepProofLib.hashVMState(s));
+    }
+
+    function test_verifyOneStepProof_revertsOnInconsistentStartState() external {
+        bytes memory code = hex"01";
+        uint256[] memory stack = new uint256[](2);
+        OneStepProofLib.VMState memory s = newState(code, stack);
+        bytes memory proof = encodeProof(s, code, stack);
+
+        vm.expectRevert(Verifier.StateInconsistent.selector);
+        verifier.verifyOneStepProof(keccak256("other"), ctx, proof);
+    }
+
+    function test_verifyOneStepProof_revertsOnUnsupportedStep() external {
+        bytes memory code = hex"f1"; // CALL
+        uint256[] memory stack = new uint256[](0);
+        OneStepProofLib.VMState memory s = newState(code, stack);
+        bytes memory proof = encodeProof(s, code, stack);
+
+        vm.expectRevert(abi.encodeWithSelector(Verifier.UnsupportedStep.selector, uint8(0xf1)));
+        verifier.verifyOneStepProof(OneStepProofLib.hashVMState(s), ctx, proof);
+    }
+}
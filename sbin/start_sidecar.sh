@@ -18,6 +18,15 @@ FLAGS=(
   "--l2.endpoint $L2_ENDPOINT"
   "--protocol.rollup-cfg-path $ROLLUP_CFG_PATH"
 )
+if [ -n "$L1_BEACON_ENDPOINT" ]; then
+  FLAGS+=("--l1.beacon-endpoint $L1_BEACON_ENDPOINT")
+fi
+if [ -n "$L2_POLL_INTERVAL" ]; then
+  FLAGS+=("--l2.poll-interval $L2_POLL_INTERVAL")
+fi
+if [ -n "$L2_PROOF_ENDPOINT" ]; then
+  FLAGS+=("--l2.proof-endpoint $L2_PROOF_ENDPOINT")
+fi
 
 # Set disseminator flags.
 if [ "$DISSEMINATOR" = true ]; then
@@ -35,6 +44,21 @@ if [ "$DISSEMINATOR" = true ]; then
   if [ -n "$DISSEMINATOR_INTERVAL" ]; then
     FLAGS+=("--disseminator.interval $DISSEMINATOR_INTERVAL")
   fi
+  if [ -n "$DISSEMINATOR_BATCH_ENCODER_VERSION" ]; then
+    FLAGS+=("--disseminator.batch-encoder-version $DISSEMINATOR_BATCH_ENCODER_VERSION")
+  fi
+  if [ -n "$DISSEMINATOR_COMPRESSION_ALGO" ]; then
+    FLAGS+=("--disseminator.compression-algo $DISSEMINATOR_COMPRESSION_ALGO")
+  fi
+  if [ -n "$DISSEMINATOR_DA_MODE" ]; then
+    FLAGS+=("--disseminator.da-mode $DISSEMINATOR_DA_MODE")
+  fi
+  if [ -n "$DISSEMINATOR_MAX_PENDING_TXS" ]; then
+    FLAGS+=("--disseminator.max-pending-txs $DISSEMINATOR_MAX_PENDING_TXS")
+  fi
+  if [ -n "$DISSEMINATOR_CHECKPOINT_PATH" ]; then
+    FLAGS+=("--disseminator.checkpoint-path $DISSEMINATOR_CHECKPOINT_PATH")
+  fi
 fi
 # Set validator flags.
 if [ "$VALIDATOR" = true ]; then
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/specularL2/specular/services/sidecar/internal/sidecar/infra/services"
+	rollupServices "github.com/specularL2/specular/services/sidecar/rollup/services"
+)
+
+var serviceFlag = &cli.StringFlag{
+	Name:     "service",
+	Usage:    "The service whose account's pending L1 txs are cancelled (disseminator or validator)",
+	Required: true,
+}
+
+// Cancels the pending L1 txs of a sidecar service's account, e.g. to clear a stuck nonce.
+// Takes the same flags as the sidecar, to use the same account and tx journal; refuses to run while the sidecar holds
+// the journal's lock.
+func main() {
+	app := &cli.App{
+		Name:   "cancel-pending",
+		Usage:  "cancel the pending L1 txs of a sidecar service's account",
+		Flags:  append(rollupServices.CLIFlags(), serviceFlag),
+		Action: cancelPending,
+	}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatalf("failed to cancel pending txs: %s", err)
+	}
+}
+
+func cancelPending(cliCtx *cli.Context) error {
+	cfg, err := rollupServices.ParseSystemConfig(cliCtx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	return services.CancelPendingTxs(ctx, cfg, cliCtx.String(serviceFlag.Name))
+}
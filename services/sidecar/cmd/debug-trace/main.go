@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+
+	"github.com/specularL2/specular/services/sidecar/proof/prover"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+var (
+	endpointFlag = &cli.StringFlag{
+		Name:     "endpoint",
+		Usage:    "The L2 API endpoint serving the proof namespace",
+		Required: true,
+	}
+	txFlag = &cli.StringFlag{
+		Name:     "tx",
+		Usage:    "The hash of the transaction to trace",
+		Required: true,
+	}
+	outFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "The file to write the trace to, as JSON lines (defaults to stdout)",
+	}
+	compareFlag = &cli.StringFlag{
+		Name:  "compare",
+		Usage: "A trace of the same transaction from another node, to find the first step they diverge at",
+	}
+	pageSizeFlag = &cli.Uint64Flag{
+		Name:  "page-size",
+		Usage: "The number of steps fetched per request",
+		Value: 1024,
+	}
+)
+
+// Fetches the step trace of an L2 transaction from an EL node (page by page), e.g. to compare it with another node's.
+func main() {
+	app := &cli.App{
+		Name:   "debug-trace",
+		Usage:  "fetch the step trace of an L2 transaction, and compare it with another node's",
+		Flags:  []cli.Flag{endpointFlag, txFlag, outFlag, compareFlag, pageSizeFlag},
+		Action: debugTrace,
+	}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatalf("failed to trace transaction: %s", err)
+	}
+}
+
+func debugTrace(cliCtx *cli.Context) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	client, err := eth.DialWithRetry(ctx, cliCtx.String(endpointFlag.Name))
+	if err != nil {
+		return err
+	}
+	var other []prover.TraceStep
+	if path := cliCtx.String(compareFlag.Name); path != "" {
+		if other, err = readTraceFile(path); err != nil {
+			return err
+		}
+	}
+	var out io.Writer = os.Stdout
+	if path := cliCtx.String(outFlag.Name); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create trace file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+	var (
+		txHash   = common.HexToHash(cliCtx.String(txFlag.Name))
+		pageSize = cliCtx.Uint64(pageSizeFlag.Name)
+		numSteps uint64
+		diverged bool
+	)
+	for {
+		page, err := client.DebugTransaction(ctx, txHash, numSteps, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed to get trace: %w", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		if err := prover.WriteTrace(w, page); err != nil {
+			return err
+		}
+		if other != nil && !diverged {
+			if i, ok := prover.FirstDivergentStep(page, window(other, numSteps, uint64(len(page)))); ok {
+				diverged = true
+				log.Printf("traces diverge at step %d", numSteps+uint64(i)+1)
+			}
+		}
+		numSteps += uint64(len(page))
+	}
+	if other != nil && !diverged {
+		if uint64(len(other)) > numSteps {
+			log.Printf("traces diverge at step %d", numSteps+1)
+		} else {
+			log.Printf("traces are identical (%d steps)", numSteps)
+		}
+	}
+	return nil
+}
+
+func readTraceFile(path string) ([]prover.TraceStep, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+	defer f.Close()
+	return prover.ReadTrace(f)
+}
+
+// Returns up to `limit` steps of the trace, starting at index `offset`.
+func window(trace []prover.TraceStep, offset, limit uint64) []prover.TraceStep {
+	if offset >= uint64(len(trace)) {
+		return nil
+	}
+	if end := offset + limit; end < uint64(len(trace)) {
+		return trace[offset:end]
+	}
+	return trace[offset:]
+}
@@ -7,10 +7,15 @@ replace github.com/ethereum/go-ethereum => ../el_clients/go-ethereum
 replace github.com/specularL2/specular/bindings-go => ../../bindings-go
 
 require (
+	github.com/andybalholm/brotli v1.0.5
 	github.com/avast/retry-go/v4 v4.3.3
 	github.com/ethereum/go-ethereum v1.13.2
+	github.com/google/uuid v1.3.0
 	github.com/google/wire v0.5.0
+	github.com/holiman/uint256 v1.2.3
+	github.com/klauspost/compress v1.15.15
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.14.0
 	github.com/specularL2/specular/bindings-go v0.0.0-00010101000000-000000000000
 	github.com/spf13/viper v1.3.2
 	github.com/stretchr/testify v1.8.1
@@ -49,14 +54,11 @@ require (
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/golang/snappy v0.0.5-0.20220116011046-fa5810519dcb // indirect
 	github.com/google/subcommands v1.2.0 // indirect
-	github.com/google/uuid v1.3.0 // indirect
 	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/holiman/bloomfilter/v2 v2.0.3 // indirect
-	github.com/holiman/uint256 v1.2.3 // indirect
 	github.com/huin/goupnp v1.3.0 // indirect
 	github.com/jackpal/go-nat-pmp v1.0.2 // indirect
-	github.com/klauspost/compress v1.15.15 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/magiconair/properties v1.8.0 // indirect
@@ -67,7 +69,6 @@ require (
 	github.com/olekukonko/tablewriter v0.0.5 // indirect
 	github.com/pelletier/go-toml v1.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/prometheus/client_golang v1.14.0 // indirect
 	github.com/prometheus/client_model v0.3.0 // indirect
 	github.com/prometheus/common v0.39.0 // indirect
 	github.com/prometheus/procfs v0.9.0 // indirect
@@ -11,8 +11,10 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/specularL2/specular/services/sidecar/internal/service/config"
+	"github.com/specularL2/specular/services/sidecar/rollup/metrics"
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
 	"github.com/specularL2/specular/services/sidecar/rollup/services"
+	"github.com/specularL2/specular/services/sidecar/rollup/services/challenger"
 	"github.com/specularL2/specular/services/sidecar/rollup/services/disseminator"
 	"github.com/specularL2/specular/services/sidecar/rollup/services/validator"
 )
@@ -29,8 +31,10 @@ type Application struct {
 	config            *config.Config
 	systemConfig      *services.SystemConfig
 	l1State           *eth.EthState
+	metrics           *metrics.Metrics
 	batchDisseminator *disseminator.BatchDisseminator
 	validator         *validator.Validator
+	challenger        *challenger.Challenger
 }
 
 func (app *Application) Run() error {
@@ -47,6 +51,12 @@ func (app *Application) Run() error {
 
 	errGroup, _ := errgroup.WithContext(app.ctx)
 
+	if app.systemConfig.Metrics().GetIsEnabled() {
+		app.log.Info("Starting metrics server...")
+		metricsCfg := app.systemConfig.Metrics()
+		errGroup.Go(func() error { return app.metrics.Serve(app.ctx, metricsCfg.GetAddr(), metricsCfg.GetPort()) })
+	}
+
 	if app.systemConfig.Disseminator().GetIsEnabled() {
 		app.log.Info("Starting disseminator...")
 		err := app.batchDisseminator.Start(app.ctx, errGroup)
@@ -61,6 +71,10 @@ func (app *Application) Run() error {
 		if err != nil {
 			return err
 		}
+		app.log.Info("Starting challenger...")
+		if err := app.challenger.Start(app.ctx, errGroup); err != nil {
+			return err
+		}
 	}
 
 	if err := errGroup.Wait(); err != nil {
@@ -15,8 +15,10 @@ func SetupApplication() (*Application, func(), error) {
 		ConfigProvider,
 		SystemConfigProvider,
 		L1StateProvider,
+		MetricsProvider,
 		DisseminatorProvider,
 		ValidatorProvider,
+		ChallengerProvider,
 		wire.Struct(new(Application), "*"))),
 	)
 }
@@ -26,8 +28,10 @@ func SetupApplicationForIntegrationTests(cfg *config.Config) (*TestApplication,
 		CommonProvider,
 		SystemConfigProvider,
 		L1StateProvider,
+		MetricsProvider,
 		DisseminatorProvider,
 		ValidatorProvider,
+		ChallengerProvider,
 		wire.Struct(new(Application), "*"),
 		wire.Struct(new(TestApplication), "*"))),
 	)
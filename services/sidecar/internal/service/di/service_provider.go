@@ -6,10 +6,19 @@ import (
 	"github.com/specularL2/specular/services/sidecar/internal/sidecar/infra/services"
 )
 
+var MetricsProvider = wire.NewSet( //nolint:gochecknoglobals
+	services.NewMetrics,
+)
+
 var DisseminatorProvider = wire.NewSet( //nolint:gochecknoglobals
 	services.NewDisseminator,
 )
 
 var ValidatorProvider = wire.NewSet( //nolint:gochecknoglobals
+	services.NewValidatorClients,
 	services.NewValidator,
 )
+
+var ChallengerProvider = wire.NewSet( //nolint:gochecknoglobals
+	services.NewChallenger,
+)
@@ -7,5 +7,6 @@ import (
 )
 
 var L1StateProvider = wire.NewSet( //nolint:gochecknoglobals
+	services.NewHeadSyncers,
 	services.NewL1State,
 )
@@ -25,15 +25,25 @@ func SetupApplication() (*Application, func(), error) {
 	logger := config.NewLogger(systemConfig)
 	cancelChannel := config.NewCancelChannel()
 	context := config.NewContext(logger, cancelChannel)
-	ethState, err := services.NewL1State(context, logger, systemConfig)
+	headSyncers, err := services.NewHeadSyncers(context, logger, systemConfig)
 	if err != nil {
 		return nil, nil, err
 	}
-	batchDisseminator, err := services.NewDisseminator(context, systemConfig, ethState)
+	ethState := services.NewL1State(headSyncers)
+	metricsMetrics := services.NewMetrics(systemConfig)
+	batchDisseminator, err := services.NewDisseminator(context, systemConfig, ethState, headSyncers, metricsMetrics)
 	if err != nil {
 		return nil, nil, err
 	}
-	validator, err := services.NewValidator(context, systemConfig, ethState)
+	validatorClients, err := services.NewValidatorClients(context, systemConfig, metricsMetrics)
+	if err != nil {
+		return nil, nil, err
+	}
+	validator, err := services.NewValidator(context, systemConfig, ethState, headSyncers, validatorClients, metricsMetrics)
+	if err != nil {
+		return nil, nil, err
+	}
+	challenger, err := services.NewChallenger(context, systemConfig, ethState, headSyncers, validatorClients)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -43,8 +53,10 @@ func SetupApplication() (*Application, func(), error) {
 		config:            configConfig,
 		systemConfig:      systemConfig,
 		l1State:           ethState,
+		metrics:           metricsMetrics,
 		batchDisseminator: batchDisseminator,
 		validator:         validator,
+		challenger:        challenger,
 	}
 	return application, func() {
 	}, nil
@@ -58,15 +70,25 @@ func SetupApplicationForIntegrationTests(cfg *config.Config) (*TestApplication,
 	logger := config.NewLogger(systemConfig)
 	cancelChannel := config.NewCancelChannel()
 	context := config.NewContext(logger, cancelChannel)
-	ethState, err := services.NewL1State(context, logger, systemConfig)
+	headSyncers, err := services.NewHeadSyncers(context, logger, systemConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	ethState := services.NewL1State(headSyncers)
+	metricsMetrics := services.NewMetrics(systemConfig)
+	batchDisseminator, err := services.NewDisseminator(context, systemConfig, ethState, headSyncers, metricsMetrics)
+	if err != nil {
+		return nil, nil, err
+	}
+	validatorClients, err := services.NewValidatorClients(context, systemConfig, metricsMetrics)
 	if err != nil {
 		return nil, nil, err
 	}
-	batchDisseminator, err := services.NewDisseminator(context, systemConfig, ethState)
+	validator, err := services.NewValidator(context, systemConfig, ethState, headSyncers, validatorClients, metricsMetrics)
 	if err != nil {
 		return nil, nil, err
 	}
-	validator, err := services.NewValidator(context, systemConfig, ethState)
+	challenger, err := services.NewChallenger(context, systemConfig, ethState, headSyncers, validatorClients)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -76,8 +98,10 @@ func SetupApplicationForIntegrationTests(cfg *config.Config) (*TestApplication,
 		config:            cfg,
 		systemConfig:      systemConfig,
 		l1State:           ethState,
+		metrics:           metricsMetrics,
 		batchDisseminator: batchDisseminator,
 		validator:         validator,
+		challenger:        challenger,
 	}
 	testApplication := &TestApplication{
 		Application: application,
@@ -2,39 +2,62 @@ package services
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"math/big"
 
-	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr/metrics"
-
-	"github.com/ethereum/go-ethereum/accounts"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/accounts/external"
 	"github.com/ethereum/go-ethereum/common"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 
+	"github.com/specularL2/specular/services/sidecar/rollup/da"
 	"github.com/specularL2/specular/services/sidecar/rollup/derivation"
+	"github.com/specularL2/specular/services/sidecar/rollup/metrics"
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
 	"github.com/specularL2/specular/services/sidecar/rollup/services"
+	challengerService "github.com/specularL2/specular/services/sidecar/rollup/services/challenger"
 	disseminatorService "github.com/specularL2/specular/services/sidecar/rollup/services/disseminator"
 	validatorService "github.com/specularL2/specular/services/sidecar/rollup/services/validator"
+	"github.com/specularL2/specular/services/sidecar/rollup/signer"
 	"github.com/specularL2/specular/services/sidecar/utils/fmt"
 	"github.com/specularL2/specular/services/sidecar/utils/log"
 )
 
 type serviceCfg interface {
 	GetAccountAddr() common.Address
-	GetPrivateKey() *ecdsa.PrivateKey
-	GetClefEndpoint() string
+	GetSignerCfg() signer.Config
 	GetTxMgrCfg() txmgr.Config
 }
 
+// Metrics recorded by the services (and their tx managers).
+type serviceMetrics interface {
+	derivation.Metricer
+	validatorService.Metricer
+	NewTxMetrics(service string) txmgr.TxMetricer
+}
+
+// Creates the metrics registry served by the sidecar (nil if metrics are disabled).
+func NewMetrics(cfg *services.SystemConfig) *metrics.Metrics {
+	if !cfg.Metrics().GetIsEnabled() {
+		log.Info("metrics are not enabled")
+		return nil
+	}
+	return metrics.NewMetrics()
+}
+
+// Returns the given metrics, or no-op metrics if disabled.
+func getServiceMetrics(m *metrics.Metrics) serviceMetrics {
+	if m == nil {
+		return &metrics.NoopMetrics{}
+	}
+	return m
+}
+
 func NewDisseminator(
 	ctx context.Context,
 	cfg *services.SystemConfig,
 	l1State *eth.EthState,
+	syncers *HeadSyncers,
+	m *metrics.Metrics,
 ) (*disseminatorService.BatchDisseminator, error) {
 	if !cfg.Disseminator().GetIsEnabled() {
 		log.Info("disseminator is not enabled")
@@ -46,25 +69,52 @@ func NewDisseminator(
 	} else {
 		endpoint = cfg.L1().Endpoint
 	}
-	l1TxMgr, err := createTxManager(ctx, "disseminator", endpoint, cfg.Protocol(), cfg.Disseminator())
+	var (
+		checkpoints = disseminatorService.NewFileCheckpointStore(cfg.Disseminator().GetCheckpointPath())
+		publishHook = func(tx *ethTypes.Transaction) {
+			if err := checkpoints.AddPendingTx(tx); err != nil {
+				log.Errorf("Failed to checkpoint pending tx: %w", err, "tx_hash", tx.Hash())
+			}
+		}
+	)
+	l1TxMgr, err := createTxManager(
+		ctx, "disseminator", endpoint, cfg.Protocol(), cfg.Disseminator(), getServiceMetrics(m), publishHook,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize l1 tx manager: %w", err)
 	}
+	l1Client, err := eth.DialWithRetry(ctx, cfg.L1().GetEndpoint())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize l1 client: %w", err)
+	}
+	encoder, err := createBatchEncoder(cfg.Disseminator())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize batch encoder: %w", err)
+	}
 	var (
-		encoder      = derivation.NewBatchV0Encoder(cfg)
-		batchBuilder = derivation.NewBatchBuilder(cfg, encoder)
+		batchBuilder = derivation.NewBatchBuilder(cfg, encoder, getServiceMetrics(m))
 		l2Client     = eth.NewLazilyDialedEthClient(cfg.L2().GetEndpoint())
+		// Batches may be ready as soon as L2 blocks are produced, or L1 advances (e.g. due to timeouts).
+		newHeads = eth.SubscribeNewHeads(ctx, syncers.L1.LatestHeaderBroker, syncers.L2.LatestHeaderBroker)
 	)
-	return disseminatorService.NewBatchDisseminator(cfg.Disseminator(), batchBuilder, l1TxMgr, l1State, l2Client), nil
+	return disseminatorService.NewBatchDisseminator(
+		cfg.Disseminator(), batchBuilder, l1TxMgr, da.NewL1DAProvider(), l1State, l1Client, l2Client, checkpoints, newHeads,
+	), nil
 }
 
-func NewValidator(
+// L1 clients acting on behalf of the validator's account.
+// Shared by the validator and challenger services, so that their txs are sent with consistent nonces.
+type ValidatorClients struct {
+	TxMgr        *bridge.TxManager
+	BridgeClient *bridge.BridgeClient
+}
+
+func NewValidatorClients(
 	ctx context.Context,
 	cfg *services.SystemConfig,
-	l1State *eth.EthState,
-) (*validatorService.Validator, error) {
+	m *metrics.Metrics,
+) (*ValidatorClients, error) {
 	if !cfg.Validator().GetIsEnabled() {
-		log.Info("validator is not enabled")
 		return nil, nil
 	}
 	var endpoint string
@@ -73,7 +123,7 @@ func NewValidator(
 	} else {
 		endpoint = cfg.L1().Endpoint
 	}
-	l1TxMgr, err := createTxManager(ctx, "validator", endpoint, cfg.Protocol(), cfg.Validator())
+	l1TxMgr, err := createTxManager(ctx, "validator", endpoint, cfg.Protocol(), cfg.Validator(), getServiceMetrics(m), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize l1 tx manager: %w", err)
 	}
@@ -85,8 +135,106 @@ func NewValidator(
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize l1 bridge client: %w", err)
 	}
-	l2Client := eth.NewLazilyDialedEthClient(cfg.L2().GetEndpoint())
-	return validatorService.NewValidator(cfg.Validator(), l1TxMgr, l1BridgeClient, l1State, l2Client), nil
+	return &ValidatorClients{TxMgr: l1TxMgr, BridgeClient: l1BridgeClient}, nil
+}
+
+func NewValidator(
+	ctx context.Context,
+	cfg *services.SystemConfig,
+	l1State *eth.EthState,
+	syncers *HeadSyncers,
+	clients *ValidatorClients,
+	m *metrics.Metrics,
+) (*validatorService.Validator, error) {
+	if !cfg.Validator().GetIsEnabled() {
+		log.Info("validator is not enabled")
+		return nil, nil
+	}
+	var (
+		l2Client = eth.NewLazilyDialedEthClient(cfg.L2().GetEndpoint())
+		// Assertions may be created once the L2 safe head advances, and resolved once L1 advances.
+		newHeads = eth.SubscribeNewHeads(ctx, syncers.L1.LatestHeaderBroker, syncers.L2.SafeHeaderBroker)
+	)
+	pipeline, err := createDerivationPipeline(ctx, cfg, l2Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize derivation pipeline: %w", err)
+	}
+	return validatorService.NewValidator(
+		cfg.Validator(), clients.TxMgr, clients.BridgeClient, l1State, l2Client, pipeline, getServiceMetrics(m), newHeads,
+	), nil
+}
+
+// Creates a pipeline deriving L2 blocks from the batches appended to L1, to verify the local L2 chain against.
+func createDerivationPipeline(
+	ctx context.Context,
+	cfg *services.SystemConfig,
+	l2Client derivation.L2ChainReader,
+) (*derivation.DerivationPipeline, error) {
+	l1Client, err := eth.DialWithRetry(ctx, cfg.L1().GetEndpoint())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize l1 client: %w", err)
+	}
+	// Blobs are only served by the beacon node.
+	var blobs derivation.BlobSource
+	if endpoint := cfg.L1().GetBeaconEndpoint(); endpoint != "" {
+		blobs = eth.NewBeaconClient(endpoint)
+	} else {
+		log.Warn("No L1 beacon endpoint configured; unable to verify batches carried in blobs.")
+	}
+	return derivation.NewDerivationPipeline(cfg, l1Client, l2Client, da.NewL1DAProvider(), blobs), nil
+}
+
+// Creates a challenger, driving the challenges the validator takes part in. Only enabled alongside the validator.
+func NewChallenger(
+	ctx context.Context,
+	cfg *services.SystemConfig,
+	l1State *eth.EthState,
+	syncers *HeadSyncers,
+	clients *ValidatorClients,
+) (*challengerService.Challenger, error) {
+	if !cfg.Validator().GetIsEnabled() {
+		log.Info("challenger is not enabled")
+		return nil, nil
+	}
+	var (
+		accountAddr = cfg.Validator().GetAccountAddr()
+		// Challenge deadlines are measured in L1 blocks.
+		newHeads = eth.SubscribeNewHeads(ctx, syncers.L1.LatestHeaderBroker)
+	)
+	responders := map[bridge.ChallengeProtocol]challengerService.Responder{}
+	// Execution states are generated by an L2 EL node serving the `proof` namespace.
+	if proofEndpoint := cfg.L2().GetProofEndpoint(); proofEndpoint != "" {
+		var (
+			// The same node serves the state read by state commitments.
+			l2Client  = eth.NewLazilyDialedEthClient(proofEndpoint)
+			committer = validatorService.NewStateCommitter(cfg.Validator().GetStateCommitmentVersion(), l2Client)
+			states    = challengerService.NewProofStateSource(l2Client, committer)
+		)
+		responders[bridge.SymChallengeProtocol] = challengerService.NewSymResponder(
+			accountAddr, clients.TxMgr, clients.BridgeClient, states,
+		)
+	} else {
+		log.Warn("No L2 proof endpoint configured; unable to play symmetric challenges.")
+	}
+	return challengerService.NewChallenger(
+		cfg.Validator(), clients.TxMgr, clients.BridgeClient, l1State, responders, newHeads,
+	), nil
+}
+
+// Creates a batch encoder for the configured encoding format version.
+func createBatchEncoder(cfg services.DisseminatorConfig) (derivation.VersionedDataEncoder, error) {
+	switch cfg.GetBatchEncoderVersion() {
+	case uint64(derivation.V0):
+		return derivation.NewBatchV0Encoder(cfg), nil
+	case uint64(derivation.V1):
+		algo, err := derivation.ParseCompressionAlgo(cfg.GetCompressionAlgo())
+		if err != nil {
+			return nil, err
+		}
+		return derivation.NewBatchV1Encoder(cfg, algo)
+	default:
+		return nil, fmt.Errorf("unsupported batch encoder version: %d", cfg.GetBatchEncoderVersion())
+	}
 }
 
 func createTxManager(
@@ -95,62 +243,122 @@ func createTxManager(
 	l1RpcUrl string,
 	protocolCfg services.ProtocolConfig,
 	serCfg serviceCfg,
+	m serviceMetrics,
+	publishHook func(tx *ethTypes.Transaction), // optional
 ) (*bridge.TxManager, error) {
-	transactor, err := createTransactor(
-		serCfg.GetAccountAddr(), serCfg.GetClefEndpoint(), serCfg.GetPrivateKey(), protocolCfg.GetL1ChainID(),
-	)
+	inner, err := createL1TxManager(ctx, name, l1RpcUrl, protocolCfg, serCfg, m)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize transactor: %w", err)
+		return nil, err
+	}
+	if publishHook != nil {
+		inner.SetPublishHook(publishHook)
 	}
-	log.Info("created transactor for", "addr", transactor.From)
+	return bridge.NewTxManager(inner, protocolCfg)
+}
+
+func createL1TxManager(
+	ctx context.Context,
+	name string,
+	l1RpcUrl string,
+	protocolCfg services.ProtocolConfig,
+	serCfg serviceCfg,
+	m serviceMetrics,
+) (*txmgr.TxManager, error) {
+	signerProvider, err := signer.NewProvider(serCfg.GetSignerCfg(), new(big.Int).SetUint64(protocolCfg.GetL1ChainID()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize signer: %w", err)
+	}
+	log.Info("created signer for", "addr", signerProvider.Address(), "type", serCfg.GetSignerCfg().GetType())
 
 	l1Client, err := eth.DialWithRetry(ctx, l1RpcUrl)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize l1 client: %w", err)
 	}
-	var (
-		signer = func(ctx context.Context, address common.Address, tx *ethTypes.Transaction) (*ethTypes.Transaction, error) {
-			return transactor.Signer(address, tx)
-		}
-		txMgrCfg = serCfg.GetTxMgrCfg()
-		inner    = txmgr.NewTxManager(log.New("service", name), txMgrCfg, l1Client, signer, &metrics.NoopTxMetrics{})
+	txMgr := txmgr.NewTxManager(
+		log.New("service", name), serCfg.GetTxMgrCfg(), l1Client, signer.SignerFn(signerProvider), m.NewTxMetrics(name),
 	)
-	return bridge.NewTxManager(inner, protocolCfg)
+	// Only one process may send txs from the service's account at a time (see `CancelPendingTxs`).
+	if err := txMgr.LockJournal(); err != nil {
+		return nil, fmt.Errorf("failed to lock tx journal: %w", err)
+	}
+	return txMgr, nil
 }
 
-// Creates a transactor for the given account address, either using a clef endpoint (preferred) or secret key.
-func createTransactor(
-	accountAddress common.Address,
-	clefEndpoint string,
-	secretKey *ecdsa.PrivateKey,
-	chainID uint64,
-) (*bind.TransactOpts, error) {
-	if clefEndpoint != "" {
-		clef, err := external.NewExternalSigner(clefEndpoint)
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize external signer from clef endpoint: %w", err)
+// Cancels all pending L1 txs of the given service's account (see `txmgr.TxManager.CancelPending`).
+// Refuses to run alongside the service itself, which holds the lock on its tx journal.
+func CancelPendingTxs(ctx context.Context, cfg *services.SystemConfig, service string) error {
+	var serCfg serviceCfg
+	switch service {
+	case "disseminator":
+		if !cfg.Disseminator().GetIsEnabled() {
+			return fmt.Errorf("disseminator is not enabled")
+		}
+		serCfg = cfg.Disseminator()
+	case "validator":
+		if !cfg.Validator().GetIsEnabled() {
+			return fmt.Errorf("validator is not enabled")
 		}
-		return bind.NewClefTransactor(clef, accounts.Account{Address: accountAddress}), nil
+		serCfg = cfg.Validator()
+	default:
+		return fmt.Errorf("unknown service: %s", service)
+	}
+	if serCfg.GetTxMgrCfg().JournalPath == "" {
+		return fmt.Errorf("no tx journal path configured; unable to check that the %s isn't running", service)
+	}
+	endpoint := cfg.L1().GetEndpoint()
+	if cfg.L1().SubmissionEndpoint != "" {
+		endpoint = cfg.L1().SubmissionEndpoint
+	}
+	l1TxMgr, err := createL1TxManager(ctx, service, endpoint, cfg.Protocol(), serCfg, &metrics.NoopMetrics{})
+	if err != nil {
+		return fmt.Errorf("failed to initialize l1 tx manager: %w", err)
 	}
-	log.Warn("No external signer specified, using geth signer")
-	return bind.NewKeyedTransactorWithChainID(secretKey, new(big.Int).SetUint64(chainID))
+	return l1TxMgr.CancelPending(ctx)
 }
 
-func createL1State(ctx context.Context, cfg *services.SystemConfig) (*eth.EthState, error) {
+// Syncers tracking L1 and L2 heads, whose brokers services subscribe to.
+type HeadSyncers struct {
+	L1State *eth.EthState
+	L1      *eth.EthSyncer
+	L2      *eth.EthSyncer
+}
+
+func NewHeadSyncers(ctx context.Context, log log.Logger, cfg *services.SystemConfig) (*HeadSyncers, error) {
 	log.Info("Starting L1 sync...")
+	l1State, l1Syncer, err := createL1State(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Starting L2 sync...")
+	return &HeadSyncers{l1State, l1Syncer, createL2Syncer(ctx, cfg)}, nil
+}
+
+func NewL1State(syncers *HeadSyncers) *eth.EthState {
+	return syncers.L1State
+}
 
+func createL1State(ctx context.Context, cfg *services.SystemConfig) (*eth.EthState, *eth.EthSyncer, error) {
 	l1Client, err := eth.DialWithRetry(ctx, cfg.L1().GetEndpoint())
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize l1 client: %w", err)
+		return nil, nil, fmt.Errorf("failed to initialize l1 client: %w", err)
 	}
 	l1State := eth.NewEthState()
 	l1Syncer := eth.NewEthSyncer(l1State)
 	l1Syncer.Start(ctx, l1Client)
-	return l1State, nil
+	return l1State, l1Syncer, nil
 }
 
-func NewL1State(ctx context.Context, log log.Logger, cfg *services.SystemConfig) (*eth.EthState, error) {
-	log.Info("Starting L1 sync...")
-
-	return createL1State(ctx, cfg)
+// Creates a syncer for L2 heads. Since the L2 node may not be up yet, it's dialed (and synced) in the background.
+func createL2Syncer(ctx context.Context, cfg *services.SystemConfig) *eth.EthSyncer {
+	l2Syncer := eth.NewEthSyncer(eth.NewEthState())
+	go func() {
+		l2Client, err := eth.DialWithRetry(ctx, cfg.L2().GetEndpoint())
+		if err != nil {
+			log.Errorf("Failed to initialize l2 client for syncing: %w", err)
+			return
+		}
+		pollInterval := cfg.L2().GetPollInterval()
+		l2Syncer.StartWithPollIntervals(ctx, l2Client, pollInterval, pollInterval)
+	}()
+	return l2Syncer
 }
@@ -19,6 +19,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -28,6 +29,9 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/specularL2/specular/services/sidecar/proof/prover"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
 )
 
 // Backend interface provides the common API services (that are provided by
@@ -49,14 +53,24 @@ type Backend interface {
 	StateAtTransaction(ctx context.Context, block *types.Block, txIndex int, reexec uint64) (*core.Message, vm.BlockContext, *state.StateDB, tracers.StateReleaseFunc, error)
 }
 
+const (
+	// Maximum number of states returned by a single paginated or indexed request.
+	maxStatesPerRequest = 4096
+	// Number of block ranges whose state indices are cached.
+	stateIndexCacheSize = 16
+)
+
 // ProverAPI is the collection of Specular one-step proof APIs.
 type ProverAPI struct {
 	backend Backend
+	indices *lru.Cache[blockRange, *StateIndex]
 }
 
+type blockRange struct{ start, end uint64 }
+
 // NewAPI creates a new API definition for the Specular one-step proof services.
 func NewAPI(backend Backend) *ProverAPI {
-	return &ProverAPI{backend: backend}
+	return &ProverAPI{backend: backend, indices: lru.NewCache[blockRange, *StateIndex](stateIndexCacheSize)}
 }
 
 type chainContext struct {
@@ -87,11 +101,152 @@ func createChainContext(backend Backend, ctx context.Context) core.ChainContext
 	return &chainContext{backend: backend, ctx: ctx}
 }
 
+// GenerateStates returns the execution states across blocks [start, end) (see `GenerateStates`).
+// For long ranges, prefer `GenerateStatesPage` or `GenerateStatesAt`.
+func (api *ProverAPI) GenerateStates(ctx context.Context, start, end uint64, config *ProverConfig) ([]*ExecutionState, error) {
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+	return GenerateStates(api.backend, ctx, start, end, config)
+}
+
+// CountStates returns the number of execution states across blocks [start, end).
+func (api *ProverAPI) CountStates(ctx context.Context, start, end uint64, config *ProverConfig) (uint64, error) {
+	index, err := api.stateIndex(ctx, start, end, config)
+	if err != nil {
+		return 0, err
+	}
+	return index.NumStates(), nil
+}
+
+// GenerateStatesAt returns the execution states at the given indices across blocks [start, end) (in order),
+// re-executing only the transactions containing them.
+func (api *ProverAPI) GenerateStatesAt(
+	ctx context.Context, start, end uint64, indices []uint64, config *ProverConfig,
+) ([]*ExecutionState, error) {
+	if len(indices) > maxStatesPerRequest {
+		return nil, fmt.Errorf("too many states requested (%d > %d)", len(indices), maxStatesPerRequest)
+	}
+	index, err := api.stateIndex(ctx, start, end, config)
+	if err != nil {
+		return nil, err
+	}
+	return index.StatesAt(api.backend, ctx, indices, config)
+}
+
+// GenerateStatesPage returns up to `limit` execution states across blocks [start, end), starting at index `offset`.
+// An empty page marks the end of the states.
+func (api *ProverAPI) GenerateStatesPage(
+	ctx context.Context, start, end uint64, offset, limit uint64, config *ProverConfig,
+) ([]*ExecutionState, error) {
+	if limit > maxStatesPerRequest {
+		return nil, fmt.Errorf("page too large (%d > %d)", limit, maxStatesPerRequest)
+	}
+	index, err := api.stateIndex(ctx, start, end, config)
+	if err != nil {
+		return nil, err
+	}
+	var indices []uint64
+	for i := offset; i < offset+limit && i < index.NumStates(); i++ {
+		indices = append(indices, i)
+	}
+	return index.StatesAt(api.backend, ctx, indices, config)
+}
+
+// GenerateProof returns the encoded one-step proof of the step starting from the given state.
+func (api *ProverAPI) GenerateProof(ctx context.Context, state ExecutionStateRef, config *ProverConfig) (hexutil.Bytes, error) {
+	block, err := api.blockByHash(ctx, state.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	startState := &ExecutionState{
+		VMHash:         state.VMHash,
+		Block:          block,
+		TransactionIdx: state.TransactionIdx,
+		StepIdx:        state.StepIdx,
+	}
+	proof, err := GenerateProof(api.backend, ctx, startState, config)
+	if err != nil {
+		return nil, err
+	}
+	return proof.Encode(), nil
+}
+
+// StateAt returns the execution state at step `step` of transaction `txIdx` in the given block (see `StateAt`).
+func (api *ProverAPI) StateAt(
+	ctx context.Context, blockHash common.Hash, txIdx uint64, step uint64, config *ProverConfig,
+) (*ExecutionState, error) {
+	block, err := api.blockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return StateAt(api.backend, ctx, block, txIdx, step, config)
+}
+
+// ProveTransaction returns the encoded one-step proof of the first step of transaction `hash` starting from state `target`.
 func (api *ProverAPI) ProveTransaction(ctx context.Context, hash common.Hash, target common.Hash, config *ProverConfig) (hexutil.Bytes, error) {
-	return hexutil.Bytes{}, nil
+	proof, err := ProveTransaction(api.backend, ctx, hash, target, config)
+	if err != nil {
+		return nil, err
+	}
+	return proof.Encode(), nil
+}
+
+// DebugTransaction returns up to `limit` steps of the trace of transaction `hash`, starting at step `offset+1`
+// (see `DebugTransaction`). An empty page marks the end of the trace.
+func (api *ProverAPI) DebugTransaction(
+	ctx context.Context, hash common.Hash, offset, limit uint64, config *ProverConfig,
+) ([]prover.TraceStep, error) {
+	if limit > maxStatesPerRequest {
+		return nil, fmt.Errorf("page too large (%d > %d)", limit, maxStatesPerRequest)
+	}
+	return DebugTransaction(api.backend, ctx, hash, offset, limit, config)
+}
+
+// Returns the (cached, if still canonical) state index of blocks [start, end).
+func (api *ProverAPI) stateIndex(ctx context.Context, start, end uint64, config *ProverConfig) (*StateIndex, error) {
+	if err := validateRange(start, end); err != nil {
+		return nil, err
+	}
+	key := blockRange{start, end}
+	if index, ok := api.indices.Get(key); ok {
+		canonical, err := index.IsCanonical(api.backend, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if canonical {
+			return index, nil
+		}
+	}
+	index, err := NewStateIndex(api.backend, ctx, start, end, config)
+	if err != nil {
+		return nil, err
+	}
+	api.indices.Add(key, index)
+	return index, nil
+}
+
+func validateRange(start, end uint64) error {
+	if start == 0 || end <= start {
+		return fmt.Errorf("invalid block range [%d, %d)", start, end)
+	}
+	return nil
+}
+
+func (api *ProverAPI) blockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	block, err := api.backend.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", hash)
+	}
+	return block, nil
 }
 
-// APIs return the collection of RPC services the tracer package offers.
+// APIs return the collection of RPC services the prover package offers.
+// EL nodes serving them register them on their stack, e.g. `stack.RegisterAPIs(proof.APIs(eth.APIBackend))`,
+// and expose the "proof" namespace over RPC.
 func APIs(backend Backend) []rpc.API {
 	// Append all the local APIs and return
 	return []rpc.API{
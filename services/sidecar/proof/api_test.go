@@ -0,0 +1,42 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIsServedOverRPC(t *testing.T) {
+	server := rpc.NewServer()
+	for _, api := range APIs(nil) {
+		require.NoError(t, server.RegisterName(api.Namespace, api.Service))
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var states []ExecutionStateRef
+	err := client.CallContext(context.Background(), &states, "proof_generateStates", 2, 1)
+	require.ErrorContains(t, err, "invalid block range")
+
+	var count uint64
+	err = client.CallContext(context.Background(), &count, "proof_countStates", 0, 1)
+	require.ErrorContains(t, err, "invalid block range")
+	err = client.CallContext(context.Background(), &states, "proof_generateStatesPage", 1, 2, 0, maxStatesPerRequest+1)
+	require.ErrorContains(t, err, "page too large")
+}
@@ -0,0 +1,25 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+// CodeProof reveals the code being executed, from which the opcode (and any immediate) at `pc` is read.
+// It's checked against the code hash of the VM state.
+type CodeProof struct {
+	Code []byte
+}
+
+func (p *CodeProof) Encode() []byte {
+	return (&encoder{}).bytes(p.Code).buf
+}
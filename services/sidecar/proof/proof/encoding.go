@@ -0,0 +1,70 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+// Proofs are encoded as the concatenation of their fields, using fixed-width big-endian integers:
+// uint64s as 8 bytes, uint256s and hashes as 32 bytes, and addresses as 20 bytes.
+// Variable-length fields (byte strings and lists) are prefixed with their length, as a uint64.
+type encoder struct{ buf []byte }
+
+func (e *encoder) uint64(v uint64) *encoder {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+	return e
+}
+
+func (e *encoder) uint256(v *uint256.Int) *encoder {
+	b := v.Bytes32()
+	e.buf = append(e.buf, b[:]...)
+	return e
+}
+
+func (e *encoder) hash(h common.Hash) *encoder {
+	e.buf = append(e.buf, h[:]...)
+	return e
+}
+
+func (e *encoder) address(a common.Address) *encoder {
+	e.buf = append(e.buf, a[:]...)
+	return e
+}
+
+func (e *encoder) bytes(b []byte) *encoder {
+	e.uint64(uint64(len(b)))
+	e.buf = append(e.buf, b...)
+	return e
+}
+
+func (e *encoder) hashes(hs []common.Hash) *encoder {
+	e.uint64(uint64(len(hs)))
+	for _, h := range hs {
+		e.hash(h)
+	}
+	return e
+}
+
+func (e *encoder) bytesList(bs [][]byte) *encoder {
+	e.uint64(uint64(len(bs)))
+	for _, b := range bs {
+		e.bytes(b)
+	}
+	return e
+}
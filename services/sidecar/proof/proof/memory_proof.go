@@ -0,0 +1,166 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Memory is merkleized in 32-byte cells, padded with zero cells to a power of two.
+const MemoryCellSize = 32
+
+// MemoryProof reveals the memory cells accessed by the proven step, with their merkle proofs against the memory root.
+// Cells beyond the current memory size are zero, and not included.
+type MemoryProof struct {
+	Cells []MemoryCell
+}
+
+type MemoryCell struct {
+	Index    uint64
+	Value    common.Hash
+	Siblings []common.Hash // From the leaf up.
+}
+
+// NewMemoryProof returns the proof of the cells overlapping `size` bytes at `offset`.
+func NewMemoryProof(memory []byte, offset, size uint64) *MemoryProof {
+	p := &MemoryProof{}
+	numCells := uint64(len(memory)) / MemoryCellSize
+	if size == 0 || offset >= numCells*MemoryCellSize {
+		return p
+	}
+	tree := newMemoryTree(memory)
+	last := numCells - 1
+	if end := offset + size; end > offset && (end-1)/MemoryCellSize < last {
+		last = (end - 1) / MemoryCellSize
+	}
+	for i := offset / MemoryCellSize; i <= last; i++ {
+		p.Cells = append(p.Cells, MemoryCell{
+			Index:    i,
+			Value:    common.BytesToHash(memory[i*MemoryCellSize : (i+1)*MemoryCellSize]),
+			Siblings: tree.siblings(i),
+		})
+	}
+	return p
+}
+
+func (p *MemoryProof) Encode() []byte {
+	e := &encoder{}
+	e.uint64(uint64(len(p.Cells)))
+	for _, cell := range p.Cells {
+		e.uint64(cell.Index).hash(cell.Value).hashes(cell.Siblings)
+	}
+	return e.buf
+}
+
+// MemoryRoot returns the merkle root of the memory. The empty memory has a zero root.
+func MemoryRoot(memory []byte) common.Hash {
+	if len(memory) == 0 {
+		return common.Hash{}
+	}
+	return newMemoryTree(memory).root()
+}
+
+// MemoryHasher computes `MemoryRoot` incrementally across successive states of a memory,
+// only rehashing the cells that changed since the last call (and their ancestors).
+type MemoryHasher struct {
+	memory []byte // As of the last call.
+	tree   memoryTree
+}
+
+func (h *MemoryHasher) Root(memory []byte) common.Hash {
+	if len(memory) == 0 {
+		return common.Hash{}
+	}
+	// The tree is rebuilt when it grows.
+	if len(h.tree) == 0 || len(h.tree[0]) != numMemoryLeaves(memory) {
+		h.tree = newMemoryTree(memory)
+		h.memory = append(h.memory[:0], memory...)
+		return h.tree.root()
+	}
+	var dirty []int
+	for i := 0; i*MemoryCellSize < len(memory); i++ {
+		start, end := i*MemoryCellSize, (i+1)*MemoryCellSize
+		if end > len(h.memory) || !bytes.Equal(memory[start:end], h.memory[start:end]) {
+			h.tree[0][i] = crypto.Keccak256Hash(memory[start:end])
+			dirty = append(dirty, i)
+		}
+	}
+	for level := 1; level < len(h.tree) && len(dirty) > 0; level++ {
+		// Dirty indices are in increasing order, so siblings' parents are adjacent.
+		parents := dirty[:0]
+		for _, i := range dirty {
+			if parent := i / 2; len(parents) == 0 || parents[len(parents)-1] != parent {
+				parents = append(parents, parent)
+			}
+		}
+		for _, i := range parents {
+			h.tree[level][i] = crypto.Keccak256Hash(h.tree[level-1][2*i][:], h.tree[level-1][2*i+1][:])
+		}
+		dirty = parents
+	}
+	h.memory = append(h.memory[:0], memory...)
+	return h.tree.root()
+}
+
+// Levels of a memory merkle tree, from the leaves up.
+type memoryTree [][]common.Hash
+
+// Returns the number of leaves of the memory's tree, i.e. its number of cells rounded up to a power of two.
+func numMemoryLeaves(memory []byte) int {
+	numLeaves := 1
+	for numLeaves*MemoryCellSize < len(memory) {
+		numLeaves *= 2
+	}
+	return numLeaves
+}
+
+func newMemoryTree(memory []byte) memoryTree {
+	numLeaves := numMemoryLeaves(memory)
+	var (
+		leaves   = make([]common.Hash, numLeaves)
+		zeroLeaf = crypto.Keccak256Hash(make([]byte, MemoryCellSize))
+	)
+	for i := range leaves {
+		if start := i * MemoryCellSize; start < len(memory) {
+			leaves[i] = crypto.Keccak256Hash(memory[start : start+MemoryCellSize])
+		} else {
+			leaves[i] = zeroLeaf
+		}
+	}
+	tree := memoryTree{leaves}
+	for level := leaves; len(level) > 1; {
+		parents := make([]common.Hash, len(level)/2)
+		for i := range parents {
+			parents[i] = crypto.Keccak256Hash(level[2*i][:], level[2*i+1][:])
+		}
+		tree = append(tree, parents)
+		level = parents
+	}
+	return tree
+}
+
+func (t memoryTree) root() common.Hash { return t[len(t)-1][0] }
+
+func (t memoryTree) siblings(index uint64) []common.Hash {
+	siblings := make([]common.Hash, 0, len(t)-1)
+	for _, level := range t[:len(t)-1] {
+		siblings = append(siblings, level[index^1])
+		index /= 2
+	}
+	return siblings
+}
@@ -0,0 +1,134 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+// Recomputes the memory root from a cell and its siblings.
+func memoryRootFromCell(cell MemoryCell) common.Hash {
+	hash, index := crypto.Keccak256Hash(cell.Value[:]), cell.Index
+	for _, sibling := range cell.Siblings {
+		if index%2 == 0 {
+			hash = crypto.Keccak256Hash(hash[:], sibling[:])
+		} else {
+			hash = crypto.Keccak256Hash(sibling[:], hash[:])
+		}
+		index /= 2
+	}
+	return hash
+}
+
+func TestMemoryProof(t *testing.T) {
+	memory := make([]byte, 3*MemoryCellSize)
+	for i := range memory {
+		memory[i] = byte(i)
+	}
+	root := MemoryRoot(memory)
+
+	// The range spans the last two (non-padding) cells.
+	p := NewMemoryProof(memory, 40, 100)
+	require.Len(t, p.Cells, 2)
+	for i, cell := range p.Cells {
+		require.Equal(t, uint64(i+1), cell.Index)
+		require.Equal(t, common.BytesToHash(memory[(i+1)*MemoryCellSize:(i+2)*MemoryCellSize]), cell.Value)
+		require.Len(t, cell.Siblings, 2)
+		require.Equal(t, root, memoryRootFromCell(cell))
+	}
+
+	require.Empty(t, NewMemoryProof(memory, 0, 0).Cells)
+	require.Empty(t, NewMemoryProof(memory, 3*MemoryCellSize, 32).Cells)
+	require.Equal(t, common.Hash{}, MemoryRoot(nil))
+}
+
+func TestMemoryHasher(t *testing.T) {
+	var (
+		h      MemoryHasher
+		memory []byte
+	)
+	for _, update := range []func(){
+		func() { memory = make([]byte, MemoryCellSize) },
+		func() { memory[3] = 1 },
+		func() { memory = append(memory, make([]byte, 4*MemoryCellSize)...) },
+		func() { memory[2*MemoryCellSize], memory[4*MemoryCellSize+1] = 2, 3 },
+		func() {}, // Unchanged.
+		func() { memory = nil },
+		func() { memory = make([]byte, 2*MemoryCellSize) }, // Shrunk, e.g. in another frame.
+	} {
+		update()
+		require.Equal(t, MemoryRoot(memory), h.Root(memory))
+	}
+}
+
+func TestStackHasher(t *testing.T) {
+	var (
+		h     StackHasher
+		stack []uint256.Int
+	)
+	for _, update := range []func(){
+		func() { stack = append(stack, *uint256.NewInt(1), *uint256.NewInt(2)) },
+		func() { stack = append(stack, *uint256.NewInt(3)) },
+		func() { stack[1] = *uint256.NewInt(4) },
+		func() { stack = stack[:1] },
+		func() {}, // Unchanged.
+		func() { stack = nil },
+	} {
+		update()
+		require.Equal(t, StackHash(stack), h.Hash(stack))
+	}
+}
+
+func TestStackProof(t *testing.T) {
+	stack := []uint256.Int{*uint256.NewInt(1), *uint256.NewInt(2), *uint256.NewInt(3)}
+	p := NewStackProof(stack, 2)
+	require.Equal(t, StackHash(stack[:1]), p.RestHash)
+	require.Equal(t, stack[1:], p.Items)
+
+	hash := p.RestHash
+	for i := range p.Items {
+		hash = pushStackHash(hash, &p.Items[i])
+	}
+	require.Equal(t, StackHash(stack), hash)
+}
+
+func TestVMStateProofEncoding(t *testing.T) {
+	state := &VMStateProof{
+		BlockNumber:    1,
+		TransactionIdx: 2,
+		Depth:          3,
+		CallStackHash:  common.HexToHash("0x06"),
+		Gas:            4,
+		Refund:         5,
+		Pc:             6,
+		Contract:       common.HexToAddress("0xc0"),
+		CodeHash:       common.HexToHash("0x01"),
+		StackSize:      7,
+		StackHash:      common.HexToHash("0x02"),
+		MemorySize:     8,
+		MemoryRoot:     common.HexToHash("0x03"),
+		ReturnDataHash: common.HexToHash("0x04"),
+		StateRoot:      common.HexToHash("0x05"),
+	}
+	// 8 uint64s, an address and 6 hashes.
+	require.Len(t, state.Encode(), 8*8+common.AddressLength+6*common.HashLength)
+	// Changing the encoding changes every state hash, and breaks challenges between nodes running different versions.
+	require.Equal(t, common.HexToHash("0xc8a7cb4a4543013c265f57e2392a4c89d68b022b8ece34b77d65d213bc2c45c1"), state.Hash())
+}
@@ -0,0 +1,82 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// StackProof reveals the stack items read by the proven step.
+// The stack hash is recomputed by pushing `Items` onto `RestHash`.
+type StackProof struct {
+	RestHash common.Hash   // Hash of the stack below the items read.
+	Items    []uint256.Int // Items read, from the bottom-most up to the top of the stack.
+}
+
+func NewStackProof(stack []uint256.Int, numItems int) *StackProof {
+	rest := len(stack) - numItems
+	return &StackProof{RestHash: StackHash(stack[:rest]), Items: stack[rest:]}
+}
+
+func (p *StackProof) Encode() []byte {
+	e := &encoder{}
+	e.hash(p.RestHash).uint64(uint64(len(p.Items)))
+	for i := range p.Items {
+		e.uint256(&p.Items[i])
+	}
+	return e.buf
+}
+
+// StackHash chains the stack items from the bottom up: H(...H(H(0 || item0) || item1)... || itemN).
+// The empty stack hashes to zero.
+func StackHash(stack []uint256.Int) common.Hash {
+	var hash common.Hash
+	for i := range stack {
+		hash = pushStackHash(hash, &stack[i])
+	}
+	return hash
+}
+
+func pushStackHash(hash common.Hash, item *uint256.Int) common.Hash {
+	b := item.Bytes32()
+	return crypto.Keccak256Hash(hash[:], b[:])
+}
+
+// StackHasher computes `StackHash` incrementally across successive states of a stack,
+// only rehashing the items above the lowest one that changed since the last call.
+type StackHasher struct {
+	items  []uint256.Int
+	hashes []common.Hash // hashes[i] is the hash of items[:i+1].
+}
+
+func (h *StackHasher) Hash(stack []uint256.Int) common.Hash {
+	n := 0
+	for n < len(stack) && n < len(h.items) && stack[n] == h.items[n] {
+		n++
+	}
+	h.items = append(h.items[:n], stack[n:]...)
+	h.hashes = h.hashes[:n]
+	var hash common.Hash
+	if n > 0 {
+		hash = h.hashes[n-1]
+	}
+	for i := n; i < len(stack); i++ {
+		hash = pushStackHash(hash, &stack[i])
+		h.hashes = append(h.hashes, hash)
+	}
+	return hash
+}
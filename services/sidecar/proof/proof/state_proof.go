@@ -0,0 +1,67 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// VMStateProof is the intra-transaction VM state, before executing the proven step.
+// Its hash (over its encoding: the fields in order, see `encoder`) is the state hash committed to in bisections,
+// so the encoding must remain stable.
+type VMStateProof struct {
+	BlockNumber    uint64
+	TransactionIdx uint64
+	Depth          uint64
+	CallStackHash  common.Hash // Commitment to the call frames below the current one (zero in the outermost).
+	Gas            uint64
+	Refund         uint64
+	Pc             uint64
+	Contract       common.Address
+	CodeHash       common.Hash
+	StackSize      uint64
+	StackHash      common.Hash // See `StackHash`.
+	MemorySize     uint64
+	MemoryRoot     common.Hash // See `MemoryRoot`.
+	ReturnDataHash common.Hash // Hash of the return data of the last call.
+	// Intermediate root of the world state before the step, including the transaction's state changes so far.
+	// Account and storage proofs of the step are against it.
+	StateRoot common.Hash
+}
+
+func (s *VMStateProof) Encode() []byte {
+	e := &encoder{}
+	e.uint64(s.BlockNumber).
+		uint64(s.TransactionIdx).
+		uint64(s.Depth).
+		hash(s.CallStackHash).
+		uint64(s.Gas).
+		uint64(s.Refund).
+		uint64(s.Pc).
+		address(s.Contract).
+		hash(s.CodeHash).
+		uint64(s.StackSize).
+		hash(s.StackHash).
+		uint64(s.MemorySize).
+		hash(s.MemoryRoot).
+		hash(s.ReturnDataHash).
+		hash(s.StateRoot)
+	return e.buf
+}
+
+func (s *VMStateProof) Hash() common.Hash {
+	return crypto.Keccak256Hash(s.Encode())
+}
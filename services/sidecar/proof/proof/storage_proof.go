@@ -0,0 +1,41 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AccountProof is a merkle-patricia proof of an account against the state root of the VM state.
+type AccountProof struct {
+	Address common.Address
+	Nodes   [][]byte // RLP-encoded trie nodes, from the root down.
+}
+
+func (p *AccountProof) Encode() []byte {
+	return (&encoder{}).address(p.Address).bytesList(p.Nodes).buf
+}
+
+// StorageProof is a merkle-patricia proof of a storage slot (holding `Value`) against the (proven) account's
+// storage root.
+type StorageProof struct {
+	Key   common.Hash
+	Value common.Hash
+	Nodes [][]byte // RLP-encoded trie nodes, from the root down.
+}
+
+func (p *StorageProof) Encode() []byte {
+	return (&encoder{}).hash(p.Key).hash(p.Value).bytesList(p.Nodes).buf
+}
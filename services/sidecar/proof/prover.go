@@ -17,13 +17,12 @@ package proof
 import (
 	"context"
 	"encoding/json"
-	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
-	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/specularL2/specular/services/sidecar/proof/proof"
 	"github.com/specularL2/specular/services/sidecar/proof/prover"
@@ -48,19 +47,25 @@ type ExecutionState struct {
 	StepIdx        uint64
 }
 
-func (s *ExecutionState) MarshalJson() ([]byte, error) {
-	return json.Marshal(&struct {
-		VMHash         common.Hash `json:"vmHash"`
-		L2GasUsed      *big.Int    `json:"l2GasUsed"`
-		BlockHash      common.Hash `json:"blockHash"`
-		TransactionIdx uint64      `json:"txnIdx"`
-		StepIdx        uint64      `json:"stepIdx"`
-	}{
+// ExecutionStateRef identifies an execution state by its block hash (i.e. the JSON form of an `ExecutionState`).
+type ExecutionStateRef struct {
+	VMHash         common.Hash `json:"vmHash"`
+	BlockHash      common.Hash `json:"blockHash"`
+	TransactionIdx uint64      `json:"txnIdx"`
+	StepIdx        uint64      `json:"stepIdx"`
+}
+
+func (s *ExecutionState) Ref() *ExecutionStateRef {
+	return &ExecutionStateRef{
 		VMHash:         s.VMHash,
 		BlockHash:      s.Block.Hash(),
 		TransactionIdx: s.TransactionIdx,
 		StepIdx:        s.StepIdx,
-	})
+	}
+}
+
+func (s *ExecutionState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Ref())
 }
 
 func (s *ExecutionState) Hash() common.Hash {
@@ -70,6 +75,7 @@ func (s *ExecutionState) Hash() common.Hash {
 // This function generates execution states across blocks [startNum, endNum)
 // For example there are 2 transactions: a, b
 // The states are: inter-state before a, intra-states in a, inter-state before b (after a), intra-states in b, inter-state after b
+// All states are kept in memory; for long ranges, see `IterateStates` and `StateIndex`.
 func GenerateStates(
 	backend Backend,
 	ctx context.Context,
@@ -77,82 +83,14 @@ func GenerateStates(
 	endNum uint64,
 	config *ProverConfig,
 ) ([]*ExecutionState, error) {
-	parent, err := backend.BlockByNumber(ctx, rpc.BlockNumber(startNum-1))
-	if err != nil {
-		return nil, err
-	}
-	reexec := defaultProveReexec
-	if config != nil && config.Reexec != nil {
-		reexec = *config.Reexec
-	}
-	statedb, _, err := backend.StateAtBlock(ctx, parent, reexec, nil, true, false)
+	var states []*ExecutionState
+	err := IterateStates(backend, ctx, startNum, endNum, config, func(state *ExecutionState) error {
+		states = append(states, state)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	var (
-		states []*ExecutionState
-		block  *types.Block
-	)
-	for num := startNum; num < endNum; num++ {
-		block, err = backend.BlockByNumber(ctx, rpc.BlockNumber(num))
-		if err != nil {
-			return nil, err
-		}
-		if block == nil {
-			return nil, fmt.Errorf("block #%d not found", num)
-		}
-		signer := types.MakeSigner(backend.ChainConfig(), block.Number(), block.Time())
-		blockCtx := core.NewEVMBlockContext(block.Header(), createChainContext(backend, ctx), nil)
-		// Trace all the transactions contained within
-		for i, tx := range block.Transactions() {
-			// Push inter-state hash
-			states = append(states, &ExecutionState{
-				VMHash:         statedb.IntermediateRoot(backend.ChainConfig().IsEIP158(block.Number())),
-				Block:          block,
-				TransactionIdx: uint64(i),
-				StepIdx:        0,
-			})
-			msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
-			txContext := core.NewEVMTxContext(msg)
-			prover := prover.NewStateGenerator()
-			// Run the transaction with tracing enabled.
-
-			vmenv := vm.NewEVM(blockCtx, txContext, statedb, backend.ChainConfig(), vm.Config{Tracer: prover, NoBaseFee: true})
-			rules := backend.ChainConfig().Rules(vmenv.Context.BlockNumber, vmenv.Context.Random != nil, vmenv.Context.Time)
-
-			// Call Prepare to clear out the statedb access list
-			statedb.Prepare(rules, msg.From, vmenv.Context.Coinbase, msg.To, vm.ActivePrecompiles(rules), msg.AccessList)
-			_, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit))
-			if err != nil {
-				return nil, fmt.Errorf("tracing failed: %w", err)
-			}
-			generatedStates, err := prover.GetGeneratedStates()
-			if err != nil {
-				return nil, fmt.Errorf("tracing failed: %w", err)
-			}
-			for idx, s := range generatedStates {
-				states = append(states, &ExecutionState{
-					VMHash:         s.VMHash,
-					Block:          block,
-					TransactionIdx: uint64(i),
-					StepIdx:        uint64(idx + 1),
-				})
-			}
-		}
-		// Get next statedb if we are not at the last block
-		if num < endNum-1 {
-			statedb, _, err = backend.StateAtBlock(ctx, block, reexec, statedb, true, false)
-			if err != nil {
-				return nil, err
-			}
-		}
-	}
-	states = append(states, &ExecutionState{
-		VMHash:         block.Root(),
-		Block:          block,
-		TransactionIdx: uint64(len(block.Transactions())),
-		StepIdx:        0,
-	})
 	return states, nil
 }
 
@@ -163,25 +101,205 @@ func GenerateProof(backend Backend, ctx context.Context, startState *ExecutionSt
 	if startState.TransactionIdx >= uint64(len(startState.Block.Transactions())) {
 		return nil, fmt.Errorf("bad start state")
 	}
+	msg, vmctx, statedb, root, err := stateAtTransaction(backend, ctx, startState.Block, int(startState.TransactionIdx), config)
+	if err != nil {
+		return nil, err
+	}
+	prover := prover.NewProver(startState.VMHash, startState.StepIdx, statedb, root)
+	if err := traceTransaction(backend, msg, vmctx, statedb, prover); err != nil {
+		return nil, err
+	}
+	return prover.GetProof()
+}
+
+// Returns the execution state at step `step` of transaction `txIdx` in the given block.
+// Step 0 is the inter-state before the transaction; `txIdx` may be the number of transactions, for the block's end state.
+func StateAt(
+	backend Backend,
+	ctx context.Context,
+	block *types.Block,
+	txIdx uint64,
+	step uint64,
+	config *ProverConfig,
+) (*ExecutionState, error) {
+	numTxs := uint64(len(block.Transactions()))
+	if txIdx > numTxs || (txIdx == numTxs && step > 0) {
+		return nil, fmt.Errorf("no step %d of transaction %d in block %s", step, txIdx, block.Hash())
+	}
+	if txIdx == numTxs {
+		return &ExecutionState{VMHash: block.Root(), Block: block, TransactionIdx: txIdx, StepIdx: 0}, nil
+	}
+	msg, vmctx, statedb, root, err := stateAtTransaction(backend, ctx, block, int(txIdx), config)
+	if err != nil {
+		return nil, err
+	}
+	if step == 0 {
+		return &ExecutionState{VMHash: root, Block: block, TransactionIdx: txIdx, StepIdx: 0}, nil
+	}
+	generator := prover.NewStateGenerator(txIdx)
+	if err := traceTransaction(backend, msg, vmctx, statedb, generator); err != nil {
+		return nil, err
+	}
+	states, err := generator.GetGeneratedStates()
+	if err != nil {
+		return nil, err
+	}
+	if step > uint64(len(states)) {
+		return nil, fmt.Errorf("transaction %d has %d steps, not %d", txIdx, len(states), step)
+	}
+	return &ExecutionState{VMHash: states[step-1].VMHash, Block: block, TransactionIdx: txIdx, StepIdx: step}, nil
+}
+
+// Generates the one-step proof of the first step of the given transaction that starts from state `target`.
+func ProveTransaction(
+	backend Backend,
+	ctx context.Context,
+	txHash common.Hash,
+	target common.Hash,
+	config *ProverConfig,
+) (*proof.OneStepProof, error) {
+	tx, blockHash, _, index, err := backend.GetTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %s not found", txHash)
+	}
+	block, err := backend.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockHash)
+	}
+	msg, vmctx, statedb, root, err := stateAtTransaction(backend, ctx, block, int(index), config)
+	if err != nil {
+		return nil, err
+	}
+	prover := prover.NewProverAtState(target, statedb, root)
+	if err := traceTransaction(backend, msg, vmctx, statedb, prover); err != nil {
+		return nil, err
+	}
+	return prover.GetProof()
+}
+
+// Returns up to `limit` steps of the trace of the given transaction, skipping the first `offset` (see `prover.DebugProver`).
+// Traces of the same transaction from two nodes can be compared with `prover.FirstDivergentStep`.
+func DebugTransaction(
+	backend Backend,
+	ctx context.Context,
+	txHash common.Hash,
+	offset uint64,
+	limit uint64,
+	config *ProverConfig,
+) ([]prover.TraceStep, error) {
+	_, blockHash, _, index, err := backend.GetTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if blockHash == (common.Hash{}) {
+		return nil, fmt.Errorf("transaction %s not found", txHash)
+	}
+	block, err := backend.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", blockHash)
+	}
+	msg, vmctx, statedb, _, err := stateAtTransaction(backend, ctx, block, int(index), config)
+	if err != nil {
+		return nil, err
+	}
+	debugger := prover.NewDebugProver(index, offset, limit)
+	if err := traceTransaction(backend, msg, vmctx, statedb, debugger); err != nil {
+		return nil, err
+	}
+	return debugger.GetTrace(), nil
+}
+
+// Returns the state to execute the given transaction on, committed (at the returned root) so that it can be proven.
+// The block's prior transactions are re-executed on a throwaway database (see `newThrowawayDatabase`),
+// so that the state (and intermediate ones, see `prover.OneStepProver`) can be committed without writing to the node's.
+func stateAtTransaction(
+	backend Backend,
+	ctx context.Context,
+	block *types.Block,
+	txIdx int,
+	config *ProverConfig,
+) (*core.Message, vm.BlockContext, *state.StateDB, common.Hash, error) {
+	if block.NumberU64() == 0 {
+		return nil, vm.BlockContext{}, nil, common.Hash{}, fmt.Errorf("no transaction in genesis")
+	}
+	if txIdx >= len(block.Transactions()) {
+		return nil, vm.BlockContext{}, nil, common.Hash{}, fmt.Errorf("transaction index %d out of range for block %s", txIdx, block.Hash())
+	}
 	reexec := defaultProveReexec
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	msg, vmctx, statedb, _, err := backend.StateAtTransaction(ctx, startState.Block, int(startState.TransactionIdx), reexec)
+	parent, err := backend.BlockByHash(ctx, block.ParentHash())
 	if err != nil {
-		return nil, err
+		return nil, vm.BlockContext{}, nil, common.Hash{}, err
 	}
+	if parent == nil {
+		return nil, vm.BlockContext{}, nil, common.Hash{}, fmt.Errorf("parent %s not found", block.ParentHash())
+	}
+	base, _, err := backend.StateAtBlock(ctx, parent, reexec, nil, true, false)
+	if err != nil {
+		return nil, vm.BlockContext{}, nil, common.Hash{}, err
+	}
+	statedb, err := state.New(parent.Root(), newThrowawayDatabase(base.Database()), nil)
+	if err != nil {
+		return nil, vm.BlockContext{}, nil, common.Hash{}, fmt.Errorf("failed to open parent state: %w", err)
+	}
+	var (
+		signer   = types.MakeSigner(backend.ChainConfig(), block.Number(), block.Time())
+		blockCtx = core.NewEVMBlockContext(block.Header(), createChainContext(backend, ctx), nil)
+		eip158   = backend.ChainConfig().IsEIP158(block.Number())
+	)
+	for i, tx := range block.Transactions()[:txIdx] {
+		msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
+		vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, backend.ChainConfig(), vm.Config{})
+		statedb.SetTxContext(tx.Hash(), i)
+		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas())); err != nil {
+			return nil, vm.BlockContext{}, nil, common.Hash{}, fmt.Errorf("transaction %s failed: %w", tx.Hash(), err)
+		}
+		statedb.Finalise(eip158)
+	}
+	root, err := statedb.Commit(block.NumberU64(), eip158)
+	if err != nil {
+		return nil, vm.BlockContext{}, nil, common.Hash{}, fmt.Errorf("failed to commit state: %w", err)
+	}
+	statedb, err = state.New(root, statedb.Database(), nil)
+	if err != nil {
+		return nil, vm.BlockContext{}, nil, common.Hash{}, fmt.Errorf("failed to reopen state: %w", err)
+	}
+	tx := block.Transactions()[txIdx]
+	msg, err := core.TransactionToMessage(tx, signer, block.BaseFee())
+	if err != nil {
+		return nil, vm.BlockContext{}, nil, common.Hash{}, fmt.Errorf("failed to convert transaction %s: %w", tx.Hash(), err)
+	}
+	statedb.SetTxContext(tx.Hash(), txIdx)
+	return msg, blockCtx, statedb, root, nil
+}
+
+func traceTransaction(
+	backend Backend,
+	msg *core.Message,
+	vmctx vm.BlockContext,
+	statedb *state.StateDB,
+	tracer vm.EVMLogger,
+) error {
 	txContext := core.NewEVMTxContext(msg)
-	prover := prover.NewProver(startState.VMHash, startState.StepIdx)
 	// Run the transaction with tracing enabled.
-	vmenv := vm.NewEVM(vmctx, txContext, statedb, backend.ChainConfig(), vm.Config{Tracer: prover, NoBaseFee: true})
+	vmenv := vm.NewEVM(vmctx, txContext, statedb, backend.ChainConfig(), vm.Config{Tracer: tracer, NoBaseFee: true})
 	rules := backend.ChainConfig().Rules(vmenv.Context.BlockNumber, vmenv.Context.Random != nil, vmenv.Context.Time)
 	// Call Prepare to clear out the statedb access list
 	statedb.Prepare(rules, msg.From, vmenv.Context.Coinbase, msg.To, vm.ActivePrecompiles(rules), msg.AccessList)
 
-	_, err = core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit))
-	if err != nil {
-		return nil, fmt.Errorf("tracing failed: %w", err)
+	if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit)); err != nil {
+		return fmt.Errorf("tracing failed: %w", err)
 	}
-	return prover.GetProof()
+	return nil
 }
@@ -15,38 +15,155 @@
 package prover
 
 import (
+	"bufio"
+	"encoding/json"
+	"io"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/vm"
-)
+	"github.com/holiman/uint256"
 
-type DebugProver struct{}
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
 
-func NewDebugProver() *DebugProver {
-	return &DebugProver{}
+// TraceStep is a step of a transaction, as recorded by `DebugProver`.
+type TraceStep struct {
+	Step       uint64       `json:"step"` // 1-indexed, as in `GenerateStates`.
+	Op         string       `json:"op"`
+	Pc         uint64       `json:"pc"`
+	Gas        uint64       `json:"gas"`
+	StackTop   *uint256.Int `json:"stackTop,omitempty"` // Nil if the stack is empty.
+	MemorySize uint64       `json:"memSize"`
+	Depth      uint64       `json:"depth"`
+	Hash       common.Hash  `json:"hash"` // Hash of the VM state before the step (see `StateGenerator`).
 }
 
-func (l *DebugProver) CaptureTxStart(gasLimit uint64) {
+// DebugProver records a structured trace of a window of a transaction's steps,
+// to find the first step at which two nodes diverge (see `FirstDivergentStep`).
+// Only the steps in the window are recorded (and hashed), so long transactions can be traced page by page.
+type DebugProver struct {
+	// Config
+	txIdx  uint64
+	offset uint64 // Number of steps to skip.
+	limit  uint64 // Maximum number of steps to record.
 
-}
+	// Context
+	env       *vm.EVM
+	callStack callStack
+	hasher    vmStateHasher
 
-func (l *DebugProver) CaptureTxEnd(restGas uint64) {
+	// Global
+	numSteps uint64
+	trace    []TraceStep
+}
 
+func NewDebugProver(txIdx uint64, offset, limit uint64) *DebugProver {
+	return &DebugProver{txIdx: txIdx, offset: offset, limit: limit}
 }
 
+func (l *DebugProver) CaptureTxStart(gasLimit uint64) {}
+
+func (l *DebugProver) CaptureTxEnd(restGas uint64) {}
+
 func (l *DebugProver) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	l.env = env
 }
 
 func (l *DebugProver) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	l.numSteps++
+	l.hasher.step(op)
+	recorded := l.numSteps > l.offset && uint64(len(l.trace)) < l.limit
+	// Steps entering a frame are hashed regardless, as later states commit to them (see `callStack`).
+	if !recorded && !entersFrame(op) {
+		return
+	}
+	step := TraceStep{
+		Step:       l.numSteps,
+		Op:         op.String(),
+		Pc:         pc,
+		Gas:        gas,
+		MemorySize: uint64(scope.Memory.Len()),
+		Depth:      uint64(depth),
+		Hash:       l.hasher.vmState(l.env, l.txIdx, l.callStack.hash(), pc, gas, scope, rData, depth).Hash(),
+	}
+	if entersFrame(op) {
+		l.callStack.caller = step.Hash
+	}
+	if !recorded {
+		return
+	}
+	if stack := scope.Stack.Data(); len(stack) > 0 {
+		step.StackTop = new(uint256.Int).Set(&stack[len(stack)-1])
+	}
+	l.trace = append(l.trace, step)
 }
 
 func (l *DebugProver) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	l.callStack.enter()
 }
 
-func (l *DebugProver) CaptureExit(output []byte, gasUsed uint64, err error) {}
+func (l *DebugProver) CaptureExit(output []byte, gasUsed uint64, err error) {
+	l.callStack.exit()
+	l.hasher.invalidate()
+}
 
 func (l *DebugProver) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
 }
 
 func (l *DebugProver) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+// Returns the recorded steps; empty if the transaction has no more than `offset` steps.
+func (l *DebugProver) GetTrace() []TraceStep { return l.trace }
+
+// WriteTrace writes the trace as JSON lines (one step per line).
+func WriteTrace(w io.Writer, trace []TraceStep) error {
+	enc := json.NewEncoder(w)
+	for i := range trace {
+		if err := enc.Encode(&trace[i]); err != nil {
+			return fmt.Errorf("failed to encode step %d: %w", trace[i].Step, err)
+		}
+	}
+	return nil
+}
+
+// ReadTrace reads a trace written by `WriteTrace`.
+func ReadTrace(r io.Reader) ([]TraceStep, error) {
+	var (
+		trace   []TraceStep
+		scanner = bufio.NewScanner(r)
+	)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var step TraceStep
+		if err := json.Unmarshal(scanner.Bytes(), &step); err != nil {
+			return nil, fmt.Errorf("failed to decode step %d: %w", len(trace)+1, err)
+		}
+		trace = append(trace, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+	return trace, nil
+}
+
+// FirstDivergentStep returns the index of the first step whose state differs between the traces,
+// or at which only one of them ends. Returns false if the traces are identical.
+func FirstDivergentStep(a, b []TraceStep) (int, bool) {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i].Hash != b[i].Hash {
+			return i, true
+		}
+	}
+	if len(a) != len(b) {
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,80 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prover
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func debugTrace(t *testing.T) []TraceStep { return debugTraceWindow(t, 0, math.MaxUint64) }
+
+func debugTraceWindow(t *testing.T, offset, limit uint64) []TraceStep {
+	db, root := testState(t)
+	statedb, err := state.New(root, db, nil)
+	require.NoError(t, err)
+	debugger := NewDebugProver(0, offset, limit)
+	_, _, err = runtime.Call(testContract, nil, &runtime.Config{State: statedb, EVMConfig: vm.Config{Tracer: debugger}})
+	require.NoError(t, err)
+	return debugger.GetTrace()
+}
+
+func TestDebugTrace(t *testing.T) {
+	states := generateStates(t, NewStateGenerator(0))
+	trace := debugTrace(t)
+	require.Len(t, trace, len(states))
+	for i, step := range trace {
+		require.Equal(t, uint64(i+1), step.Step)
+		require.Equal(t, states[i].VMHash, step.Hash)
+	}
+	require.Nil(t, trace[0].StackTop)
+	// The SLOAD reads slot 0.
+	require.Equal(t, TraceStep{Step: 5, Op: "SLOAD", Pc: 7, Gas: trace[4].Gas, StackTop: uint256.NewInt(0), Hash: trace[4].Hash, Depth: 1}, trace[4])
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTrace(&buf, trace))
+	require.Equal(t, len(trace), bytes.Count(buf.Bytes(), []byte("\n")))
+	read, err := ReadTrace(&buf)
+	require.NoError(t, err)
+	require.Equal(t, trace, read)
+
+	// Traces can be paged through.
+	require.Equal(t, trace[2:5], debugTraceWindow(t, 2, 3))
+	require.Empty(t, debugTraceWindow(t, uint64(len(trace)), 3))
+}
+
+func TestFirstDivergentStep(t *testing.T) {
+	trace := debugTrace(t)
+	_, diverged := FirstDivergentStep(trace, debugTrace(t))
+	require.False(t, diverged)
+
+	other := append([]TraceStep{}, trace...)
+	other[3].Hash = common.Hash{0x01}
+	i, diverged := FirstDivergentStep(trace, other)
+	require.True(t, diverged)
+	require.Equal(t, 3, i)
+
+	i, diverged = FirstDivergentStep(trace, trace[:2])
+	require.True(t, diverged)
+	require.Equal(t, 2, i)
+}
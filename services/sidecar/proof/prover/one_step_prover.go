@@ -18,25 +18,52 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/specularL2/specular/services/sidecar/proof/proof"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
 )
 
-type PlaceHolderProof struct{}
+// OneStepProver captures the one-step proof of a single step of a transaction.
+// Step 0 is the transaction's initiation (from its inter-state, hashed as the state root),
+// and step i > 0 is the execution of the i-th opcode (from the i-th intra-state, see `GenerateStates`).
+//
+// The proof of an opcode step consists of (in order):
+// the VM state, the code, the stack items read, the memory cells accessed (one proof per range, against the memory
+// as expanded by the step, see `expandedMemory`),
+// and, for ops reading accounts or storage, account and storage proofs against the VM state's (intermediate) state root.
+// The proof of the initiation step consists of the account proofs of the sender and recipient.
+type OneStepProver struct {
+	// Config
+	target    common.Hash
+	step      uint64
+	search    bool           // Whether to prove the first step from state `target`, rather than step `step`.
+	statedb   *state.StateDB // Transaction's state, committed at `stateRoot` before executing it (on a throwaway database).
+	stateRoot common.Hash
 
-func (p *PlaceHolderProof) Encode() []byte {
-	return []byte{'p', 'r', 'o', 'o', 'f'}
+	// Context
+	env       *vm.EVM
+	steps     uint64 // Opcodes executed so far.
+	callStack callStack
+	hasher    vmStateHasher
+
+	// Results
+	stateHash common.Hash // Hash of the proven step's start state.
+	proof     *proof.OneStepProof
+	err       error
 }
 
-type OneStepProver struct {
-	// Config
-	target common.Hash
-	step   uint64
+// Returns a prover for step `step`, expected to start from state `target` (if non-zero).
+// Intermediate states are committed to the database of `statedb`, so it should be a throwaway one.
+func NewProver(target common.Hash, step uint64, statedb *state.StateDB, stateRoot common.Hash) *OneStepProver {
+	return &OneStepProver{target: target, step: step, statedb: statedb, stateRoot: stateRoot}
 }
 
-func NewProver(target common.Hash, step uint64) *OneStepProver {
-	return &OneStepProver{target: target, step: step}
+// Returns a prover for the first step starting from state `target`.
+func NewProverAtState(target common.Hash, statedb *state.StateDB, stateRoot common.Hash) *OneStepProver {
+	return &OneStepProver{target: target, search: true, statedb: statedb, stateRoot: stateRoot}
 }
 
 func (l *OneStepProver) CaptureTxStart(gasLimit uint64) {}
@@ -44,15 +71,43 @@ func (l *OneStepProver) CaptureTxStart(gasLimit uint64) {}
 func (l *OneStepProver) CaptureTxEnd(restGas uint64) {}
 
 func (l *OneStepProver) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	l.env = env
+	if l.done() {
+		return
+	}
+	if (l.search && l.target == l.stateRoot) || (!l.search && l.step == 0) {
+		l.captureInitiation(from, to)
+	}
 }
 
 func (l *OneStepProver) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	l.steps++
+	if l.done() {
+		return
+	}
+	l.hasher.step(op)
+	candidate := l.search || l.steps == l.step
+	// Steps entering frames are hashed regardless, as the frames' states commit to them.
+	if !candidate && !entersFrame(op) {
+		return
+	}
+	state := l.hasher.vmState(l.env, uint64(l.statedb.TxIndex()), l.callStack.hash(), pc, gas, scope, rData, depth)
+	hash := state.Hash()
+	if entersFrame(op) {
+		l.callStack.caller = hash
+	}
+	if candidate && (!l.search || hash == l.target) {
+		l.capture(state, op, scope)
+	}
 }
 
 func (l *OneStepProver) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	l.callStack.enter()
 }
 
 func (l *OneStepProver) CaptureExit(output []byte, gasUsed uint64, err error) {
+	l.callStack.exit()
+	l.hasher.invalidate()
 }
 
 func (l *OneStepProver) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
@@ -62,7 +117,153 @@ func (l *OneStepProver) CaptureEnd(output []byte, gasUsed uint64, err error) {
 }
 
 func (l *OneStepProver) GetProof() (*proof.OneStepProof, error) {
-	proof := proof.EmptyProof()
-	proof.AddProof(&PlaceHolderProof{})
-	return proof, nil
+	if l.err != nil {
+		return nil, l.err
+	}
+	if l.proof == nil {
+		if l.search {
+			return nil, fmt.Errorf("state %s not reached", l.target)
+		}
+		return nil, fmt.Errorf("step %d not reached (%d steps executed)", l.step, l.steps)
+	}
+	if !l.search && l.target != (common.Hash{}) && l.stateHash != l.target {
+		return nil, fmt.Errorf("step %d starts from state %s, expected %s", l.step, l.stateHash, l.target)
+	}
+	return l.proof, nil
+}
+
+func (l *OneStepProver) done() bool { return l.proof != nil || l.err != nil }
+
+func (l *OneStepProver) capture(state *proof.VMStateProof, op vm.OpCode, scope *vm.ScopeContext) {
+	var (
+		p        = proof.EmptyProof()
+		stack    = scope.Stack.Data()
+		numItems = stackInputs(op)
+	)
+	p.AddProof(state)
+	p.AddProof(&proof.CodeProof{Code: scope.Contract.Code})
+	// On stack underflow the step fails before accessing memory or state.
+	if numItems > len(stack) {
+		p.AddProof(proof.NewStackProof(stack, len(stack)))
+		l.stateHash, l.proof = state.Hash(), p
+		return
+	}
+	p.AddProof(proof.NewStackProof(stack, numItems))
+	ranges := memoryRanges(op, stack)
+	memory := expandedMemory(scope.Memory.Data(), ranges, state.Gas)
+	for _, r := range ranges {
+		p.AddProof(proof.NewMemoryProof(memory, r[0], r[1]))
+	}
+	i, accessesAccount := accountAccess(op)
+	accessesStorage := op == vm.SLOAD || op == vm.SSTORE
+	if accessesAccount || accessesStorage {
+		statedb, _ := intermediateState(l.env)
+		if l.err = l.commitIntermediate(statedb, state.StateRoot); l.err != nil {
+			return
+		}
+	}
+	if accessesAccount {
+		addr := common.Address(stack[len(stack)-1-i].Bytes20())
+		if l.err = l.addAccountProof(p, state.StateRoot, addr); l.err != nil {
+			return
+		}
+	}
+	if accessesStorage {
+		var (
+			addr = scope.Contract.Address()
+			key  = common.Hash(stack[len(stack)-1].Bytes32())
+		)
+		if l.err = l.addAccountProof(p, state.StateRoot, addr); l.err != nil {
+			return
+		}
+		if l.err = l.addStorageProof(p, state.StateRoot, addr, key); l.err != nil {
+			return
+		}
+	}
+	l.stateHash, l.proof = state.Hash(), p
+}
+
+// Commits the intermediate state of the step (at `root`), so that accounts and storage can be proven against it.
+// This writes to the state's database, which mustn't be the node's (see `NewProver`).
+func (l *OneStepProver) commitIntermediate(statedb *state.StateDB, root common.Hash) error {
+	var (
+		number = l.env.Context.BlockNumber
+		eip158 = l.env.ChainConfig().IsEIP158(number)
+	)
+	committed, err := statedb.Commit(number.Uint64(), eip158)
+	if err != nil {
+		return fmt.Errorf("failed to commit intermediate state: %w", err)
+	}
+	if committed != root {
+		return fmt.Errorf("committed intermediate state %s, expected %s", committed, root)
+	}
+	return nil
+}
+
+func (l *OneStepProver) captureInitiation(from, to common.Address) {
+	p := proof.EmptyProof()
+	for _, addr := range []common.Address{from, to} {
+		if l.err = l.addAccountProof(p, l.stateRoot, addr); l.err != nil {
+			return
+		}
+	}
+	l.stateHash, l.proof = l.stateRoot, p
+}
+
+func (l *OneStepProver) addAccountProof(p *proof.OneStepProof, root common.Hash, addr common.Address) error {
+	tr, err := l.statedb.Database().OpenTrie(root)
+	if err != nil {
+		return fmt.Errorf("failed to open state trie: %w", err)
+	}
+	var nodes proofList
+	if err := tr.Prove(crypto.Keccak256(addr.Bytes()), &nodes); err != nil {
+		return fmt.Errorf("failed to prove account %s: %w", addr, err)
+	}
+	p.AddProof(&proof.AccountProof{Address: addr, Nodes: nodes})
+	return nil
+}
+
+// Proves the slot's value (as read from the proven trie itself) against the storage root of the account at `root`.
+func (l *OneStepProver) addStorageProof(p *proof.OneStepProof, root common.Hash, addr common.Address, key common.Hash) error {
+	tr, err := l.statedb.Database().OpenTrie(root)
+	if err != nil {
+		return fmt.Errorf("failed to open state trie: %w", err)
+	}
+	account, err := tr.GetAccount(addr)
+	if err != nil {
+		return fmt.Errorf("failed to get account %s: %w", addr, err)
+	}
+	var (
+		nodes proofList
+		value common.Hash
+	)
+	// Absent accounts have empty storage (proven by the account proof).
+	if account != nil {
+		storageTr, err := l.statedb.Database().OpenStorageTrie(root, addr, account.Root)
+		if err != nil {
+			return fmt.Errorf("failed to open storage trie: %w", err)
+		}
+		enc, err := storageTr.GetStorage(addr, key.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to get storage slot %s: %w", key, err)
+		}
+		value = common.BytesToHash(enc)
+		if err := storageTr.Prove(crypto.Keccak256(key.Bytes()), &nodes); err != nil {
+			return fmt.Errorf("failed to prove storage slot %s: %w", key, err)
+		}
+	}
+	p.AddProof(&proof.StorageProof{Key: key, Value: value, Nodes: nodes})
+	return nil
+}
+
+// Collects trie nodes, in order, as written by `Prove`.
+type proofList [][]byte
+
+func (n *proofList) Put(key []byte, value []byte) error {
+	*n = append(*n, value)
+	return nil
+}
+
+func (n *proofList) Delete(key []byte) error {
+	panic("not supported")
 }
@@ -0,0 +1,124 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prover
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/services/sidecar/proof/proof"
+)
+
+var testContract = common.HexToAddress("0xc0")
+
+// Stores 0x2a in slot 0, then loads it.
+var testCode = []byte{
+	byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+	byte(vm.PUSH1), 0x00, byte(vm.SLOAD),
+	byte(vm.STOP),
+}
+
+// Returns a committed state with the test contract, whose slot 0 holds 7.
+func testState(t *testing.T) (state.Database, common.Hash) {
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	statedb, err := state.New(types.EmptyRootHash, db, nil)
+	require.NoError(t, err)
+	statedb.SetCode(testContract, testCode)
+	statedb.SetState(testContract, common.Hash{}, common.HexToHash("0x07"))
+	root, err := statedb.Commit(0, true)
+	require.NoError(t, err)
+	return db, root
+}
+
+// Verifies a merkle-patricia proof, returning the proven value.
+func verifyNodes(t *testing.T, root common.Hash, key []byte, nodes [][]byte) []byte {
+	proofDb := memorydb.New()
+	for _, node := range nodes {
+		require.NoError(t, proofDb.Put(crypto.Keccak256(node), node))
+	}
+	value, err := trie.VerifyProof(root, crypto.Keccak256(key), proofDb)
+	require.NoError(t, err)
+	return value
+}
+
+func proveStep(t *testing.T, newProver func(statedb *state.StateDB, root common.Hash) *OneStepProver) *proof.OneStepProof {
+	db, root := testState(t)
+	statedb, err := state.New(root, db, nil)
+	require.NoError(t, err)
+	prover := newProver(statedb, root)
+	_, _, err = runtime.Call(testContract, nil, &runtime.Config{State: statedb, EVMConfig: vm.Config{Tracer: prover}})
+	require.NoError(t, err)
+	p, err := prover.GetProof()
+	require.NoError(t, err)
+	return p
+}
+
+func TestProveStorageStep(t *testing.T) {
+	// Step 5 is the SLOAD.
+	p := proveStep(t, func(statedb *state.StateDB, root common.Hash) *OneStepProver {
+		return NewProver(common.Hash{}, 5, statedb, root)
+	})
+	require.Len(t, p.Proofs, 5)
+
+	vmState := p.Proofs[0].(*proof.VMStateProof)
+	require.Equal(t, uint64(7), vmState.Pc)
+	require.Equal(t, uint64(1), vmState.StackSize)
+	require.Equal(t, crypto.Keccak256Hash(testCode), vmState.CodeHash)
+	require.Equal(t, testCode, p.Proofs[1].(*proof.CodeProof).Code)
+	require.Len(t, p.Proofs[2].(*proof.StackProof).Items, 1)
+
+	// The account and slot are proven against the state as of the step (i.e. after the SSTORE), rather than the
+	// state committed before the transaction.
+	_, txStartRoot := testState(t)
+	require.NotEqual(t, txStartRoot, vmState.StateRoot)
+	accountProof := p.Proofs[3].(*proof.AccountProof)
+	require.Equal(t, testContract, accountProof.Address)
+	var account types.StateAccount
+	require.NoError(t, rlp.DecodeBytes(verifyNodes(t, vmState.StateRoot, testContract.Bytes(), accountProof.Nodes), &account))
+
+	storageProof := p.Proofs[4].(*proof.StorageProof)
+	var proven []byte
+	require.NoError(t, rlp.DecodeBytes(verifyNodes(t, account.Root, storageProof.Key.Bytes(), storageProof.Nodes), &proven))
+	require.Equal(t, []byte{0x2a}, proven)
+	require.Equal(t, common.HexToHash("0x2a"), storageProof.Value)
+
+	// The same step is found from its start state.
+	found := proveStep(t, func(statedb *state.StateDB, root common.Hash) *OneStepProver {
+		return NewProverAtState(vmState.Hash(), statedb, root)
+	})
+	require.Equal(t, p.Encode(), found.Encode())
+}
+
+func TestProveUnreachedStep(t *testing.T) {
+	db, root := testState(t)
+	statedb, err := state.New(root, db, nil)
+	require.NoError(t, err)
+	prover := NewProver(common.Hash{}, 100, statedb, root)
+	_, _, err = runtime.Call(testContract, nil, &runtime.Config{State: statedb, EVMConfig: vm.Config{Tracer: prover}})
+	require.NoError(t, err)
+	_, err = prover.GetProof()
+	require.Error(t, err)
+}
@@ -0,0 +1,163 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prover
+
+import (
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Largest memory size whose expansion cost doesn't overflow (see geth's `memoryGasCost`).
+const maxMemorySize = 0x1FFFFFFFE0
+
+// Returns the number of stack items read by `op` (the jump table's is unexported).
+func stackInputs(op vm.OpCode) int {
+	switch {
+	case op.IsPush():
+		return 0
+	case op >= vm.DUP1 && op <= vm.DUP16:
+		return int(op-vm.DUP1) + 1
+	case op >= vm.SWAP1 && op <= vm.SWAP16:
+		return int(op-vm.SWAP1) + 2
+	case op >= vm.LOG0 && op <= vm.LOG4:
+		return int(op-vm.LOG0) + 2
+	}
+	switch op {
+	case vm.ISZERO, vm.NOT, vm.BALANCE, vm.CALLDATALOAD, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.BLOCKHASH, vm.BLOBHASH,
+		vm.POP, vm.MLOAD, vm.SLOAD, vm.JUMP, vm.TLOAD, vm.SELFDESTRUCT:
+		return 1
+	case vm.ADD, vm.MUL, vm.SUB, vm.DIV, vm.SDIV, vm.MOD, vm.SMOD, vm.EXP, vm.SIGNEXTEND,
+		vm.LT, vm.GT, vm.SLT, vm.SGT, vm.EQ, vm.AND, vm.OR, vm.XOR, vm.BYTE, vm.SHL, vm.SHR, vm.SAR,
+		vm.KECCAK256, vm.MSTORE, vm.MSTORE8, vm.SSTORE, vm.JUMPI, vm.TSTORE, vm.RETURN, vm.REVERT:
+		return 2
+	case vm.ADDMOD, vm.MULMOD, vm.CALLDATACOPY, vm.CODECOPY, vm.RETURNDATACOPY, vm.MCOPY, vm.CREATE:
+		return 3
+	case vm.EXTCODECOPY, vm.CREATE2:
+		return 4
+	case vm.DELEGATECALL, vm.STATICCALL:
+		return 6
+	case vm.CALL, vm.CALLCODE:
+		return 7
+	default:
+		return 0
+	}
+}
+
+// A memory range accessed by an op, as indices of its (offset, size) stack arguments (0 being the top of the stack).
+type memoryArgs struct{ offset, size int }
+
+// Returns the memory ranges accessed (read or written) by `op`.
+func memoryAccesses(op vm.OpCode) []memoryArgs {
+	switch op {
+	case vm.KECCAK256, vm.RETURN, vm.REVERT, vm.LOG0, vm.LOG1, vm.LOG2, vm.LOG3, vm.LOG4:
+		return []memoryArgs{{0, 1}}
+	case vm.CALLDATACOPY, vm.CODECOPY, vm.RETURNDATACOPY:
+		return []memoryArgs{{0, 2}}
+	case vm.EXTCODECOPY:
+		return []memoryArgs{{1, 3}}
+	case vm.MCOPY:
+		return []memoryArgs{{1, 2}, {0, 2}}
+	case vm.CREATE, vm.CREATE2:
+		return []memoryArgs{{1, 2}}
+	case vm.CALL, vm.CALLCODE:
+		return []memoryArgs{{3, 4}, {5, 6}}
+	case vm.DELEGATECALL, vm.STATICCALL:
+		return []memoryArgs{{2, 3}, {4, 5}}
+	default:
+		return nil
+	}
+}
+
+// Returns the (offset, size) of the memory ranges accessed by `op`, given the stack.
+// Ranges that don't fit in a uint64 can't be accessed (the op runs out of gas), and are returned empty.
+func memoryRanges(op vm.OpCode, stack []uint256.Int) [][2]uint64 {
+	back := func(i int) *uint256.Int { return &stack[len(stack)-1-i] }
+	toRange := func(offset, size *uint256.Int) [2]uint64 {
+		if !offset.IsUint64() || !size.IsUint64() {
+			return [2]uint64{}
+		}
+		return [2]uint64{offset.Uint64(), size.Uint64()}
+	}
+	switch op {
+	case vm.MLOAD, vm.MSTORE:
+		return [][2]uint64{toRange(back(0), uint256.NewInt(32))}
+	case vm.MSTORE8:
+		return [][2]uint64{toRange(back(0), uint256.NewInt(1))}
+	}
+	var ranges [][2]uint64
+	for _, args := range memoryAccesses(op) {
+		ranges = append(ranges, toRange(back(args.offset), back(args.size)))
+	}
+	return ranges
+}
+
+// Returns the memory as expanded (zero-padded to whole cells) to cover the given ranges, so that cells written
+// beyond the current memory can be proven (as zero) too.
+// The memory isn't expanded if the step can't afford it with `gas`, as it then runs out of gas instead.
+func expandedMemory(memory []byte, ranges [][2]uint64, gas uint64) []byte {
+	size := uint64(len(memory))
+	for _, r := range ranges {
+		if r[1] == 0 {
+			continue
+		}
+		end := r[0] + r[1]
+		if end < r[0] || end > maxMemorySize {
+			return memory
+		}
+		if end = (end + 31) / 32 * 32; end > size {
+			size = end
+		}
+	}
+	if size == uint64(len(memory)) || memoryGasCost(size)-memoryGasCost(uint64(len(memory))) > gas {
+		return memory
+	}
+	expanded := make([]byte, size)
+	copy(expanded, memory)
+	return expanded
+}
+
+// Returns the total cost of a memory of the given size (at most `maxMemorySize`).
+func memoryGasCost(size uint64) uint64 {
+	words := (size + 31) / 32
+	return words*params.MemoryGas + words*words/params.QuadCoeffDiv
+}
+
+// Returns whether `op` may enter a new call frame (as traced by `CaptureEnter`).
+func entersFrame(op vm.OpCode) bool {
+	switch op {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL, vm.CREATE, vm.CREATE2, vm.SELFDESTRUCT:
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns true iff `op` may change the world state (i.e. its intermediate root), directly or in the frame it enters.
+func changesState(op vm.OpCode) bool {
+	return op == vm.SSTORE || entersFrame(op)
+}
+
+// Returns the index of the stack argument of `op` that's the address of an account it reads, if any.
+func accountAccess(op vm.OpCode) (int, bool) {
+	switch op {
+	case vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODECOPY, vm.EXTCODEHASH, vm.SELFDESTRUCT:
+		return 0, true
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
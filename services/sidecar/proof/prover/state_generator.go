@@ -24,15 +24,41 @@ import (
 type GeneratedState struct {
 	VMHash common.Hash
 	Gas    uint64
+	Step   uint64 // 1-indexed, as in `GenerateStates`.
 }
 
+// StateGenerator hashes the intra-states of a transaction (the VM state before each step, see `proof.VMStateProof`).
 type StateGenerator struct {
+	// Config
+	txIdx    uint64
+	steps    map[uint64]bool // Steps to generate (all if nil).
+	lastStep uint64          // Last step in `steps`.
+
+	// Context
+	env       *vm.EVM
+	callStack callStack
+	hasher    vmStateHasher
+
 	// Global
-	states []GeneratedState
+	numSteps uint64
+	states   []GeneratedState
 }
 
-func NewStateGenerator() *StateGenerator {
-	return &StateGenerator{}
+func NewStateGenerator(txIdx uint64) *StateGenerator {
+	return &StateGenerator{txIdx: txIdx}
+}
+
+// Returns a generator of only the given steps, which otherwise only counts steps
+// (only hashing those entering call frames before the last given step).
+func NewStateGeneratorAt(txIdx uint64, steps []uint64) *StateGenerator {
+	l := &StateGenerator{txIdx: txIdx, steps: map[uint64]bool{}}
+	for _, step := range steps {
+		l.steps[step] = true
+		if step > l.lastStep {
+			l.lastStep = step
+		}
+	}
+	return l
 }
 
 func (l *StateGenerator) CaptureTxStart(gasLimit uint64) {}
@@ -40,16 +66,34 @@ func (l *StateGenerator) CaptureTxStart(gasLimit uint64) {}
 func (l *StateGenerator) CaptureTxEnd(restGas uint64) {}
 
 func (l *StateGenerator) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	l.env = env
 }
 
 func (l *StateGenerator) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
-	l.states = append(l.states, GeneratedState{common.Hash{}, gas})
+	l.numSteps++
+	l.hasher.step(op)
+	generate := l.steps == nil || l.steps[l.numSteps]
+	// Steps entering frames are hashed regardless, as the frames' states commit to them,
+	// unless no later step is generated.
+	if !generate && !(entersFrame(op) && (l.steps == nil || l.numSteps < l.lastStep)) {
+		return
+	}
+	hash := l.hasher.vmState(l.env, l.txIdx, l.callStack.hash(), pc, gas, scope, rData, depth).Hash()
+	if entersFrame(op) {
+		l.callStack.caller = hash
+	}
+	if generate {
+		l.states = append(l.states, GeneratedState{hash, gas, l.numSteps})
+	}
 }
 
 func (l *StateGenerator) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	l.callStack.enter()
 }
 
 func (l *StateGenerator) CaptureExit(output []byte, gasUsed uint64, err error) {
+	l.callStack.exit()
+	l.hasher.invalidate()
 }
 
 func (l *StateGenerator) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
@@ -58,6 +102,9 @@ func (l *StateGenerator) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64,
 func (l *StateGenerator) CaptureEnd(output []byte, gasUsed uint64, err error) {
 }
 
+// Returns the number of steps executed.
+func (l *StateGenerator) NumSteps() uint64 { return l.numSteps }
+
 func (l *StateGenerator) GetGeneratedStates() ([]GeneratedState, error) {
 	return l.states, nil
 }
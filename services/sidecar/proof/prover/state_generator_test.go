@@ -0,0 +1,143 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prover
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/services/sidecar/proof/proof"
+)
+
+func TestGeneratedStatesMatchProofs(t *testing.T) {
+	db, root := testState(t)
+	statedb, err := state.New(root, db, nil)
+	require.NoError(t, err)
+	generator := NewStateGenerator(0)
+	_, _, err = runtime.Call(testContract, nil, &runtime.Config{State: statedb, EVMConfig: vm.Config{Tracer: generator}})
+	require.NoError(t, err)
+	states, err := generator.GetGeneratedStates()
+	require.NoError(t, err)
+	require.Len(t, states, 6)
+
+	seen := map[common.Hash]bool{}
+	for i, s := range states {
+		require.False(t, seen[s.VMHash], "step %d", i+1)
+		seen[s.VMHash] = true
+		// Each generated state is the start state of the corresponding step's proof.
+		step := uint64(i + 1)
+		p := proveStep(t, func(statedb *state.StateDB, root common.Hash) *OneStepProver {
+			return NewProver(s.VMHash, step, statedb, root)
+		})
+		require.Equal(t, s.VMHash, p.Proofs[0].(*proof.VMStateProof).Hash())
+	}
+}
+
+func generateStates(t *testing.T, generator *StateGenerator) []GeneratedState {
+	db, root := testState(t)
+	statedb, err := state.New(root, db, nil)
+	require.NoError(t, err)
+	_, _, err = runtime.Call(testContract, nil, &runtime.Config{State: statedb, EVMConfig: vm.Config{Tracer: generator}})
+	require.NoError(t, err)
+	states, err := generator.GetGeneratedStates()
+	require.NoError(t, err)
+	return states
+}
+
+func TestGenerateSelectedStates(t *testing.T) {
+	all := generateStates(t, NewStateGenerator(0))
+
+	selected := NewStateGeneratorAt(0, []uint64{2, 5, 100})
+	states := generateStates(t, selected)
+	require.Equal(t, []GeneratedState{all[1], all[4]}, states)
+	require.Equal(t, uint64(len(all)), selected.NumSteps())
+
+	// Without steps, only counts them.
+	counter := NewStateGeneratorAt(0, nil)
+	require.Empty(t, generateStates(t, counter))
+	require.Equal(t, uint64(len(all)), counter.NumSteps())
+}
+
+var testCallee = common.HexToAddress("0xc1")
+
+// Calls the callee (which pushes and pops a value) with no input or value, then returns.
+var testCallerCode = []byte{
+	byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00,
+	byte(vm.PUSH1), byte(testCallee[common.AddressLength-1]), byte(vm.GAS), byte(vm.CALL),
+	byte(vm.POP), byte(vm.STOP),
+}
+
+// Traces a call of the test caller (at `testContract`), with `newTracer` getting its state before execution.
+func traceNestedCall(t *testing.T, newTracer func(statedb *state.StateDB, root common.Hash) vm.EVMLogger) {
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	statedb, err := state.New(types.EmptyRootHash, db, nil)
+	require.NoError(t, err)
+	statedb.SetCode(testContract, testCallerCode)
+	statedb.SetCode(testCallee, []byte{byte(vm.PUSH1), 0x01, byte(vm.POP), byte(vm.STOP)})
+	root, err := statedb.Commit(0, true)
+	require.NoError(t, err)
+	statedb, err = state.New(root, db, nil)
+	require.NoError(t, err)
+	tracer := newTracer(statedb, root)
+	_, _, err = runtime.Call(testContract, nil, &runtime.Config{State: statedb, EVMConfig: vm.Config{Tracer: tracer}})
+	require.NoError(t, err)
+}
+
+func TestGeneratedStatesCommitToCallStack(t *testing.T) {
+	var (
+		generator = NewStateGenerator(0)
+		selected  = NewStateGeneratorAt(0, []uint64{9})
+	)
+	traceNestedCall(t, func(*state.StateDB, common.Hash) vm.EVMLogger { return generator })
+	traceNestedCall(t, func(*state.StateDB, common.Hash) vm.EVMLogger { return selected })
+	states, err := generator.GetGeneratedStates()
+	require.NoError(t, err)
+	// 8 steps up to the CALL, 3 in the callee, then 2 after it.
+	require.Len(t, states, 13)
+	// The callee's states are the same when only they are generated.
+	selectedStates, err := selected.GetGeneratedStates()
+	require.NoError(t, err)
+	require.Equal(t, []GeneratedState{states[8]}, selectedStates)
+	// The CALL step isn't hashed if no later step is generated.
+	counter := NewStateGeneratorAt(0, []uint64{2})
+	traceNestedCall(t, func(*state.StateDB, common.Hash) vm.EVMLogger { return counter })
+	require.Equal(t, common.Hash{}, counter.callStack.caller)
+	require.Equal(t, uint64(13), counter.NumSteps())
+
+	prove := func(step uint64) *proof.VMStateProof {
+		var prover *OneStepProver
+		traceNestedCall(t, func(statedb *state.StateDB, root common.Hash) vm.EVMLogger {
+			prover = NewProver(states[step-1].VMHash, step, statedb, root)
+			return prover
+		})
+		p, err := prover.GetProof()
+		require.NoError(t, err)
+		return p.Proofs[0].(*proof.VMStateProof)
+	}
+	// The callee's first step commits to the caller's CALL step.
+	callee := prove(9)
+	require.Equal(t, uint64(2), callee.Depth)
+	require.Equal(t, crypto.Keccak256Hash(common.Hash{}.Bytes(), states[7].VMHash.Bytes()), callee.CallStackHash)
+	// Back in the caller, the call stack is empty again.
+	require.Equal(t, common.Hash{}, prove(12).CallStackHash)
+}
@@ -0,0 +1,140 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prover
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/specularL2/specular/services/sidecar/proof/proof"
+)
+
+// Returns a copy of the world state before executing the current step (i.e. with the transaction's changes so far),
+// and its root. The root isn't committed to the database, and the copy is left to the caller (e.g. to commit it).
+func intermediateState(env *vm.EVM) (*state.StateDB, common.Hash) {
+	statedb := env.StateDB.(*state.StateDB).Copy()
+	return statedb, statedb.IntermediateRoot(env.ChainConfig().IsEIP158(env.Context.BlockNumber))
+}
+
+// callStack tracks the commitment to the call frames below the current one, for `VMStateProof.CallStackHash`.
+// Each frame is committed to by the VM state hash of its step that entered the next frame (e.g. its CALL),
+// chained onto the commitment to the frames below it.
+type callStack struct {
+	hashes []common.Hash // Commitment to the stack, as of each entered frame.
+	caller common.Hash   // VM state hash of the last step that may enter a frame (see `entersFrame`).
+}
+
+// Returns the commitment to the frames below the current one (zero in the outermost frame).
+func (s *callStack) hash() common.Hash {
+	if len(s.hashes) == 0 {
+		return common.Hash{}
+	}
+	return s.hashes[len(s.hashes)-1]
+}
+
+func (s *callStack) enter() {
+	s.hashes = append(s.hashes, crypto.Keccak256Hash(s.hash().Bytes(), s.caller.Bytes()))
+}
+
+func (s *callStack) exit() {
+	s.hashes = s.hashes[:len(s.hashes)-1]
+}
+
+// vmStateHasher computes the VM states of successive steps of a transaction (see `newVMState`), reusing work across them:
+// the world state's intermediate root is only recomputed after steps that may have changed it,
+// and the stack and memory commitments of each frame depth are updated incrementally.
+// `step` must be called on every step, whether its state is computed or not.
+type vmStateHasher struct {
+	stateRoot   common.Hash
+	rootValid   bool // Whether `stateRoot` is the current intermediate root.
+	lastChanges bool // Whether the last step may change the world state.
+	stacks      []proof.StackHasher
+	memories    []proof.MemoryHasher
+}
+
+func (h *vmStateHasher) step(op vm.OpCode) {
+	if h.lastChanges {
+		h.rootValid = false
+	}
+	h.lastChanges = changesState(op)
+}
+
+// Invalidates the intermediate root, e.g. as a frame exiting may revert its changes.
+func (h *vmStateHasher) invalidate() { h.rootValid = false }
+
+// Returns the VM state of the current step (see `newVMState`).
+func (h *vmStateHasher) vmState(
+	env *vm.EVM,
+	txIdx uint64,
+	callStackHash common.Hash,
+	pc uint64,
+	gas uint64,
+	scope *vm.ScopeContext,
+	rData []byte,
+	depth int,
+) *proof.VMStateProof {
+	if !h.rootValid {
+		_, h.stateRoot = intermediateState(env)
+		h.rootValid = true
+	}
+	for len(h.stacks) < depth {
+		h.stacks = append(h.stacks, proof.StackHasher{})
+		h.memories = append(h.memories, proof.MemoryHasher{})
+	}
+	var (
+		stackHash  = h.stacks[depth-1].Hash(scope.Stack.Data())
+		memoryRoot = h.memories[depth-1].Root(scope.Memory.Data())
+	)
+	return newVMState(env, txIdx, h.stateRoot, callStackHash, pc, gas, scope, rData, depth, stackHash, memoryRoot)
+}
+
+// Returns the VM state before executing the current step of transaction `txIdx`,
+// with `stateRoot` being the world state's intermediate root at that step (see `intermediateState`),
+// `callStackHash` the commitment to the frames below the current one (see `callStack`),
+// and `stackHash` and `memoryRoot` the commitments to the current frame's stack and memory.
+// Its hash commits to the step in bisections, and must match between `StateGenerator` and `OneStepProver`.
+func newVMState(
+	env *vm.EVM,
+	txIdx uint64,
+	stateRoot common.Hash,
+	callStackHash common.Hash,
+	pc uint64,
+	gas uint64,
+	scope *vm.ScopeContext,
+	rData []byte,
+	depth int,
+	stackHash common.Hash,
+	memoryRoot common.Hash,
+) *proof.VMStateProof {
+	return &proof.VMStateProof{
+		BlockNumber:    env.Context.BlockNumber.Uint64(),
+		TransactionIdx: txIdx,
+		Depth:          uint64(depth),
+		CallStackHash:  callStackHash,
+		Gas:            gas,
+		Refund:         env.StateDB.GetRefund(),
+		Pc:             pc,
+		Contract:       scope.Contract.Address(),
+		CodeHash:       crypto.Keccak256Hash(scope.Contract.Code),
+		StackSize:      uint64(len(scope.Stack.Data())),
+		StackHash:      stackHash,
+		MemorySize:     uint64(scope.Memory.Len()),
+		MemoryRoot:     memoryRoot,
+		ReturnDataHash: crypto.Keccak256Hash(rData),
+		StateRoot:      stateRoot,
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/specularL2/specular/services/sidecar/proof/prover"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+// IterateStates streams the execution states across blocks [startNum, endNum) (see `GenerateStates`) to `fn`,
+// holding only the states of a single transaction in memory at a time.
+func IterateStates(
+	backend Backend,
+	ctx context.Context,
+	startNum uint64,
+	endNum uint64,
+	config *ProverConfig,
+	fn func(*ExecutionState) error,
+) error {
+	newTracer := func(txIdx uint64) *prover.StateGenerator {
+		return prover.NewStateGenerator(txIdx)
+	}
+	visit := func(block *types.Block, txIdx uint64, root common.Hash, generator *prover.StateGenerator) error {
+		// Push inter-state hash
+		if err := fn(&ExecutionState{VMHash: root, Block: block, TransactionIdx: txIdx, StepIdx: 0}); err != nil {
+			return err
+		}
+		generatedStates, err := generator.GetGeneratedStates()
+		if err != nil {
+			return fmt.Errorf("tracing failed: %w", err)
+		}
+		for _, s := range generatedStates {
+			state := &ExecutionState{VMHash: s.VMHash, Block: block, TransactionIdx: txIdx, StepIdx: s.Step}
+			if err := fn(state); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	block, err := walkTransactions(backend, ctx, startNum, endNum, config, newTracer, visit)
+	if err != nil {
+		return err
+	}
+	return fn(endState(block))
+}
+
+// StateIndex locates the execution states across blocks [startNum, endNum) (indexed as by `GenerateStates`),
+// so that any of them can be regenerated by re-executing a single transaction.
+// Only per-transaction step counts are kept in memory.
+type StateIndex struct {
+	startNum  uint64
+	endNum    uint64
+	endBlock  *types.Block
+	txs       []indexedTx
+	numStates uint64
+}
+
+type indexedTx struct {
+	block    *types.Block
+	txIdx    uint64
+	offset   uint64 // Index of the transaction's inter-state.
+	numSteps uint64 // Number of intra-states.
+}
+
+// NewStateIndex executes blocks [startNum, endNum), counting the steps of each transaction.
+func NewStateIndex(
+	backend Backend,
+	ctx context.Context,
+	startNum uint64,
+	endNum uint64,
+	config *ProverConfig,
+) (*StateIndex, error) {
+	index := &StateIndex{startNum: startNum, endNum: endNum}
+	newTracer := func(txIdx uint64) *prover.StateGenerator {
+		return prover.NewStateGeneratorAt(txIdx, nil)
+	}
+	visit := func(block *types.Block, txIdx uint64, _ common.Hash, counter *prover.StateGenerator) error {
+		index.txs = append(index.txs, indexedTx{
+			block:    block,
+			txIdx:    txIdx,
+			offset:   index.numStates,
+			numSteps: counter.NumSteps(),
+		})
+		index.numStates += 1 + counter.NumSteps()
+		return nil
+	}
+	block, err := walkTransactions(backend, ctx, startNum, endNum, config, newTracer, visit)
+	if err != nil {
+		return nil, err
+	}
+	index.endBlock = block
+	index.numStates++ // End state.
+	return index, nil
+}
+
+// Returns the number of states, including the end state.
+func (i *StateIndex) NumStates() uint64 { return i.numStates }
+
+// Returns true if the indexed blocks are still canonical.
+func (i *StateIndex) IsCanonical(backend Backend, ctx context.Context) (bool, error) {
+	header, err := backend.HeaderByNumber(ctx, rpc.BlockNumber(i.endNum-1))
+	if err != nil {
+		return false, err
+	}
+	return header != nil && header.Hash() == i.endBlock.Hash(), nil
+}
+
+// StatesAt regenerates the states at the given indices, re-executing only the transactions containing them
+// (from the state at the start of each, see `stateAtTransaction`).
+func (i *StateIndex) StatesAt(
+	backend Backend,
+	ctx context.Context,
+	indices []uint64,
+	config *ProverConfig,
+) ([]*ExecutionState, error) {
+	var (
+		states = make([]*ExecutionState, len(indices))
+		// Requested steps, by transaction position in the index.
+		steps = map[int][]uint64{}
+	)
+	for _, idx := range indices {
+		if idx >= i.numStates {
+			return nil, fmt.Errorf("state %d out of range (%d states)", idx, i.numStates)
+		}
+		if idx == i.numStates-1 {
+			continue
+		}
+		pos := sort.Search(len(i.txs), func(pos int) bool { return i.txs[pos].offset > idx }) - 1
+		steps[pos] = append(steps[pos], idx-i.txs[pos].offset)
+	}
+	generated := map[uint64]*ExecutionState{i.numStates - 1: endState(i.endBlock)}
+	positions := make([]int, 0, len(steps))
+	for pos := range steps {
+		positions = append(positions, pos)
+	}
+	sort.Ints(positions)
+	for _, pos := range positions {
+		tx := i.txs[pos]
+		txStates, err := statesOfTransaction(backend, ctx, tx.block, tx.txIdx, steps[pos], config)
+		if err != nil {
+			return nil, err
+		}
+		for _, state := range txStates {
+			generated[tx.offset+state.StepIdx] = state
+		}
+	}
+	for j, idx := range indices {
+		states[j] = generated[idx]
+	}
+	return states, nil
+}
+
+// Returns the states at the given steps of a transaction (0 being its inter-state), in no particular order.
+func statesOfTransaction(
+	backend Backend,
+	ctx context.Context,
+	block *types.Block,
+	txIdx uint64,
+	steps []uint64,
+	config *ProverConfig,
+) ([]*ExecutionState, error) {
+	msg, vmctx, statedb, root, err := stateAtTransaction(backend, ctx, block, int(txIdx), config)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		states     []*ExecutionState
+		intraSteps []uint64
+	)
+	for _, step := range steps {
+		if step == 0 {
+			states = append(states, &ExecutionState{VMHash: root, Block: block, TransactionIdx: txIdx, StepIdx: 0})
+		} else {
+			intraSteps = append(intraSteps, step)
+		}
+	}
+	if len(intraSteps) == 0 {
+		return states, nil
+	}
+	generator := prover.NewStateGeneratorAt(txIdx, intraSteps)
+	if err := traceTransaction(backend, msg, vmctx, statedb, generator); err != nil {
+		return nil, err
+	}
+	generatedStates, err := generator.GetGeneratedStates()
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+	for _, s := range generatedStates {
+		states = append(states, &ExecutionState{VMHash: s.VMHash, Block: block, TransactionIdx: txIdx, StepIdx: s.Step})
+	}
+	return states, nil
+}
+
+// Returns the state after executing `block`.
+func endState(block *types.Block) *ExecutionState {
+	return &ExecutionState{
+		VMHash:         block.Root(),
+		Block:          block,
+		TransactionIdx: uint64(len(block.Transactions())),
+		StepIdx:        0,
+	}
+}
+
+// Executes the transactions of blocks [startNum, endNum) in order, each traced by a new tracer,
+// calling `visit` after each (with the state root before it). Returns the last block.
+func walkTransactions(
+	backend Backend,
+	ctx context.Context,
+	startNum uint64,
+	endNum uint64,
+	config *ProverConfig,
+	newTracer func(txIdx uint64) *prover.StateGenerator,
+	visit func(block *types.Block, txIdx uint64, root common.Hash, tracer *prover.StateGenerator) error,
+) (*types.Block, error) {
+	parent, err := backend.BlockByNumber(ctx, rpc.BlockNumber(startNum-1))
+	if err != nil {
+		return nil, err
+	}
+	reexec := defaultProveReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, _, err := backend.StateAtBlock(ctx, parent, reexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var block *types.Block
+	for num := startNum; num < endNum; num++ {
+		block, err = backend.BlockByNumber(ctx, rpc.BlockNumber(num))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", num)
+		}
+		var (
+			signer   = types.MakeSigner(backend.ChainConfig(), block.Number(), block.Time())
+			blockCtx = core.NewEVMBlockContext(block.Header(), createChainContext(backend, ctx), nil)
+		)
+		// Trace all the transactions contained within
+		for i, tx := range block.Transactions() {
+			root := statedb.IntermediateRoot(backend.ChainConfig().IsEIP158(block.Number()))
+			msg, _ := core.TransactionToMessage(tx, signer, block.BaseFee())
+			tracer := newTracer(uint64(i))
+			statedb.SetTxContext(tx.Hash(), i)
+			if err := traceTransaction(backend, msg, blockCtx, statedb, tracer); err != nil {
+				return nil, err
+			}
+			if err := visit(block, uint64(i), root, tracer); err != nil {
+				return nil, err
+			}
+		}
+		// Get next statedb if we are not at the last block
+		if num < endNum-1 {
+			statedb, _, err = backend.StateAtBlock(ctx, block, reexec, statedb, true, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return block, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Returns a throwaway state database layered over `base`: states (e.g. intermediate ones, to be proven) can be
+// committed to it without writing to `base`, while reading anything already in `base`.
+// Assumes `base` uses the hash-based trie scheme.
+func newThrowawayDatabase(base state.Database) state.Database {
+	return state.NewDatabase(&overlayDB{rawdb.NewMemoryDatabase(), base})
+}
+
+// overlayDB is an in-memory database, falling back to a state database for reads.
+// Trie nodes (keyed by hash) are read through its trie database, and anything else from its disk database.
+type overlayDB struct {
+	ethdb.Database
+	base state.Database
+}
+
+func (db *overlayDB) Has(key []byte) (bool, error) {
+	if has, err := db.Database.Has(key); err != nil || has {
+		return has, err
+	}
+	if len(key) == common.HashLength {
+		if _, err := db.base.TrieDB().Node(common.BytesToHash(key)); err == nil {
+			return true, nil
+		}
+	}
+	return db.base.DiskDB().Has(key)
+}
+
+func (db *overlayDB) Get(key []byte) ([]byte, error) {
+	if value, err := db.Database.Get(key); err == nil {
+		return value, nil
+	}
+	if len(key) == common.HashLength {
+		if value, err := db.base.TrieDB().Node(common.BytesToHash(key)); err == nil {
+			return value, nil
+		}
+	}
+	return db.base.DiskDB().Get(key)
+}
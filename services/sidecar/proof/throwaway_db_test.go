@@ -0,0 +1,55 @@
+// Copyright 2022, Specular contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proof
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrowawayDatabaseDoesntWriteToBase(t *testing.T) {
+	var (
+		addr = common.HexToAddress("0x01")
+		code = []byte{0x60, 0x00}
+		base = state.NewDatabase(rawdb.NewMemoryDatabase())
+	)
+	statedb, err := state.New(types.EmptyRootHash, base, nil)
+	require.NoError(t, err)
+	statedb.SetCode(addr, code)
+	baseRoot, err := statedb.Commit(0, true)
+	require.NoError(t, err)
+
+	// The base state is readable from the throwaway database, and changes to it are committed there only.
+	throwaway := newThrowawayDatabase(base)
+	statedb, err = state.New(baseRoot, throwaway, nil)
+	require.NoError(t, err)
+	require.Equal(t, code, statedb.GetCode(addr))
+	statedb.SetBalance(addr, big.NewInt(1))
+	root, err := statedb.Commit(1, true)
+	require.NoError(t, err)
+
+	_, err = base.TrieDB().Node(root)
+	require.Error(t, err)
+	statedb, err = state.New(root, throwaway, nil)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1), statedb.GetBalance(addr))
+	require.Equal(t, code, statedb.GetCode(addr))
+}
@@ -0,0 +1,11 @@
+package da
+
+import "context"
+
+// Makes batches available, returning the data to append to the sequencer inbox on L1.
+// Note: the sequencer inbox only accepts batches it carries (in calldata or blobs), so `L1DAProvider` is the only
+// provider until it can verify the availability of batches stored elsewhere.
+type DAProvider interface {
+	Store(ctx context.Context, batch []byte) ([]byte, error)
+	Retrieve(ctx context.Context, data []byte) ([]byte, error)
+}
@@ -0,0 +1,22 @@
+package da
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestL1DAProvider(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		p     = NewL1DAProvider()
+		batch = []byte{0x0, 0x1, 0x2}
+	)
+	data, err := p.Store(ctx, batch)
+	require.NoError(t, err)
+	require.Equal(t, batch, data)
+	retrieved, err := p.Retrieve(ctx, data)
+	require.NoError(t, err)
+	require.Equal(t, batch, retrieved)
+}
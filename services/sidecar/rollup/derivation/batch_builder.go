@@ -15,7 +15,10 @@ import (
 
 type BatchEncoderVersion = byte
 
-const V0 BatchEncoderVersion = 0x0
+const (
+	V0 BatchEncoderVersion = 0x0
+	V1 BatchEncoderVersion = 0x1
+)
 
 type Config interface {
 	GetL1OracleAddr() common.Address
@@ -35,34 +38,52 @@ type VersionedDataEncoder interface {
 	// Returns an `errBatchFull` if the block would cause the batch to exceed the target size.
 	// Returns an error if the block could not be processed.
 	ProcessBlock(block *ethTypes.Block, isNewEpoch bool) error
+	// Returns the number of the last L2 block included in the last flushed batch.
+	LastFlushedL2BlockNum() uint64
+	// Returns the number of sub-batches included in the last flushed batch.
+	LastFlushedNumSubBatches() int
 	// Resets the encoder, discarding all buffered data.
 	Reset()
 }
 
-type (
-	InvalidBlockError        struct{ Msg string }
-	HardTimeoutExceededError struct{ Msg string }
-)
+type Metricer interface {
+	RecordBatchBuilt(size int, numSubBatches int)
+	RecordSafeLag(lag uint64)
+	RecordHardTimeoutExceeded(numBlocks int)
+}
+
+type InvalidBlockError struct{ Msg string }
 
-func (e InvalidBlockError) Error() string        { return e.Msg }
-func (e HardTimeoutExceededError) Error() string { return e.Msg }
+func (e InvalidBlockError) Error() string { return e.Msg }
 
 type batchBuilder struct {
-	cfg           Config
-	encoder       VersionedDataEncoder
-	pendingBlocks []*ethTypes.Block
-	lastEnqueued  types.BlockID
-	lastBuilt     []byte
+	cfg             Config
+	encoder         VersionedDataEncoder
+	metrics         Metricer
+	pendingBlocks   []*ethTypes.Block
+	processedBlocks []processedBlock // blocks processed by the encoder, but not yet flushed
+	lastEnqueued    types.BlockID
+	lastBuilt       []byte
+	lastBuiltBlock  types.BlockID // last block included in `lastBuilt`
 
-	timeout uint64 // L1 epoch at which the batch should be sequenced (soft timeout).
+	epochTimeout uint64 // Soft timeout of the last processed L1 epoch.
 }
 
-func NewBatchBuilder(cfg Config, encoder VersionedDataEncoder) *batchBuilder {
-	return &batchBuilder{cfg, encoder, nil, types.BlockID{}, nil, 0}
+// A block processed by the encoder, along with the soft timeout of its L1 epoch (0 if unknown).
+type processedBlock struct {
+	block   *ethTypes.Block
+	timeout uint64
+}
+
+func NewBatchBuilder(cfg Config, encoder VersionedDataEncoder, metrics Metricer) *batchBuilder {
+	return &batchBuilder{cfg, encoder, metrics, nil, nil, types.BlockID{}, nil, types.BlockID{}, 0}
 }
 
 func (b *batchBuilder) LastEnqueued() types.BlockID { return b.lastEnqueued }
 
+// Returns the last block included in the last built batch.
+func (b *batchBuilder) LastBuiltBlock() types.BlockID { return b.lastBuiltBlock }
+
 // Enqueues a block, to be processed and batched.
 // Returns a `InvalidBlockError` if the block is not a child of the last enqueued block.
 func (b *batchBuilder) Enqueue(block *ethTypes.Block) error {
@@ -79,7 +100,10 @@ func (b *batchBuilder) Enqueue(block *ethTypes.Block) error {
 func (b *batchBuilder) Reset(lastEnqueued types.BlockID) {
 	b.encoder.Reset()
 	b.pendingBlocks = []*ethTypes.Block{}
+	b.processedBlocks = nil
 	b.lastEnqueued = lastEnqueued
+	b.lastBuiltBlock = lastEnqueued
+	b.epochTimeout = 0
 	b.Advance()
 }
 
@@ -91,6 +115,7 @@ func (b *batchBuilder) Build(l1Head types.BlockID, currentLag uint64) ([]byte, e
 	if b.lastBuilt != nil {
 		return b.lastBuilt, nil
 	}
+	b.metrics.RecordSafeLag(currentLag)
 	if err := b.processPending(); err != nil {
 		return nil, fmt.Errorf("failed to process pending blocks into a new batch: %w", err)
 	}
@@ -100,7 +125,6 @@ func (b *batchBuilder) Build(l1Head types.BlockID, currentLag uint64) ([]byte, e
 // Advances the builder, clearing the last built batch.
 func (b *batchBuilder) Advance() {
 	b.lastBuilt = nil
-	b.timeout = 0
 }
 
 // Tries to get the current batch.
@@ -108,22 +132,21 @@ func (b *batchBuilder) getBatch(l1Head types.BlockID, currentLag uint64) ([]byte
 	if b.encoder.IsEmpty() {
 		return nil, io.EOF
 	}
+	// If it's too late to sequence the earliest blocks in time, post them right away in a batch of their own.
+	if timeout := b.timeout(); timeout != 0 && b.isExpired(timeout, l1Head) {
+		if err := b.splitExpired(l1Head); err != nil {
+			return nil, fmt.Errorf("failed to split off expired blocks: %w", err)
+		}
+	}
 	// Force-build batch if necessary (lag or timeout exceeded).
 	var (
-		timeoutExceeded = b.timeout != 0 && l1Head.GetNumber() >= b.timeout
+		timeout         = b.timeout()
+		timeoutExceeded = timeout != 0 && l1Head.GetNumber() >= timeout
 		lagExceeded     = b.cfg.GetMaxSafeLag() != 0 && currentLag+b.cfg.GetMaxSafeLagDelta() >= b.cfg.GetMaxSafeLag()
 		force           = timeoutExceeded || lagExceeded
 	)
-	log.Info("Trying to get batch", "curr_l1#", l1Head.GetNumber(), "timeout_l1#", b.timeout, "lag", currentLag, "force?", force)
+	log.Info("Trying to get batch", "curr_l1#", l1Head.GetNumber(), "timeout_l1#", timeout, "lag", currentLag, "force?", force)
 	batch, err := b.encoder.Flush(force)
-	if force {
-		// If it's too late to sequence, the batch should just be dropped entirely.
-		// TODO: instead of dropping the whole batch, we can prune earlier sub-batches.
-		hardTimeoutExceeded := l1Head.GetNumber() >= b.timeout+b.cfg.GetSubSafetyMargin()
-		if hardTimeoutExceeded {
-			return nil, HardTimeoutExceededError{Msg: "hard timeout exceeded for batch"}
-		}
-	}
 	if err != nil {
 		if errors.Is(err, errBatchTooSmall) {
 			log.Warn("Batch too small, waiting for more blocks")
@@ -131,11 +154,95 @@ func (b *batchBuilder) getBatch(l1Head types.BlockID, currentLag uint64) ([]byte
 		}
 		return nil, fmt.Errorf("failed to get batch: %w", err)
 	}
+	b.metrics.RecordBatchBuilt(len(batch), b.encoder.LastFlushedNumSubBatches())
+	b.clearFlushed()
 	// Cache last built batch.
 	b.lastBuilt = batch
 	return batch, nil
 }
 
+// Returns the soft timeout of the batch, i.e. that of the earliest known L1 epoch in it (0 if unknown).
+// Note: this assumes L1 epochs are monotonically increasing.
+func (b *batchBuilder) timeout() uint64 {
+	for _, processed := range b.processedBlocks {
+		if processed.timeout != 0 {
+			return processed.timeout
+		}
+	}
+	return 0
+}
+
+// Returns true iff it's too late to sequence blocks with the given soft timeout.
+func (b *batchBuilder) isExpired(timeout uint64, l1Head types.BlockID) bool {
+	return l1Head.GetNumber() >= timeout+b.cfg.GetSubSafetyMargin()
+}
+
+// Splits the leading blocks that can no longer be sequenced in time off into a batch of their own (so they're
+// flushed next), and requeues the rest to be re-processed after them.
+// Blocks of an unknown epoch aren't considered expired, so nothing is split off if the batch starts with one.
+func (b *batchBuilder) splitExpired(l1Head types.BlockID) error {
+	numExpired := 0
+	for _, processed := range b.processedBlocks {
+		if processed.timeout == 0 || !b.isExpired(processed.timeout, l1Head) {
+			break
+		}
+		numExpired += 1
+	}
+	if numExpired == 0 {
+		return nil
+	}
+	var (
+		expired = b.processedBlocks[:numExpired]
+		kept    = b.processedBlocks[numExpired:]
+		pending = make([]*ethTypes.Block, 0, len(kept)+len(b.pendingBlocks))
+	)
+	log.Warn(
+		"Hard timeout exceeded, splitting off expired blocks",
+		"first_l2#", expired[0].block.NumberU64(),
+		"last_l2#", expired[len(expired)-1].block.NumberU64(),
+		"num_requeued", len(kept),
+	)
+	b.metrics.RecordHardTimeoutExceeded(len(expired))
+	if len(kept) == 0 {
+		return nil
+	}
+	for _, processed := range kept {
+		pending = append(pending, processed.block)
+	}
+	b.pendingBlocks = append(pending, b.pendingBlocks...)
+	b.processedBlocks = nil
+	// The first expired block may not start an epoch, so carry over its timeout.
+	b.epochTimeout = expired[0].timeout
+	b.encoder.Reset()
+	for _, processed := range expired {
+		// These blocks fit in the batch before, so they fit in one of their own.
+		if err := b.processBlock(processed.block); err != nil {
+			return fmt.Errorf("failed to re-process expired block: %w", err)
+		}
+	}
+	return nil
+}
+
+// Drops the processed blocks that were included in the last flushed batch.
+func (b *batchBuilder) clearFlushed() {
+	numFlushed := len(b.processedBlocks)
+	if !b.encoder.IsEmpty() {
+		lastFlushed := b.encoder.LastFlushedL2BlockNum()
+		numFlushed = 0
+		for _, processed := range b.processedBlocks {
+			if processed.block.NumberU64() > lastFlushed {
+				break
+			}
+			numFlushed += 1
+		}
+	}
+	if numFlushed > 0 {
+		last := b.processedBlocks[numFlushed-1].block
+		b.lastBuiltBlock = types.NewBlockID(last.NumberU64(), last.Hash())
+	}
+	b.processedBlocks = b.processedBlocks[numFlushed:]
+}
+
 // Processes pending blocks until batch is full.
 func (b *batchBuilder) processPending() error {
 	if len(b.pendingBlocks) == 0 {
@@ -170,7 +277,6 @@ func (b *batchBuilder) processBlock(block *ethTypes.Block) (err error) {
 			if err != nil {
 				return fmt.Errorf("could not unpack oracle tx: %w", err)
 			}
-			b.updateTimeout(epoch)
 		} else {
 			log.Trace("No oracle tx in block", "block#", block.NumberU64())
 		}
@@ -179,35 +285,16 @@ func (b *batchBuilder) processBlock(block *ethTypes.Block) (err error) {
 	if err := b.encoder.ProcessBlock(block, epoch != 0); err != nil {
 		return err
 	}
-	return err
-}
-
-// Updates the batch timeout if the given L1 epoch is earlier than the current timeout.
-// Note: the timeout won't be updated more than once assuming the L1 epoch is monotonically increasing.
-func (b *batchBuilder) updateTimeout(epoch uint64) {
-	timeout := epoch + b.cfg.GetSeqWindowSize() - b.cfg.GetSubSafetyMargin()
-	if b.timeout == 0 || b.timeout > timeout {
-		log.Info("Updating batch timeout", "epoch", epoch)
-		b.timeout = timeout
+	if epoch != 0 {
+		b.updateEpochTimeout(epoch)
 	}
+	b.processedBlocks = append(b.processedBlocks, processedBlock{block, b.epochTimeout})
+	return nil
 }
 
-type DecodeTxBatchError struct{ msg string }
-
-func (e *DecodeTxBatchError) Error() string {
-	return fmt.Sprintf("failed to decode batch: %s", e.msg)
-}
-
-// TODO: this is not currently called anywhere but will be useful for testing.
-func DecodeBatch(data []byte) (interface{}, error) {
-	if len(data) == 0 {
-		return nil, &DecodeTxBatchError{"empty batch data"}
-	}
-	// TODO: use map.
-	switch data[0] {
-	case V0:
-		return decodeV0(data[1:])
-	default:
-		return nil, &DecodeTxBatchError{fmt.Sprintf("invalid batch version: %d", data[0])}
-	}
+// Updates the timeout of the current L1 epoch.
+func (b *batchBuilder) updateEpochTimeout(epoch uint64) {
+	timeout := epoch + b.cfg.GetSeqWindowSize() - b.cfg.GetSubSafetyMargin()
+	log.Info("Updating epoch timeout", "epoch", epoch, "timeout_l1#", timeout)
+	b.epochTimeout = timeout
 }
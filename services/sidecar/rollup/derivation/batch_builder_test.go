@@ -0,0 +1,128 @@
+package derivation
+
+import (
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+	"github.com/specularL2/specular/services/sidecar/rollup/types"
+)
+
+var testL1OracleAddr = common.HexToAddress("0xff00000000000000000000000000000000000010")
+
+type builderTestConfig struct{ decoderTestConfig }
+
+func (c builderTestConfig) GetL1OracleAddr() common.Address { return testL1OracleAddr }
+func (c builderTestConfig) GetSeqWindowSize() uint64        { return 10 }
+func (c builderTestConfig) GetSubSafetyMargin() uint64      { return 2 }
+func (c builderTestConfig) GetMaxSafeLag() uint64           { return 0 }
+func (c builderTestConfig) GetMaxSafeLagDelta() uint64      { return 0 }
+
+// testMetrics records the sizes of built batches and the number of blocks that exceeded their hard timeout.
+type testMetrics struct {
+	batchSizes    []int
+	expiredBlocks int
+}
+
+func (m *testMetrics) RecordBatchBuilt(size int, _ int) { m.batchSizes = append(m.batchSizes, size) }
+func (m *testMetrics) RecordSafeLag(uint64)             {}
+func (m *testMetrics) RecordHardTimeoutExceeded(n int)  { m.expiredBlocks += n }
+
+// Builds a batch and returns its L2 block range.
+func buildBlockRange(t *testing.T, builder *batchBuilder, l1BlockNum uint64) (uint64, uint64) {
+	data, err := builder.Build(types.NewBlockID(l1BlockNum, common.Hash{}), 0)
+	require.NoError(t, err)
+	batch, err := DecodeBatch(builderTestConfig{}, data)
+	require.NoError(t, err)
+	builder.Advance()
+	first, last := batch.L2BlockRange()
+	return first, last
+}
+
+// Returns a chain of blocks, starting a new L1 epoch at each block number in `epochs`.
+func newTestChain(t *testing.T, numBlocks int, epochs map[int]uint64) []*ethTypes.Block {
+	oracleAbi, err := bindings.L1OracleMetaData.GetAbi()
+	require.NoError(t, err)
+	var (
+		blocks     []*ethTypes.Block
+		parentHash common.Hash
+	)
+	for i := 1; i <= numBlocks; i++ {
+		txs := []*ethTypes.Transaction{ethTypes.NewTx(&ethTypes.LegacyTx{Nonce: uint64(i)})}
+		if epoch, ok := epochs[i]; ok {
+			data, err := oracleAbi.Pack(
+				"setL1OracleValues",
+				new(big.Int).SetUint64(epoch), big.NewInt(0), big.NewInt(0), [32]byte{}, [32]byte{}, big.NewInt(0), big.NewInt(0),
+			)
+			require.NoError(t, err)
+			oracleTx := ethTypes.NewTx(&ethTypes.LegacyTx{To: &testL1OracleAddr, Data: data})
+			txs = append([]*ethTypes.Transaction{oracleTx}, txs...)
+		}
+		header := &ethTypes.Header{Number: big.NewInt(int64(i)), ParentHash: parentHash}
+		block := ethTypes.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+		blocks = append(blocks, block)
+		parentHash = block.Hash()
+	}
+	return blocks
+}
+
+func TestBuildSplitsOffExpiredBlocks(t *testing.T) {
+	var (
+		cfg     = builderTestConfig{}
+		metrics = &testMetrics{}
+		builder = NewBatchBuilder(cfg, NewBatchV0Encoder(cfg), metrics)
+		// Epoch 1 (soft timeout: 9, hard timeout: 11); epoch 5 (soft timeout: 13, hard timeout: 15).
+		blocks = newTestChain(t, 4, map[int]uint64{1: 1, 3: 5})
+	)
+	for _, block := range blocks {
+		require.NoError(t, builder.Enqueue(block))
+	}
+	// Nothing to force yet.
+	_, err := builder.Build(types.NewBlockID(5, common.Hash{}), 0)
+	require.ErrorIs(t, err, io.EOF)
+
+	// Epoch 1 expired: blocks 1-2 are posted on their own right away...
+	first, last := buildBlockRange(t, builder, 11)
+	require.Equal(t, uint64(1), first)
+	require.Equal(t, uint64(2), last)
+	require.Equal(t, 2, metrics.expiredBlocks)
+	require.Equal(t, types.NewBlockID(2, blocks[1].Hash()), builder.LastBuiltBlock())
+
+	// ...and blocks 3-4 are requeued, to wait for their own (soft) timeout.
+	_, err = builder.Build(types.NewBlockID(11, common.Hash{}), 0)
+	require.ErrorIs(t, err, io.EOF)
+	first, last = buildBlockRange(t, builder, 13)
+	require.Equal(t, uint64(3), first)
+	require.Equal(t, uint64(4), last)
+	require.Len(t, metrics.batchSizes, 2)
+
+	_, err = builder.Build(types.NewBlockID(13, common.Hash{}), 0)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestBuildPostsAllExpiredBlocks(t *testing.T) {
+	var (
+		cfg     = builderTestConfig{}
+		metrics = &testMetrics{}
+		builder = NewBatchBuilder(cfg, NewBatchV0Encoder(cfg), metrics)
+		// Block 1 is of an unknown epoch, so it isn't considered expired.
+		blocks = newTestChain(t, 3, map[int]uint64{2: 1})
+	)
+	for _, block := range blocks {
+		require.NoError(t, builder.Enqueue(block))
+	}
+	// Nothing is dropped: all blocks are posted.
+	first, last := buildBlockRange(t, builder, 11)
+	require.Equal(t, uint64(1), first)
+	require.Equal(t, uint64(3), last)
+	require.Zero(t, metrics.expiredBlocks)
+
+	_, err := builder.Build(types.NewBlockID(11, common.Hash{}), 0)
+	require.ErrorIs(t, err, io.EOF)
+}
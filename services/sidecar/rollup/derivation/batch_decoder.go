@@ -0,0 +1,226 @@
+package derivation
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+type DecoderConfig interface {
+	GetMaxBatchSize() uint64
+}
+
+type DecodeTxBatchError struct{ msg string }
+
+func (e *DecodeTxBatchError) Error() string {
+	return fmt.Sprintf("failed to decode batch: %s", e.msg)
+}
+
+// A batch, as decoded from the payload of an `appendTxBatch` call.
+type DecodedBatch struct {
+	Version    BatchEncoderVersion
+	SubBatches []DecodedSubBatch
+}
+
+// A sub-batch of consecutive (non-empty) L2 blocks, starting at `FirstL2BlockNum`.
+type DecodedSubBatch struct {
+	FirstL2BlockNum uint64
+	TxBlocks        []ethTypes.Transactions
+}
+
+// A single L2 block's txs, as derived from a batch.
+type DerivedBlock struct {
+	L2BlockNum uint64
+	Txs        ethTypes.Transactions
+}
+
+func (s *DecodedSubBatch) LastL2BlockNum() uint64 {
+	return s.FirstL2BlockNum + uint64(len(s.TxBlocks)) - 1
+}
+
+// Returns the first and last L2 block numbers covered by the batch.
+func (b *DecodedBatch) L2BlockRange() (uint64, uint64) {
+	if len(b.SubBatches) == 0 {
+		return 0, 0
+	}
+	return b.SubBatches[0].FirstL2BlockNum, b.SubBatches[len(b.SubBatches)-1].LastL2BlockNum()
+}
+
+// Returns all (non-empty) L2 blocks in the batch, in order.
+func (b *DecodedBatch) Blocks() []DerivedBlock {
+	var blocks []DerivedBlock
+	for _, subBatch := range b.SubBatches {
+		for i, txs := range subBatch.TxBlocks {
+			blocks = append(blocks, DerivedBlock{subBatch.FirstL2BlockNum + uint64(i), txs})
+		}
+	}
+	return blocks
+}
+
+// Decodes the sub-batches of a batch of a given version (excluding the version byte).
+type subBatchDecoder func(cfg DecoderConfig, data []byte) ([]DecodedSubBatch, error)
+
+var subBatchDecoders = map[BatchEncoderVersion]subBatchDecoder{
+	V0: decodeV0,
+	V1: decodeV1,
+}
+
+// Decodes a batch, as encoded by a `VersionedDataEncoder`.
+// Returns a `DecodeTxBatchError` if the data is malformed or violates the encoder's invariants.
+func DecodeBatch(cfg DecoderConfig, data []byte) (*DecodedBatch, error) {
+	if len(data) == 0 {
+		return nil, &DecodeTxBatchError{"empty batch data"}
+	}
+	decode, ok := subBatchDecoders[data[0]]
+	if !ok {
+		return nil, &DecodeTxBatchError{fmt.Sprintf("invalid batch version: %d", data[0])}
+	}
+	subBatches, err := decode(cfg, data[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &DecodedBatch{Version: data[0], SubBatches: subBatches}, nil
+}
+
+func decodeV0(cfg DecoderConfig, data []byte) ([]DecodedSubBatch, error) {
+	var raw []subBatch
+	if err := rlp.DecodeBytes(data, &raw); err != nil {
+		return nil, &DecodeTxBatchError{fmt.Sprintf("invalid rlp: %s", err)}
+	}
+	if err := validateSubBatches(raw); err != nil {
+		return nil, err
+	}
+	if err := validateV0Size(cfg, raw); err != nil {
+		return nil, err
+	}
+	return toDecodedSubBatches(raw)
+}
+
+// The maximum batch size applies to the compressed stream (as enforced by `BatchV1Encoder`), so it's checked
+// before decompressing. The decompressed size is further bounded by `maxDecompressedBatchSize`.
+func decodeV1(cfg DecoderConfig, data []byte) ([]DecodedSubBatch, error) {
+	if len(data) == 0 {
+		return nil, &DecodeTxBatchError{"missing compression algorithm"}
+	}
+	if size := uint64(len(data) - 1); size > cfg.GetMaxBatchSize() {
+		return nil, &DecodeTxBatchError{fmt.Sprintf("batch exceeds max size (size=%d, max=%d)", size, cfg.GetMaxBatchSize())}
+	}
+	decompressed, err := decompress(data[0], data[1:])
+	if err != nil {
+		return nil, &DecodeTxBatchError{fmt.Sprintf("failed to decompress: %s", err)}
+	}
+	var (
+		raw    []subBatch
+		stream = rlp.NewStream(bytes.NewReader(decompressed), uint64(len(decompressed)))
+	)
+	for {
+		var s subBatch
+		if err := stream.Decode(&s); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, &DecodeTxBatchError{fmt.Sprintf("invalid rlp: %s", err)}
+		}
+		raw = append(raw, s)
+	}
+	if err := validateSubBatches(raw); err != nil {
+		return nil, err
+	}
+	return toDecodedSubBatches(raw)
+}
+
+func toDecodedSubBatches(raw []subBatch) ([]DecodedSubBatch, error) {
+	decoded := make([]DecodedSubBatch, 0, len(raw))
+	for _, rawSubBatch := range raw {
+		subBatch := DecodedSubBatch{FirstL2BlockNum: rawSubBatch.FirstL2BlockNum}
+		for i, rawTxs := range rawSubBatch.TxBlocks {
+			txs, err := unmarshallTxs(rawTxs)
+			if err != nil {
+				return nil, &DecodeTxBatchError{
+					fmt.Sprintf("invalid tx in block %d: %s", rawSubBatch.FirstL2BlockNum+uint64(i), err),
+				}
+			}
+			subBatch.TxBlocks = append(subBatch.TxBlocks, txs)
+		}
+		decoded = append(decoded, subBatch)
+	}
+	return decoded, nil
+}
+
+// Checks that the sub-batches satisfy the invariants enforced by the encoders:
+// - every sub-batch and every block in it is non-empty (empty blocks are skipped, closing the sub-batch), and
+// - L2 block numbers strictly increase across sub-batches.
+func validateSubBatches(subBatches []subBatch) error {
+	if len(subBatches) == 0 {
+		return &DecodeTxBatchError{"no sub-batches"}
+	}
+	var lastL2BlockNum uint64
+	for i, subBatch := range subBatches {
+		if len(subBatch.TxBlocks) == 0 {
+			return &DecodeTxBatchError{fmt.Sprintf("sub-batch %d is empty", i)}
+		}
+		if i > 0 && subBatch.FirstL2BlockNum <= lastL2BlockNum {
+			return &DecodeTxBatchError{fmt.Sprintf(
+				"sub-batch %d starts at l2# %d, not after previous sub-batch end (l2# %d)",
+				i, subBatch.FirstL2BlockNum, lastL2BlockNum,
+			)}
+		}
+		for j, rawTxs := range subBatch.TxBlocks {
+			if len(rawTxs) == 0 {
+				blockNum := subBatch.FirstL2BlockNum + uint64(j)
+				return &DecodeTxBatchError{fmt.Sprintf("block %d is empty and should have been skipped", blockNum)}
+			}
+		}
+		lastL2BlockNum = subBatch.FirstL2BlockNum + uint64(len(subBatch.TxBlocks)) - 1
+	}
+	return nil
+}
+
+// Checks that the batch didn't exceed the maximum size prior to its last block being added,
+// using the same size accounting as `BatchV0Encoder`.
+func validateV0Size(cfg DecoderConfig, subBatches []subBatch) error {
+	var runningLen uint64
+	for _, subBatch := range subBatches[:len(subBatches)-1] {
+		runningLen += rlp.ListSize(subBatchContentSize(subBatch.FirstL2BlockNum, subBatch.TxBlocks))
+	}
+	var (
+		last       = subBatches[len(subBatches)-1]
+		sizeBefore = runningLen + emptySubBatchSize
+	)
+	if len(last.TxBlocks) > 1 {
+		prefix := last.TxBlocks[:len(last.TxBlocks)-1]
+		sizeBefore = runningLen + rlp.ListSize(subBatchContentSize(last.FirstL2BlockNum, prefix))
+	}
+	if sizeBefore > cfg.GetMaxBatchSize() {
+		return &DecodeTxBatchError{fmt.Sprintf("batch exceeds max size (size=%d, max=%d)", sizeBefore, cfg.GetMaxBatchSize())}
+	}
+	return nil
+}
+
+// Returns the content size of a sub-batch, as tracked by `subBatch.appendTxBlock`.
+func subBatchContentSize(firstL2BlockNum uint64, txBlocks []rawTxBlock) uint64 {
+	size := uint64(rlp.IntSize(firstL2BlockNum))
+	for _, rawTxs := range txBlocks {
+		for _, rawTx := range rawTxs {
+			size += uint64(len(rawTx))
+		}
+	}
+	return size
+}
+
+func unmarshallTxs(rawTxs rawTxBlock) (ethTypes.Transactions, error) {
+	txs := make(ethTypes.Transactions, 0, len(rawTxs))
+	for i, rawTx := range rawTxs {
+		var tx ethTypes.Transaction
+		if err := tx.UnmarshalBinary(rawTx); err != nil {
+			return nil, fmt.Errorf("could not unmarshall tx %d: %w", i, err)
+		}
+		txs = append(txs, &tx)
+	}
+	return txs, nil
+}
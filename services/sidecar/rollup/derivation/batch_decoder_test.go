@@ -0,0 +1,197 @@
+package derivation
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+)
+
+type decoderTestConfig struct{}
+
+func (c decoderTestConfig) GetTargetBatchSize() uint64 { return 1000 }
+func (c decoderTestConfig) GetMaxBatchSize() uint64    { return 2000 }
+
+func newTestBlock(num int64, numTxs int) *types.Block {
+	var txs []*types.Transaction
+	for i := 0; i < numTxs; i++ {
+		txs = append(txs, types.NewTx(&types.LegacyTx{Nonce: uint64(i)}))
+	}
+	return types.NewBlock(&types.Header{Number: big.NewInt(num)}, txs, nil, nil, trie.NewStackTrie(nil))
+}
+
+func encodeRawV0(t *testing.T, subBatches []subBatch) []byte {
+	encoded, err := rlp.EncodeToBytes(subBatches)
+	require.NoError(t, err)
+	return append([]byte{V0}, encoded...)
+}
+
+func TestDecodeBatchRoundTrip(t *testing.T) {
+	var (
+		enc    = NewBatchV0Encoder(decoderTestConfig{})
+		blocks = []*types.Block{newTestBlock(1, 1), newTestBlock(2, 2), newTestBlock(3, 0), newTestBlock(4, 1)}
+	)
+	for _, block := range blocks {
+		require.NoError(t, enc.ProcessBlock(block, false))
+	}
+	data, err := enc.Flush(true)
+	require.NoError(t, err)
+
+	batch, err := DecodeBatch(decoderTestConfig{}, data)
+	require.NoError(t, err)
+	require.Equal(t, V0, batch.Version)
+	// Empty block 3 is skipped, closing the first sub-batch.
+	require.Len(t, batch.SubBatches, 2)
+	require.Equal(t, uint64(1), batch.SubBatches[0].FirstL2BlockNum)
+	require.Equal(t, uint64(2), batch.SubBatches[0].LastL2BlockNum())
+	require.Equal(t, uint64(4), batch.SubBatches[1].FirstL2BlockNum)
+
+	first, last := batch.L2BlockRange()
+	require.Equal(t, uint64(1), first)
+	require.Equal(t, uint64(4), last)
+
+	derived := batch.Blocks()
+	require.Len(t, derived, 3)
+	for i, num := range []int{0, 1, 3} {
+		require.Equal(t, blocks[num].NumberU64(), derived[i].L2BlockNum)
+		require.Len(t, derived[i].Txs, len(blocks[num].Transactions()))
+		for j, tx := range derived[i].Txs {
+			require.Equal(t, blocks[num].Transactions()[j].Hash(), tx.Hash())
+		}
+	}
+}
+
+func TestDecodeBatchInvalid(t *testing.T) {
+	rawTx, err := types.NewTx(&types.LegacyTx{}).MarshalBinary()
+	require.NoError(t, err)
+	var txBlock = rawTxBlock{hexutil.Bytes(rawTx)}
+
+	_, err = DecodeBatch(decoderTestConfig{}, nil)
+	require.ErrorAs(t, err, new(*DecodeTxBatchError))
+
+	_, err = DecodeBatch(decoderTestConfig{}, []byte{0xff})
+	require.ErrorAs(t, err, new(*DecodeTxBatchError))
+
+	// Empty sub-batch.
+	_, err = DecodeBatch(decoderTestConfig{}, encodeRawV0(t, []subBatch{{FirstL2BlockNum: 1}}))
+	require.ErrorAs(t, err, new(*DecodeTxBatchError))
+
+	// Empty block.
+	data := encodeRawV0(t, []subBatch{{FirstL2BlockNum: 1, TxBlocks: []rawTxBlock{txBlock, {}}}})
+	_, err = DecodeBatch(decoderTestConfig{}, data)
+	require.ErrorAs(t, err, new(*DecodeTxBatchError))
+
+	// Overlapping sub-batches.
+	data = encodeRawV0(t, []subBatch{
+		{FirstL2BlockNum: 1, TxBlocks: []rawTxBlock{txBlock, txBlock}},
+		{FirstL2BlockNum: 2, TxBlocks: []rawTxBlock{txBlock}},
+	})
+	_, err = DecodeBatch(decoderTestConfig{}, data)
+	require.ErrorAs(t, err, new(*DecodeTxBatchError))
+
+	// Exceeds max size before the last block.
+	var oversized []rawTxBlock
+	for i := 0; i < int(decoderTestConfig{}.GetMaxBatchSize())/len(rawTx)+2; i++ {
+		oversized = append(oversized, txBlock)
+	}
+	data = encodeRawV0(t, []subBatch{{FirstL2BlockNum: 1, TxBlocks: oversized}})
+	_, err = DecodeBatch(decoderTestConfig{}, data)
+	require.ErrorAs(t, err, new(*DecodeTxBatchError))
+
+	// Valid, for reference.
+	data = encodeRawV0(t, []subBatch{
+		{FirstL2BlockNum: 1, TxBlocks: []rawTxBlock{txBlock}},
+		{FirstL2BlockNum: 2, TxBlocks: []rawTxBlock{txBlock}},
+	})
+	_, err = DecodeBatch(decoderTestConfig{}, data)
+	require.NoError(t, err)
+}
+
+func TestDecodeBatchV1RoundTrip(t *testing.T) {
+	blocks := []*types.Block{newTestBlock(1, 3), newTestBlock(2, 0), newTestBlock(3, 2), newTestBlock(4, 1)}
+	for _, algo := range []CompressionAlgo{Zlib, Brotli, Zstd} {
+		enc, err := NewBatchV1Encoder(decoderTestConfig{}, algo)
+		require.NoError(t, err)
+		for i, block := range blocks {
+			require.NoError(t, enc.ProcessBlock(block, i == 3))
+		}
+		data, err := enc.Flush(true)
+		require.NoError(t, err)
+		require.True(t, enc.IsEmpty())
+
+		batch, err := DecodeBatch(decoderTestConfig{}, data)
+		require.NoError(t, err)
+		require.Equal(t, V1, batch.Version)
+		// Empty block 2 closes the first sub-batch; block 4 starts a new epoch.
+		require.Len(t, batch.SubBatches, 3)
+		derived := batch.Blocks()
+		require.Len(t, derived, 3)
+		for i, num := range []int{0, 2, 3} {
+			require.Equal(t, blocks[num].NumberU64(), derived[i].L2BlockNum)
+			for j, tx := range derived[i].Txs {
+				require.Equal(t, blocks[num].Transactions()[j].Hash(), tx.Hash())
+			}
+		}
+	}
+	_, err := DecodeBatch(decoderTestConfig{}, []byte{V1, 0xff, 0x00})
+	require.ErrorAs(t, err, new(*DecodeTxBatchError))
+
+	// Oversized compressed streams are rejected before decompressing.
+	oversized := append([]byte{V1, Zlib}, make([]byte, decoderTestConfig{}.GetMaxBatchSize()+1)...)
+	_, err = DecodeBatch(decoderTestConfig{}, oversized)
+	require.ErrorAs(t, err, new(*DecodeTxBatchError))
+}
+
+func TestBatchV1EncoderMaxSize(t *testing.T) {
+	// Incompressible txs, so that compression doesn't shrink the batch.
+	newRandomBlock := func(num int64) *types.Block {
+		data := make([]byte, 100)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+		tx := types.NewTx(&types.LegacyTx{Data: data})
+		return types.NewBlock(&types.Header{Number: big.NewInt(num)}, []*types.Transaction{tx}, nil, nil, trie.NewStackTrie(nil))
+	}
+	enc, err := NewBatchV1Encoder(decoderTestConfig{}, Zstd)
+	require.NoError(t, err)
+	var num int64 = 1
+	for ; ; num++ {
+		if err := enc.ProcessBlock(newRandomBlock(num), false); err != nil {
+			require.ErrorIs(t, err, errBatchFull)
+			break
+		}
+	}
+	data, err := enc.Flush(true)
+	require.NoError(t, err)
+	require.LessOrEqual(t, uint64(len(data)-2), decoderTestConfig{}.GetMaxBatchSize())
+	require.Equal(t, uint64(num-1), enc.LastFlushedL2BlockNum())
+	_, err = DecodeBatch(decoderTestConfig{}, data)
+	require.NoError(t, err)
+}
+
+func TestBlobsRoundTrip(t *testing.T) {
+	for _, size := range []int{1, MaxBlobFrameSize, MaxBlobFrameSize + 1, MaxBlobBatchSize} {
+		batch := make([]byte, size)
+		for i := range batch {
+			batch[i] = byte(i)
+		}
+		blobs, err := EncodeBlobs(batch)
+		require.NoError(t, err)
+		require.Len(t, blobs, (size+MaxBlobFrameSize-1)/MaxBlobFrameSize)
+		decoded, err := DecodeBlobs(blobs)
+		require.NoError(t, err)
+		require.Equal(t, batch, decoded)
+	}
+	_, err := EncodeBlobs(make([]byte, MaxBlobBatchSize+1))
+	require.Error(t, err)
+
+	var invalid kzg4844.Blob
+	invalid[0] = 0x01
+	_, err = DecodeBlobs([]kzg4844.Blob{invalid})
+	require.ErrorAs(t, err, new(*DecodeTxBatchError))
+}
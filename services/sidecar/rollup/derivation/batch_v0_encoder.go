@@ -25,17 +25,23 @@ type V0Config interface {
 
 // TODO: refactor implementation (somewhat bug-prone).
 type BatchV0Encoder struct {
-	cfg        V0Config
-	subBatches []*subBatch
-	runningLen uint64
+	cfg                   V0Config
+	subBatches            []*subBatch
+	runningLen            uint64
+	lastFlushedL2BlockNum uint64 // last L2 block number in the last flushed batch
+	lastFlushedNumSubs    int    // # of sub-batches in the last flushed batch
 }
 
 func NewBatchV0Encoder(cfg V0Config) *BatchV0Encoder {
-	return &BatchV0Encoder{cfg, []*subBatch{newSubBatch()}, 0}
+	return &BatchV0Encoder{cfg, []*subBatch{newSubBatch()}, 0, 0, 0}
 }
 
 func (e *BatchV0Encoder) IsEmpty() bool { return e.size() <= emptySubBatchSize }
 
+func (e *BatchV0Encoder) LastFlushedL2BlockNum() uint64 { return e.lastFlushedL2BlockNum }
+
+func (e *BatchV0Encoder) LastFlushedNumSubBatches() int { return e.lastFlushedNumSubs }
+
 // Flushes data queued to the returned byte-array either if the batch is ready, or if forced.
 // Note that if forced, an empty batch may be returned.
 func (e *BatchV0Encoder) Flush(force bool) ([]byte, error) {
@@ -65,6 +71,8 @@ func (e *BatchV0Encoder) Flush(force bool) ([]byte, error) {
 		return nil, fmt.Errorf("failed to encode batch (l2# %d-%d): %w", firstL2BlockNum, lastL2BlockNum, err)
 	}
 	log.Info("Flushed batch", "first_l2#", firstL2BlockNum, "last_l2#", lastL2BlockNum, "size (B)", buf.Len())
+	e.lastFlushedL2BlockNum = lastL2BlockNum
+	e.lastFlushedNumSubs = lastSubBatchIdx + 1
 	// Delete all sub-batches (except the open one, if any).
 	e.subBatches = e.subBatches[lastSubBatchIdx+1:]
 	e.runningLen = 0
@@ -166,6 +174,16 @@ func (s *subBatch) isEmpty() bool { return s.contentSize == 0 }
 func (s *subBatch) size() uint64  { return rlp.ListSize(s.contentSize) }
 
 func (s *subBatch) appendTxBlock(blockNum uint64, txs types.Transactions) error {
+	marshalled, numBytes, err := marshallTxs(txs)
+	if err != nil {
+		return fmt.Errorf("could not marshall txs: %w", err)
+	}
+	s.appendRawTxBlock(blockNum, marshalled, numBytes)
+	return nil
+}
+
+// Appends a block of txs, as marshalled by `marshallTxs`.
+func (s *subBatch) appendRawTxBlock(blockNum uint64, rawTxs rawTxBlock, numBytes int) {
 	s.lastL2BlockNum = blockNum
 	// Set the first L2 block number if it hasn't been set yet.
 	if len(s.TxBlocks) == 0 {
@@ -173,13 +191,8 @@ func (s *subBatch) appendTxBlock(blockNum uint64, txs types.Transactions) error
 		s.contentSize = uint64(rlp.IntSize(blockNum))
 	}
 	// Append the block of txs to the sub-batch.
-	marshalled, numBytes, err := marshallTxs(txs)
-	if err != nil {
-		return fmt.Errorf("could not marshall txs: %w", err)
-	}
-	s.TxBlocks = append(s.TxBlocks, marshalled)
+	s.TxBlocks = append(s.TxBlocks, rawTxs)
 	s.contentSize += uint64(numBytes)
-	return nil
 }
 
 func marshallTxs(txs types.Transactions) (rawTxs rawTxBlock, numBytes int, err error) {
@@ -194,11 +207,3 @@ func marshallTxs(txs types.Transactions) (rawTxs rawTxBlock, numBytes int, err e
 	}
 	return rawTxs, numBytes, err
 }
-
-func decodeV0(data []byte) ([]subBatch, error) {
-	var decoded []subBatch
-	if err := rlp.Decode(bytes.NewReader(data), &decoded); err != nil {
-		return nil, err
-	}
-	return decoded, nil
-}
@@ -0,0 +1,157 @@
+package derivation
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+	"github.com/specularL2/specular/services/sidecar/utils/log"
+)
+
+// Upper bound on the bytes compression adds to the open sub-batch once it's closed and the stream is finalized
+// (i.e. stream headers and trailers, flush markers and the framing of incompressible data).
+const MaxCompressionOverhead = 1024
+
+// Encodes batches as: version (V1) || compression algo || compressed stream of RLP-encoded sub-batches.
+// Sub-batches are compressed as soon as they're closed, so that the target and maximum batch sizes
+// apply to the compressed output. The open sub-batch is accounted for by its (uncompressed) size.
+// Unlike V0, the maximum size is a hard cap (checked by the decoder before decompressing): blocks are only added
+// if the batch still fits them.
+type BatchV1Encoder struct {
+	cfg                   V0Config
+	algo                  CompressionAlgo
+	compressed            *bytes.Buffer
+	compressor            Compressor
+	numClosed             int       // # of sub-batches written to the compressor
+	openBatch             *subBatch // sub-batch currently being appended to
+	lastClosedL2BlockNum  uint64    // last L2 block number written to the compressor
+	lastFlushedL2BlockNum uint64    // last L2 block number in the last flushed batch
+	lastFlushedNumSubs    int       // # of sub-batches in the last flushed batch
+}
+
+func NewBatchV1Encoder(cfg V0Config, algo CompressionAlgo) (*BatchV1Encoder, error) {
+	buf := bytes.NewBuffer(nil)
+	compressor, err := NewCompressor(algo, buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compressor: %w", err)
+	}
+	return &BatchV1Encoder{cfg, algo, buf, compressor, 0, newSubBatch(), 0, 0, 0}, nil
+}
+
+func (e *BatchV1Encoder) IsEmpty() bool { return e.numClosed == 0 && e.openBatch.isEmpty() }
+
+func (e *BatchV1Encoder) LastFlushedL2BlockNum() uint64 { return e.lastFlushedL2BlockNum }
+
+func (e *BatchV1Encoder) LastFlushedNumSubBatches() int { return e.lastFlushedNumSubs }
+
+// Flushes data queued to the returned byte-array either if the batch is ready, or if forced.
+// Note that if forced, an empty batch may be returned.
+func (e *BatchV1Encoder) Flush(force bool) ([]byte, error) {
+	// Return error if the batch is too small (unless forced).
+	if !force && e.size() < e.cfg.GetTargetBatchSize() {
+		return nil, errBatchTooSmall
+	}
+	// Include the open sub-batch unless it's empty (blocks are only added to it if the batch fits them).
+	if !e.openBatch.isEmpty() {
+		if err := e.closeOpenSubBatch(); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.compressor.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize compressed stream: %w", err)
+	}
+	var batch = make([]byte, 0, 2+e.compressed.Len())
+	batch = append(batch, e.getVersion(), e.algo)
+	batch = append(batch, e.compressed.Bytes()...)
+	log.Info("Flushed batch", "num_sub_batches", e.numClosed, "last_l2#", e.lastClosedL2BlockNum, "algo", e.algo, "size (B)", len(batch))
+	e.lastFlushedL2BlockNum = e.lastClosedL2BlockNum
+	e.lastFlushedNumSubs = e.numClosed
+	// Start a new compressed stream (keeping the open sub-batch, if it wasn't included).
+	e.resetStream()
+	return batch, nil
+}
+
+// Processes a block. If the block is non-empty and fits, add it to the open sub-batch.
+// If the block belongs to a new epoch, close the open sub-batch and start a new one.
+func (e *BatchV1Encoder) ProcessBlock(block *types.Block, isNewEpoch bool) error {
+	var (
+		// Block is empty
+		shouldSkipBlock = len(block.Transactions()) == 0
+		// Should close sub-batch if the block is empty, OR... the block belongs to a new epoch.
+		shouldCloseSubBatch = !e.openBatch.isEmpty() && (shouldSkipBlock || isNewEpoch)
+	)
+	if shouldCloseSubBatch {
+		if err := e.closeOpenSubBatch(); err != nil {
+			return err
+		}
+	}
+	// Skip intrinsically-derivable blocks.
+	if shouldSkipBlock {
+		log.Info("Skipping intrinsically-derivable block", "block#", block.NumberU64())
+		return nil
+	}
+	rawTxs, numBytes, err := marshallTxs(block.Transactions())
+	if err != nil {
+		return fmt.Errorf("could not marshall txs: %w", err)
+	}
+	// Enforce hard cap on batch size.
+	if size := e.sizeWithBlock(block.NumberU64(), uint64(numBytes)); size > e.cfg.GetMaxBatchSize() {
+		if e.IsEmpty() {
+			return fmt.Errorf("block %d can't fit in a batch (size=%d, max=%d)", block.NumberU64(), size, e.cfg.GetMaxBatchSize())
+		}
+		return errBatchFull
+	}
+	e.openBatch.appendRawTxBlock(block.NumberU64(), rawTxs, numBytes)
+	return nil
+}
+
+func (e *BatchV1Encoder) Reset() {
+	e.openBatch = newSubBatch()
+	e.resetStream()
+}
+
+// Returns the data format version (v1).
+func (e *BatchV1Encoder) getVersion() BatchEncoderVersion { return V1 }
+
+// Returns the expected size of the encoded batch (compressed sub-batches + uncompressed open sub-batch).
+func (e *BatchV1Encoder) size() uint64 {
+	return uint64(e.compressed.Len()) + e.openBatch.size()
+}
+
+// Returns the worst-case size of the flushed batch (excluding its header) if the given block were added.
+func (e *BatchV1Encoder) sizeWithBlock(blockNum uint64, numBytes uint64) uint64 {
+	contentSize := e.openBatch.contentSize + numBytes
+	if e.openBatch.isEmpty() {
+		contentSize = uint64(rlp.IntSize(blockNum)) + numBytes
+	}
+	return uint64(e.compressed.Len()) + rlp.ListSize(contentSize) + MaxCompressionOverhead
+}
+
+// Writes the open sub-batch to the compressed stream and starts a new one.
+func (e *BatchV1Encoder) closeOpenSubBatch() error {
+	if err := rlp.Encode(e.compressor, e.openBatch); err != nil {
+		return fmt.Errorf("failed to encode sub-batch: %w", err)
+	}
+	// Flush, so that the compressed size is up-to-date.
+	if err := e.compressor.Flush(); err != nil {
+		return fmt.Errorf("failed to flush compressor: %w", err)
+	}
+	log.Info("Closing sub-batch...",
+		"first_l2#", e.openBatch.FirstL2BlockNum,
+		"last_l2#", e.openBatch.lastL2BlockNum,
+		"compressed_len", e.compressed.Len(),
+	)
+	e.numClosed += 1
+	e.lastClosedL2BlockNum = e.openBatch.lastL2BlockNum
+	e.openBatch = newSubBatch()
+	return nil
+}
+
+// Discards the compressed stream.
+func (e *BatchV1Encoder) resetStream() {
+	e.compressed = bytes.NewBuffer(nil)
+	e.compressor.Reset(e.compressed)
+	e.numClosed = 0
+}
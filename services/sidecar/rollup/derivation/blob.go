@@ -0,0 +1,90 @@
+package derivation
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+const (
+	// Calldata payload of a batch tx whose batch data is carried in its blobs.
+	BlobBatchMarker byte = 0xb0
+	// Maximum number of blobs in a single tx.
+	MaxBlobsPerTx = params.MaxBlobGasPerBlock / params.BlobTxBlobGasPerBlob
+	// Only the low 31 bytes of a field element are used, so that it's always canonical (less than the BLS modulus).
+	blobBytesPerFieldElement = params.BlobTxBytesPerFieldElement - 1
+	blobFrameLengthSize      = 4
+	// Maximum number of batch bytes that fit in a single blob (frame).
+	MaxBlobFrameSize = params.BlobTxFieldElementsPerBlob*blobBytesPerFieldElement - blobFrameLengthSize
+	// Maximum number of batch bytes that fit in a single tx.
+	MaxBlobBatchSize = MaxBlobsPerTx * MaxBlobFrameSize
+)
+
+// Splits a batch into blob-sized frames, and encodes each frame into a blob as:
+// big-endian uint32 frame length || frame data, packed into the low 31 bytes of each field element.
+func EncodeBlobs(batch []byte) ([]kzg4844.Blob, error) {
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("cannot encode empty batch into blobs")
+	}
+	if len(batch) > MaxBlobBatchSize {
+		return nil, fmt.Errorf("batch too large for blobs (size=%d, max=%d)", len(batch), MaxBlobBatchSize)
+	}
+	var blobs []kzg4844.Blob
+	for start := 0; start < len(batch); start += MaxBlobFrameSize {
+		end := start + MaxBlobFrameSize
+		if end > len(batch) {
+			end = len(batch)
+		}
+		blobs = append(blobs, encodeBlobFrame(batch[start:end]))
+	}
+	return blobs, nil
+}
+
+// Decodes a batch from blobs encoded by `EncodeBlobs`.
+func DecodeBlobs(blobs []kzg4844.Blob) ([]byte, error) {
+	if len(blobs) == 0 {
+		return nil, &DecodeTxBatchError{"no blobs"}
+	}
+	var batch []byte
+	for i := range blobs {
+		frame, err := decodeBlobFrame(&blobs[i])
+		if err != nil {
+			return nil, &DecodeTxBatchError{fmt.Sprintf("invalid blob %d: %s", i, err)}
+		}
+		batch = append(batch, frame...)
+	}
+	return batch, nil
+}
+
+func encodeBlobFrame(frame []byte) kzg4844.Blob {
+	var (
+		blob kzg4844.Blob
+		data = make([]byte, blobFrameLengthSize+len(frame))
+	)
+	binary.BigEndian.PutUint32(data, uint32(len(frame)))
+	copy(data[blobFrameLengthSize:], frame)
+	for i := 0; len(data) > 0; i++ {
+		n := copy(blob[i*params.BlobTxBytesPerFieldElement+1:(i+1)*params.BlobTxBytesPerFieldElement], data)
+		data = data[n:]
+	}
+	return blob
+}
+
+func decodeBlobFrame(blob *kzg4844.Blob) ([]byte, error) {
+	data := make([]byte, 0, params.BlobTxFieldElementsPerBlob*blobBytesPerFieldElement)
+	for i := 0; i < params.BlobTxFieldElementsPerBlob; i++ {
+		fieldElement := blob[i*params.BlobTxBytesPerFieldElement : (i+1)*params.BlobTxBytesPerFieldElement]
+		if fieldElement[0] != 0 {
+			return nil, fmt.Errorf("field element %d has a non-zero high byte", i)
+		}
+		data = append(data, fieldElement[1:]...)
+	}
+	length := binary.BigEndian.Uint32(data)
+	if length > MaxBlobFrameSize {
+		return nil, fmt.Errorf("frame length %d exceeds maximum (%d)", length, MaxBlobFrameSize)
+	}
+	return data[blobFrameLengthSize : blobFrameLengthSize+length], nil
+}
@@ -0,0 +1,97 @@
+package derivation
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+type CompressionAlgo = byte
+
+const (
+	Zlib   CompressionAlgo = 0x0
+	Brotli CompressionAlgo = 0x1
+	Zstd   CompressionAlgo = 0x2
+)
+
+// Upper bound on the size of a decompressed batch, to protect against decompression bombs.
+const maxDecompressedBatchSize = 16 * 1024 * 1024
+
+var compressionAlgoNames = map[string]CompressionAlgo{
+	"zlib":   Zlib,
+	"brotli": Brotli,
+	"zstd":   Zstd,
+}
+
+// Streaming compressor. Implemented by zlib, brotli and zstd writers.
+type Compressor interface {
+	io.Writer
+	// Flushes any pending data to the underlying writer.
+	Flush() error
+	// Flushes and finalizes the compressed stream.
+	Close() error
+	// Discards the compressor's state and starts a new stream written to `w`.
+	Reset(w io.Writer)
+}
+
+// Parses a compression algorithm name (one of: zlib, brotli, zstd).
+func ParseCompressionAlgo(name string) (CompressionAlgo, error) {
+	algo, ok := compressionAlgoNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported compression algorithm: %s", name)
+	}
+	return algo, nil
+}
+
+func NewCompressor(algo CompressionAlgo, w io.Writer) (Compressor, error) {
+	switch algo {
+	case Zlib:
+		return zlib.NewWriterLevel(w, zlib.BestCompression)
+	case Brotli:
+		return brotli.NewWriterLevel(w, brotli.BestCompression), nil
+	case Zstd:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm: %d", algo)
+	}
+}
+
+func NewDecompressor(algo CompressionAlgo, r io.Reader) (io.ReadCloser, error) {
+	switch algo {
+	case Zlib:
+		return zlib.NewReader(r)
+	case Brotli:
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case Zstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm: %d", algo)
+	}
+}
+
+// Decompresses `data`, failing if the result exceeds `maxDecompressedBatchSize`.
+func decompress(algo CompressionAlgo, data []byte) ([]byte, error) {
+	r, err := NewDecompressor(algo, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	decompressed, err := io.ReadAll(io.LimitReader(r, maxDecompressedBatchSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxDecompressedBatchSize {
+		return nil, fmt.Errorf("decompressed batch exceeds %d bytes", maxDecompressedBatchSize)
+	}
+	return decompressed, nil
+}
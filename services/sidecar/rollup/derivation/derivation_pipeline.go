@@ -0,0 +1,181 @@
+package derivation
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+	"github.com/specularL2/specular/services/sidecar/utils/log"
+)
+
+type PipelineConfig interface {
+	DecoderConfig
+	GetSequencerInboxAddr() common.Address
+}
+
+type L1BatchSource interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*ethTypes.Block, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error)
+}
+
+// Resolves data appended to the sequencer inbox into the batch it carries.
+type BatchRetriever interface {
+	Retrieve(ctx context.Context, data []byte) ([]byte, error)
+}
+
+// Fetches the blobs carried by L1 txs, which aren't served by the execution layer (e.g. from a beacon node).
+type BlobSource interface {
+	GetBlobs(ctx context.Context, l1Header *ethTypes.Header, hashes []common.Hash) ([]kzg4844.Blob, error)
+}
+
+type L2ChainReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*ethTypes.Block, error)
+}
+
+type DerivedBlockMismatchError struct{ Msg string }
+
+func (e DerivedBlockMismatchError) Error() string { return e.Msg }
+
+// Derives L2 block contents from batches appended to L1, and checks them against a local L2 chain.
+// Note: this only replays sequenced txs; it doesn't re-execute them.
+type DerivationPipeline struct {
+	cfg      PipelineConfig
+	l1Client L1BatchSource
+	l2Client L2ChainReader
+	batches  BatchRetriever
+	blobs    BlobSource
+}
+
+// `blobs` may be nil if batches are never carried in blobs.
+func NewDerivationPipeline(
+	cfg PipelineConfig,
+	l1Client L1BatchSource,
+	l2Client L2ChainReader,
+	batches BatchRetriever,
+	blobs BlobSource,
+) *DerivationPipeline {
+	return &DerivationPipeline{cfg, l1Client, l2Client, batches, blobs}
+}
+
+// Decodes all batches successfully appended to the sequencer inbox in the given L1 block.
+func (p *DerivationPipeline) BatchesInL1Block(ctx context.Context, l1BlockNum uint64) ([]*DecodedBatch, error) {
+	block, err := p.l1Client.BlockByNumber(ctx, new(big.Int).SetUint64(l1BlockNum))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get l1 block (l1# %d): %w", l1BlockNum, err)
+	}
+	var batches []*DecodedBatch
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || *tx.To() != p.cfg.GetSequencerInboxAddr() {
+			continue
+		}
+		data, err := bridge.UnpackAppendTxBatchData(tx)
+		if err != nil {
+			log.Trace("Skipping non-batch inbox tx", "tx_hash", tx.Hash(), "err", err)
+			continue
+		}
+		receipt, err := p.l1Client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipt (tx_hash=%s): %w", tx.Hash(), err)
+		}
+		if receipt.Status != ethTypes.ReceiptStatusSuccessful {
+			log.Info("Skipping reverted batch tx", "tx_hash", tx.Hash(), "l1Block#", l1BlockNum)
+			continue
+		}
+		if len(data) == 1 && data[0] == BlobBatchMarker {
+			if len(tx.BlobHashes()) == 0 {
+				// The inbox can't check that the marker comes with blobs, so this is an empty batch.
+				log.Warn("Skipping blob batch tx without blobs", "tx_hash", tx.Hash(), "l1Block#", l1BlockNum)
+				continue
+			}
+			data, err = p.blobBatch(ctx, block.Header(), tx)
+		} else {
+			data, err = p.batches.Retrieve(ctx, data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve batch (tx_hash=%s): %w", tx.Hash(), err)
+		}
+		batch, err := DecodeBatch(p.cfg, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode batch (tx_hash=%s): %w", tx.Hash(), err)
+		}
+		first, last := batch.L2BlockRange()
+		log.Info("Decoded batch", "tx_hash", tx.Hash(), "first_l2#", first, "last_l2#", last)
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// Fetches and decodes the batch carried in the tx's blobs.
+func (p *DerivationPipeline) blobBatch(ctx context.Context, l1Header *ethTypes.Header, tx *ethTypes.Transaction) ([]byte, error) {
+	if p.blobs == nil {
+		return nil, fmt.Errorf("batch is carried in blobs, but no blob source is configured")
+	}
+	blobs, err := p.blobs.GetBlobs(ctx, l1Header, tx.BlobHashes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blobs: %w", err)
+	}
+	return DecodeBlobs(blobs)
+}
+
+// Checks that the batch matches the local L2 chain: each derived block must contain exactly the same txs,
+// and any blocks skipped within the batch must be empty.
+// Returns a `DerivedBlockMismatchError` if they don't match.
+func (p *DerivationPipeline) VerifyBatch(ctx context.Context, batch *DecodedBatch) error {
+	var (
+		first, last = batch.L2BlockRange()
+		blocks      = batch.Blocks()
+		idx         = 0
+	)
+	for num := first; num <= last; num++ {
+		l2Block, err := p.l2Client.BlockByNumber(ctx, new(big.Int).SetUint64(num))
+		if err != nil {
+			return fmt.Errorf("failed to get l2 block (l2# %d): %w", num, err)
+		}
+		var expected ethTypes.Transactions
+		if idx < len(blocks) && blocks[idx].L2BlockNum == num {
+			expected = blocks[idx].Txs
+			idx++
+		}
+		if err := compareTxs(num, expected, l2Block.Transactions()); err != nil {
+			return err
+		}
+	}
+	log.Info("Verified batch against l2 chain", "first_l2#", first, "last_l2#", last)
+	return nil
+}
+
+// Decodes and verifies all batches appended in the given L1 block.
+func (p *DerivationPipeline) VerifyL1Block(ctx context.Context, l1BlockNum uint64) error {
+	batches, err := p.BatchesInL1Block(ctx, l1BlockNum)
+	if err != nil {
+		return err
+	}
+	for _, batch := range batches {
+		if err := p.VerifyBatch(ctx, batch); err != nil {
+			return fmt.Errorf("failed to verify batch in l1 block (l1# %d): %w", l1BlockNum, err)
+		}
+	}
+	return nil
+}
+
+func compareTxs(l2BlockNum uint64, expected, actual ethTypes.Transactions) error {
+	if len(expected) != len(actual) {
+		return DerivedBlockMismatchError{fmt.Sprintf(
+			"mismatching tx count in l2 block %d (derived=%d, local=%d)", l2BlockNum, len(expected), len(actual),
+		)}
+	}
+	for i := range expected {
+		if expected[i].Hash() != actual[i].Hash() {
+			return DerivedBlockMismatchError{fmt.Sprintf(
+				"mismatching tx %d in l2 block %d (derived=%s, local=%s)",
+				i, l2BlockNum, expected[i].Hash(), actual[i].Hash(),
+			)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package derivation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge/bridgetest"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
+)
+
+type pipelineTestConfig struct{ decoderTestConfig }
+
+func (c pipelineTestConfig) GetSequencerInboxAddr() common.Address { return bridgetest.InboxAddr }
+
+// Inbox data is the batch itself (i.e. the L1 DA provider).
+type l1Retriever struct{}
+
+func (l1Retriever) Retrieve(_ context.Context, data []byte) ([]byte, error) { return data, nil }
+
+// L2 chain of the given blocks, keyed by number (missing blocks are empty).
+type testL2Chain map[uint64]*types.Block
+
+func (c testL2Chain) BlockByNumber(_ context.Context, number *big.Int) (*types.Block, error) {
+	if block, ok := c[number.Uint64()]; ok {
+		return block, nil
+	}
+	return types.NewBlockWithHeader(&types.Header{Number: number}), nil
+}
+
+func encodeTestBatch(t *testing.T, version BatchEncoderVersion, blocks []*types.Block) []byte {
+	var enc interface {
+		ProcessBlock(*types.Block, bool) error
+		Flush(bool) ([]byte, error)
+	}
+	enc = NewBatchV0Encoder(decoderTestConfig{})
+	if version == V1 {
+		v1, err := NewBatchV1Encoder(decoderTestConfig{}, Zlib)
+		require.NoError(t, err)
+		enc = v1
+	}
+	for _, block := range blocks {
+		require.NoError(t, enc.ProcessBlock(block, false))
+	}
+	data, err := enc.Flush(true)
+	require.NoError(t, err)
+	return data
+}
+
+// Appends batches to the (simulated) L1 inbox as the disseminator does, and derives them back.
+func TestDerivationPipelineSimulatedL1(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		l1     = bridgetest.NewL1(t)
+		txMgr  = l1.NewTxManager(t)
+		blocks = []*types.Block{newTestBlock(1, 2), newTestBlock(2, 1), newTestBlock(3, 3), newTestBlock(4, 1)}
+		l2     = testL2Chain{}
+	)
+	for _, block := range blocks {
+		l2[block.NumberU64()] = block
+	}
+	pipeline := NewDerivationPipeline(pipelineTestConfig{}, l1, l2, l1Retriever{}, nil)
+
+	// V0 and V1 batches are accepted by the inbox, and derived.
+	for i, version := range []BatchEncoderVersion{V0, V1} {
+		receipt, err := txMgr.AppendTxBatch(ctx, encodeTestBatch(t, version, blocks[2*i:2*i+2]))
+		require.NoError(t, err)
+		require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+		require.Len(t, receipt.Logs, 1)
+		require.Equal(t, bridge.InboxEvent(bridge.TxBatchAppendedEventName).ID, receipt.Logs[0].Topics[0])
+		batches, err := pipeline.BatchesInL1Block(ctx, receipt.BlockNumber.Uint64())
+		require.NoError(t, err)
+		require.Len(t, batches, 1)
+		require.Equal(t, version, batches[0].Version)
+		first, last := batches[0].L2BlockRange()
+		require.Equal(t, blocks[2*i].NumberU64(), first)
+		require.Equal(t, blocks[2*i+1].NumberU64(), last)
+		require.NoError(t, pipeline.VerifyL1Block(ctx, receipt.BlockNumber.Uint64()))
+	}
+
+	// A batch that doesn't match the local chain is detected.
+	l2[2] = newTestBlock(2, 2)
+	receipt, err := txMgr.AppendTxBatch(ctx, encodeTestBatch(t, V0, blocks[:2]))
+	require.NoError(t, err)
+	require.ErrorAs(t, pipeline.VerifyL1Block(ctx, receipt.BlockNumber.Uint64()), new(DerivedBlockMismatchError))
+
+	// The blob marker is accepted by the inbox, but carries no batch without blobs.
+	receipt, err = txMgr.AppendTxBatch(ctx, []byte{BlobBatchMarker})
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	batches, err := pipeline.BatchesInL1Block(ctx, receipt.BlockNumber.Uint64())
+	require.NoError(t, err)
+	require.Empty(t, batches)
+
+	// Blobs are unsupported by the simulated L1 (so the disseminator falls back to calldata).
+	blobs, err := EncodeBlobs([]byte{0x1})
+	require.NoError(t, err)
+	_, err = txMgr.AppendTxBatchWithBlobs(ctx, []byte{BlobBatchMarker}, blobs)
+	require.ErrorIs(t, err, txmgr.ErrBlobsUnsupported)
+
+	// Unknown versions, the blob marker followed by data, and appends by others are rejected by the inbox.
+	sequencer := crypto.PubkeyToAddress(l1.Sequencer.PublicKey)
+	for _, data := range [][]byte{{0x02}, {BlobBatchMarker, 0x00}, {}} {
+		msg := ethereum.CallMsg{From: sequencer, To: &bridgetest.InboxAddr, Data: packAppendTxBatch(t, data)}
+		_, err = l1.EstimateGas(ctx, msg)
+		require.ErrorContains(t, err, "execution reverted")
+	}
+	msg := ethereum.CallMsg{From: common.Address{0x1}, To: &bridgetest.InboxAddr, Data: packAppendTxBatch(t, []byte{V0})}
+	_, err = l1.EstimateGas(ctx, msg)
+	require.ErrorContains(t, err, "execution reverted")
+}
+
+func packAppendTxBatch(t *testing.T, batch []byte) []byte {
+	inboxAbi, err := bindings.ISequencerInboxMetaData.GetAbi()
+	require.NoError(t, err)
+	data, err := inboxAbi.Pack(bridge.AppendTxBatchFnName, batch)
+	require.NoError(t, err)
+	return data
+}
+
+// Serves an L1 block, and all receipts as successful.
+type testL1Source struct{ block *types.Block }
+
+func (s testL1Source) BlockByNumber(context.Context, *big.Int) (*types.Block, error) {
+	return s.block, nil
+}
+
+func (s testL1Source) TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+}
+
+// Serves the blob sidecars of the given slot (`secondsPerSlot` being 12, with genesis at 0) over the beacon API.
+func newTestBeacon(t *testing.T, slot uint64, sidecar *types.BlobTxSidecar) string {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eth/v1/beacon/genesis", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"genesis_time":"0"}}`))
+	})
+	mux.HandleFunc("/eth/v1/config/spec", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"SECONDS_PER_SLOT":"12"}}`))
+	})
+	mux.HandleFunc(fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%d", slot), func(w http.ResponseWriter, _ *http.Request) {
+		var sidecars []eth.BlobSidecar
+		for i := range sidecar.Blobs {
+			sidecars = append(sidecars, eth.BlobSidecar{
+				Index:         fmt.Sprint(i),
+				Blob:          sidecar.Blobs[i][:],
+				KZGCommitment: sidecar.Commitments[i][:],
+				KZGProof:      sidecar.Proofs[i][:],
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": sidecars})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestDerivationPipelineBlobs(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		blocks  = []*types.Block{newTestBlock(1, 2), newTestBlock(2, 1)}
+		batch   = encodeTestBatch(t, V1, blocks)
+		sidecar = &types.BlobTxSidecar{}
+	)
+	blobs, err := EncodeBlobs(batch)
+	require.NoError(t, err)
+	for _, blob := range blobs {
+		commitment, err := kzg4844.BlobToCommitment(blob)
+		require.NoError(t, err)
+		proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+		require.NoError(t, err)
+		sidecar.Blobs = append(sidecar.Blobs, blob)
+		sidecar.Commitments = append(sidecar.Commitments, commitment)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+	}
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(key, types.NewCancunSigner(big.NewInt(1)), &types.BlobTx{
+		ChainID:    uint256.NewInt(1),
+		To:         bridgetest.InboxAddr,
+		Data:       packAppendTxBatch(t, []byte{BlobBatchMarker}),
+		BlobHashes: sidecar.BlobHashes(),
+	})
+	require.NoError(t, err)
+	for i := range sidecar.Commitments {
+		require.Equal(t, tx.BlobHashes()[i], eth.BlobHash(&sidecar.Commitments[i]))
+	}
+
+	var (
+		header   = &types.Header{Number: big.NewInt(10), Time: 10 * 12}
+		l1Block  = types.NewBlock(header, []*types.Transaction{tx}, nil, nil, trie.NewStackTrie(nil))
+		l2       = testL2Chain{1: blocks[0], 2: blocks[1]}
+		beacon   = eth.NewBeaconClient(newTestBeacon(t, 10, sidecar))
+		pipeline = NewDerivationPipeline(pipelineTestConfig{}, testL1Source{l1Block}, l2, l1Retriever{}, beacon)
+	)
+	batches, err := pipeline.BatchesInL1Block(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	first, last := batches[0].L2BlockRange()
+	require.Equal(t, uint64(1), first)
+	require.Equal(t, uint64(2), last)
+	require.NoError(t, pipeline.VerifyL1Block(ctx, 10))
+
+	// Blobs that aren't served (e.g. pruned), or a missing blob source, fail derivation.
+	pipeline = NewDerivationPipeline(
+		pipelineTestConfig{}, testL1Source{l1Block}, l2, l1Retriever{},
+		eth.NewBeaconClient(newTestBeacon(t, 11, sidecar)),
+	)
+	_, err = pipeline.BatchesInL1Block(ctx, 10)
+	require.Error(t, err)
+	pipeline = NewDerivationPipeline(pipelineTestConfig{}, testL1Source{l1Block}, l2, l1Retriever{}, nil)
+	_, err = pipeline.BatchesInL1Block(ctx, 10)
+	require.Error(t, err)
+
+	// Blobs not matching their commitments are rejected.
+	sidecar.Blobs[0][1] ^= 0x1
+	pipeline = NewDerivationPipeline(
+		pipelineTestConfig{}, testL1Source{l1Block}, l2, l1Retriever{},
+		eth.NewBeaconClient(newTestBeacon(t, 10, sidecar)),
+	)
+	_, err = pipeline.BatchesInL1Block(ctx, 10)
+	require.Error(t, err)
+}
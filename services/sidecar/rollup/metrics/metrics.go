@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
+	txMetrics "github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr/metrics"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+	"github.com/specularL2/specular/services/sidecar/utils/log"
+)
+
+const (
+	Namespace = "specular_sidecar"
+
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+// Metrics records sidecar service metrics in its own prometheus registry.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	batchSize          prometheus.Histogram
+	batchNumSubBatches prometheus.Histogram
+	batchesBuilt       prometheus.Counter
+	safeLag            prometheus.Gauge
+	hardTimeouts       prometheus.Counter
+	hardTimeoutBlocks  prometheus.Counter
+
+	assertionsCreated       prometheus.Counter
+	assertionsConfirmed     prometheus.Counter
+	assertionsRejected      prometheus.Counter
+	lastAssertionL2BlockNum prometheus.Gauge
+}
+
+func NewMetrics() *Metrics {
+	var (
+		registry = prometheus.NewRegistry()
+		factory  = promauto.With(registry)
+	)
+	registry.MustRegister(
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewGoCollector(),
+	)
+	return &Metrics{
+		registry: registry,
+		batchSize: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "disseminator",
+			Name:      "batch_size_bytes",
+			Help:      "Size of built batches, in bytes",
+			Buckets:   prometheus.ExponentialBuckets(1024, 2, 10),
+		}),
+		batchNumSubBatches: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "disseminator",
+			Name:      "batch_sub_batches",
+			Help:      "Number of sub-batches in built batches",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+		}),
+		batchesBuilt: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "disseminator",
+			Name:      "batches_built_total",
+			Help:      "Number of built batches",
+		}),
+		safeLag: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: "disseminator",
+			Name:      "safe_lag",
+			Help:      "Number of L2 blocks between the unsafe and safe heads",
+		}),
+		hardTimeouts: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "disseminator",
+			Name:      "hard_timeouts_total",
+			Help:      "Number of times batched blocks exceeded their hard timeout",
+		}),
+		hardTimeoutBlocks: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "disseminator",
+			Name:      "hard_timeout_blocks_total",
+			Help:      "Number of L2 blocks posted after exceeding their hard timeout",
+		}),
+		assertionsCreated: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "validator",
+			Name:      "assertions_created_total",
+			Help:      "Number of assertions created",
+		}),
+		assertionsConfirmed: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "validator",
+			Name:      "assertions_confirmed_total",
+			Help:      "Number of assertions confirmed",
+		}),
+		assertionsRejected: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "validator",
+			Name:      "assertions_rejected_total",
+			Help:      "Number of assertions rejected",
+		}),
+		lastAssertionL2BlockNum: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: "validator",
+			Name:      "last_assertion_l2_block",
+			Help:      "L2 block number of the last created assertion",
+		}),
+	}
+}
+
+// Returns tx manager metrics for the given service, recorded in the same registry.
+func (m *Metrics) NewTxMetrics(service string) txmgr.TxMetricer {
+	return txMetrics.NewTxMetrics(m.registry, Namespace, service)
+}
+
+func (m *Metrics) RecordBatchBuilt(size int, numSubBatches int) {
+	m.batchesBuilt.Inc()
+	m.batchSize.Observe(float64(size))
+	m.batchNumSubBatches.Observe(float64(numSubBatches))
+}
+
+func (m *Metrics) RecordSafeLag(lag uint64) { m.safeLag.Set(float64(lag)) }
+
+func (m *Metrics) RecordHardTimeoutExceeded(numBlocks int) {
+	m.hardTimeouts.Inc()
+	m.hardTimeoutBlocks.Add(float64(numBlocks))
+}
+
+func (m *Metrics) RecordAssertionCreated(l2BlockNum uint64) {
+	m.assertionsCreated.Inc()
+	m.lastAssertionL2BlockNum.Set(float64(l2BlockNum))
+}
+
+func (m *Metrics) RecordAssertionConfirmed() { m.assertionsConfirmed.Inc() }
+func (m *Metrics) RecordAssertionRejected()  { m.assertionsRejected.Inc() }
+
+// Returns an HTTP handler exposing all recorded metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.InstrumentMetricHandler(m.registry, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// Serves metrics on `addr:port` until the context is cancelled.
+func (m *Metrics) Serve(ctx context.Context, addr string, port uint64) error {
+	var (
+		endpoint = net.JoinHostPort(addr, strconv.FormatUint(port, 10))
+		mux      = http.NewServeMux()
+	)
+	mux.Handle("/metrics", m.Handler())
+	server := &http.Server{Addr: endpoint, Handler: mux, ReadHeaderTimeout: readHeaderTimeout}
+	go func() {
+		<-ctx.Done()
+		sCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(sCtx); err != nil {
+			log.Error("Failed to shut down metrics server", "err", err)
+		}
+	}()
+	log.Info("Serving metrics", "endpoint", endpoint)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerServesRecordedMetrics(t *testing.T) {
+	m := NewMetrics()
+	m.RecordBatchBuilt(2048, 3)
+	m.RecordHardTimeoutExceeded(2)
+	m.RecordAssertionCreated(42)
+	m.NewTxMetrics("validator").RecordNonce(7)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	for _, line := range []string{
+		"specular_sidecar_disseminator_batches_built_total 1",
+		"specular_sidecar_disseminator_batch_size_bytes_sum 2048",
+		"specular_sidecar_disseminator_hard_timeout_blocks_total 2",
+		"specular_sidecar_validator_assertions_created_total 1",
+		"specular_sidecar_validator_last_assertion_l2_block 42",
+		`specular_sidecar_txmgr_nonce{service="validator"} 7`,
+	} {
+		require.Contains(t, string(body), line)
+	}
+}
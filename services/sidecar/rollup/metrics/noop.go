@@ -0,0 +1,18 @@
+package metrics
+
+import (
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
+	txMetrics "github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr/metrics"
+)
+
+// NoopMetrics discards all metrics (used when metrics are disabled).
+type NoopMetrics struct{}
+
+func (*NoopMetrics) NewTxMetrics(string) txmgr.TxMetricer { return &txMetrics.NoopTxMetrics{} }
+
+func (*NoopMetrics) RecordBatchBuilt(int, int)     {}
+func (*NoopMetrics) RecordSafeLag(uint64)          {}
+func (*NoopMetrics) RecordHardTimeoutExceeded(int) {}
+func (*NoopMetrics) RecordAssertionCreated(uint64) {}
+func (*NoopMetrics) RecordAssertionConfirmed()     {}
+func (*NoopMetrics) RecordAssertionRejected()      {}
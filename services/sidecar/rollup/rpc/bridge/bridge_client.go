@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"strings"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/specularL2/specular/bindings-go/bindings"
@@ -16,6 +20,37 @@ import (
 type BridgeClient struct {
 	*bindings.ISequencerInbox
 	*bindings.IRollup
+	backend BridgeBackend // Used to bind challenge contracts (deployed per challenge).
+}
+
+type BridgeBackend interface {
+	bind.ContractBackend
+	TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error)
+}
+
+// A challenge between two sibling assertions.
+type ChallengeInfo struct {
+	Players      [2]common.Address // Defender, challenger.
+	AssertionIDs [2]*big.Int       // Defender's, challenger's.
+	// L1 block the challenge was created in; its events are all emitted from then on.
+	CreationBlock uint64
+}
+
+// Challenge protocol implemented by a deployed challenge contract.
+// `IAsymChallenge` defines no moves yet, so asymmetric challenges can't be played and have no protocol.
+type ChallengeProtocol uint8
+
+const (
+	SymChallengeProtocol ChallengeProtocol = iota // `ISymChallenge`
+)
+
+func (p ChallengeProtocol) String() string {
+	switch p {
+	case SymChallengeProtocol:
+		return "symmetric"
+	default:
+		return "unknown"
+	}
 }
 
 type ProtocolConfig interface {
@@ -25,7 +60,7 @@ type ProtocolConfig interface {
 
 type UnsatisfiedCondition = string
 
-func NewBridgeClient(backend bind.ContractBackend, cfg ProtocolConfig) (*BridgeClient, error) {
+func NewBridgeClient(backend BridgeBackend, cfg ProtocolConfig) (*BridgeClient, error) {
 	inbox, err := bindings.NewISequencerInbox(cfg.GetSequencerInboxAddr(), backend)
 	if err != nil {
 		return nil, err
@@ -34,7 +69,7 @@ func NewBridgeClient(backend bind.ContractBackend, cfg ProtocolConfig) (*BridgeC
 	if err != nil {
 		return nil, err
 	}
-	return &BridgeClient{ISequencerInbox: inbox, IRollup: rollup}, nil
+	return &BridgeClient{ISequencerInbox: inbox, IRollup: rollup, backend: backend}, nil
 }
 
 func (c *BridgeClient) RequireFirstUnresolvedAssertionIsConfirmable(ctx context.Context) (*UnsatisfiedCondition, error) {
@@ -59,6 +94,25 @@ func (c *BridgeClient) GetLastConfirmedAssertionID(ctx context.Context) (*big.In
 	return c.IRollup.GetLastConfirmedAssertionID(&bind.CallOpts{Pending: false, Context: ctx})
 }
 
+// Returns the AssertionCreated events emitted in the L1 block range [start, end], in order.
+func (c *BridgeClient) GetAssertionCreatedEvents(
+	ctx context.Context, start, end uint64,
+) ([]*bindings.IRollupAssertionCreated, error) {
+	iter, err := c.IRollup.FilterAssertionCreated(&bind.FilterOpts{Start: start, End: &end, Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter AssertionCreated events: %w", err)
+	}
+	defer iter.Close()
+	var events []*bindings.IRollupAssertionCreated
+	for iter.Next() {
+		events = append(events, iter.Event)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate AssertionCreated events: %w", err)
+	}
+	return events, nil
+}
+
 func (c *BridgeClient) GetRequiredStakeAmount(ctx context.Context) (*big.Int, error) {
 	return c.IRollup.CurrentRequiredStake(&bind.CallOpts{Pending: false, Context: ctx})
 }
@@ -67,6 +121,165 @@ func (c *BridgeClient) IsStakedOnAssertion(ctx context.Context, assertionID *big
 	return c.IRollup.IsStakedOnAssertion(&bind.CallOpts{Pending: false, Context: ctx}, assertionID, address)
 }
 
+// IChallenge
+
+func (c *BridgeClient) GetChallengeResponder(ctx context.Context, challengeAddr common.Address) (common.Address, error) {
+	challenge, err := bindings.NewIChallengeCaller(challengeAddr, c.backend)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return challenge.CurrentResponder(&bind.CallOpts{Pending: false, Context: ctx})
+}
+
+// Returns true if the current responder's deadline has passed, i.e. the challenge can be timed out.
+func (c *BridgeClient) IsChallengeTimedOut(ctx context.Context, challengeAddr common.Address) (bool, error) {
+	challenge, err := bindings.NewIChallengeCaller(challengeAddr, c.backend)
+	if err != nil {
+		return false, err
+	}
+	raw := &bindings.IChallengeCallerRaw{Contract: challenge}
+	err = raw.Call(&bind.CallOpts{Pending: false, Context: ctx}, nil, TimeoutFnName)
+	if err == nil {
+		return true, nil
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		unpackedErr, err := UnpackChallengeError(dataErr)
+		if err != nil {
+			return false, fmt.Errorf("failed to unpack challenge error: %w", err)
+		}
+		if unpackedErr.Name == DeadlineNotPassedErr {
+			return false, nil
+		}
+		return false, fmt.Errorf("unexpected challenge error: %s", unpackedErr.Name)
+	}
+	return false, fmt.Errorf("failed call with unknown error: %w", err)
+}
+
+// Returns the L1 block number after which the current responder can be timed out.
+func (c *BridgeClient) GetChallengeDeadline(ctx context.Context, challengeAddr common.Address) (uint64, error) {
+	challenge, err := bindings.NewIChallengeCaller(challengeAddr, c.backend)
+	if err != nil {
+		return 0, err
+	}
+	timeLeft, err := challenge.CurrentResponderTimeLeft(&bind.CallOpts{Pending: false, Context: ctx})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get responder time left: %w", err)
+	}
+	out, err := c.callChallengeGetter(ctx, challengeAddr, LastMoveBlockFnSig)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last move block: %w", err)
+	}
+	return new(big.Int).SetBytes(out).Uint64() + timeLeft.Uint64(), nil
+}
+
+// Returns the protocol of the given challenge, based on the deployed contract type.
+// Only `SymChallenge` exposes `numSteps`; other challenge contracts (i.e. `AsymChallenge`) revert, and are unsupported.
+func (c *BridgeClient) GetChallengeProtocol(ctx context.Context, challengeAddr common.Address) (ChallengeProtocol, error) {
+	code, err := c.backend.CodeAt(ctx, challengeAddr, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get challenge code: %w", err)
+	}
+	if len(code) == 0 {
+		return 0, fmt.Errorf("no challenge contract at %s", challengeAddr)
+	}
+	_, err = c.callChallengeGetter(ctx, challengeAddr, NumStepsFnSig)
+	if err == nil {
+		return SymChallengeProtocol, nil
+	}
+	if isRevert(err) {
+		return 0, fmt.Errorf("unsupported challenge protocol at %s (not an ISymChallenge)", challengeAddr)
+	}
+	return 0, fmt.Errorf("failed to probe challenge protocol: %w", err)
+}
+
+// Returns the players and assertions of the given challenge, as passed to `challengeAssertion`.
+// Only the challenge's creation block is searched for its `AssertionChallenged` event.
+func (c *BridgeClient) GetChallengeInfo(ctx context.Context, challengeAddr common.Address) (*ChallengeInfo, error) {
+	out, err := c.callChallengeGetter(ctx, challengeAddr, CreationBlockFnSig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get challenge creation block: %w", err)
+	}
+	creationBlock := new(big.Int).SetBytes(out).Uint64()
+	iter, err := c.IRollup.FilterAssertionChallenged(&bind.FilterOpts{Start: creationBlock, End: &creationBlock, Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter AssertionChallenged events: %w", err)
+	}
+	defer iter.Close()
+	for iter.Next() {
+		if iter.Event.ChallengeAddr != challengeAddr {
+			continue
+		}
+		tx, _, err := c.backend.TransactionByHash(ctx, iter.Event.Raw.TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get challenge tx: %w", err)
+		}
+		players, assertionIDs, err := UnpackChallengeAssertionInput(tx)
+		if err != nil {
+			return nil, err
+		}
+		return &ChallengeInfo{Players: players, AssertionIDs: assertionIDs, CreationBlock: creationBlock}, nil
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate AssertionChallenged events: %w", err)
+	}
+	return nil, fmt.Errorf("challenge %s not found (creation block %d)", challengeAddr, creationBlock)
+}
+
+// Returns the Bisected events emitted by the given (symmetric) challenge so far, in order.
+// `fromBlock` is the challenge's creation block (see `ChallengeInfo`).
+func (c *BridgeClient) GetBisectedEvents(
+	ctx context.Context, challengeAddr common.Address, fromBlock uint64,
+) ([]*bindings.ISymChallengeBisected, error) {
+	challenge, err := bindings.NewISymChallengeFilterer(challengeAddr, c.backend)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := challenge.FilterBisected(&bind.FilterOpts{Start: fromBlock, Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter Bisected events: %w", err)
+	}
+	defer iter.Close()
+	var events []*bindings.ISymChallengeBisected
+	for iter.Next() {
+		events = append(events, iter.Event)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("failed to iterate Bisected events: %w", err)
+	}
+	return events, nil
+}
+
+// Returns the bisection submitted in the `bisectExecution` tx that emitted the given event.
+func (c *BridgeClient) GetBisection(ctx context.Context, event *bindings.ISymChallengeBisected) ([][32]byte, error) {
+	tx, _, err := c.backend.TransactionByHash(ctx, event.Raw.TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bisection tx: %w", err)
+	}
+	return UnpackBisectExecutionBisection(tx)
+}
+
+// Calls a getter of a challenge contract by signature, returning the raw output.
+func (c *BridgeClient) callChallengeGetter(ctx context.Context, challengeAddr common.Address, sig string) ([]byte, error) {
+	return c.backend.CallContract(ctx, ethereum.CallMsg{To: &challengeAddr, Data: packGetterInput(sig)}, nil)
+}
+
+// Returns true if `err` (e.g. from sending a rollup tx) unwraps a revert with the given rollup error.
+func IsRollupError(err error, name string) bool {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return false
+	}
+	unpackedErr, err := UnpackRollupError(dataErr)
+	return err == nil && unpackedErr.Name == name
+}
+
+// Returns true if the error is due to the call reverting (with or without revert data).
+func isRevert(err error) bool {
+	var dataErr rpc.DataError
+	return errors.As(err, &dataErr) || strings.Contains(err.Error(), vm.ErrExecutionReverted.Error())
+}
+
 func processRollupError(err error) (*UnsatisfiedCondition, error) {
 	if err == nil {
 		return nil, nil
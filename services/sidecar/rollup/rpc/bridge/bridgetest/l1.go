@@ -0,0 +1,183 @@
+// Package bridgetest provides a simulated L1 with a sequencer inbox, to test batch submission and derivation
+// against a real chain (with real receipts and reverts) rather than stubs.
+package bridgetest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/asm"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr/metrics"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+const gasLimit = 30_000_000
+
+var (
+	InboxAddr  = common.HexToAddress("0x0000000000000000000000000000000000001b0c")
+	RollupAddr = common.HexToAddress("0x000000000000000000000000000000000000a11e")
+)
+
+// Runtime bytecode of a minimal sequencer inbox, mirroring the checks `appendTxBatch` makes in
+// contracts/src/SequencerInbox.sol (which can't be compiled as part of the Go build): only `sequencer` may append,
+// and the batch data must be non-empty and start with a version up to 1, or be the blob marker (0xb0) alone.
+// Accepted batches emit `TxBatchAppended`; anything else reverts.
+const inboxAsm = `
+	PUSH 0
+	CALLDATALOAD
+	PUSH 0xe0
+	SHR
+	PUSH %#x
+	EQ
+	JUMPI @append
+	JUMP @fail
+append:
+	CALLER
+	PUSH %#x
+	EQ
+	ISZERO
+	JUMPI @fail
+	PUSH 4
+	CALLDATALOAD
+	PUSH 4
+	ADD
+	DUP1
+	CALLDATALOAD
+	DUP1
+	ISZERO
+	JUMPI @fail
+	SWAP1
+	PUSH 32
+	ADD
+	CALLDATALOAD
+	PUSH 0xf8
+	SHR
+	DUP1
+	PUSH 2
+	GT
+	JUMPI @ok
+	PUSH 0xb0
+	EQ
+	SWAP1
+	PUSH 1
+	EQ
+	AND
+	JUMPI @ok
+fail:
+	PUSH 0
+	DUP1
+	REVERT
+ok:
+	PUSH %#x
+	PUSH 0
+	DUP1
+	LOG1
+	STOP
+`
+
+func InboxCode(sequencer common.Address) ([]byte, error) {
+	inboxAbi, err := bindings.ISequencerInboxMetaData.GetAbi()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox abi: %w", err)
+	}
+	var (
+		selector = inboxAbi.Methods[bridge.AppendTxBatchFnName].ID
+		topic    = inboxAbi.Events[bridge.TxBatchAppendedEventName].ID
+		src      = fmt.Sprintf(inboxAsm, selector, sequencer.Bytes(), topic.Bytes())
+		compiler = asm.NewCompiler(false)
+	)
+	compiler.Feed(asm.Lex([]byte(src), false))
+	code, errs := compiler.Compile()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to compile inbox: %w", errors.Join(errs...))
+	}
+	return hexutil.Decode("0x" + code)
+}
+
+// L1 is a simulated L1 chain, with a sequencer inbox at `InboxAddr`, that mines a block for each tx sent.
+// Blobs are unsupported (the simulated chain can't activate Cancun).
+type L1 struct {
+	*backends.SimulatedBackend
+	Sequencer *ecdsa.PrivateKey
+}
+
+func NewL1(t testing.TB) *L1 {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sequencer := crypto.PubkeyToAddress(key.PublicKey)
+	code, err := InboxCode(sequencer)
+	require.NoError(t, err)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		sequencer: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		InboxAddr: {Code: code, Balance: new(big.Int)},
+	}, gasLimit)
+	t.Cleanup(func() { backend.Close() })
+	return &L1{backend, key}
+}
+
+func (l *L1) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := l.SimulatedBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	l.Commit()
+	return nil
+}
+
+func (l *L1) BlockNumber(ctx context.Context) (uint64, error) {
+	header, err := l.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+func (l *L1) FeeHistory(context.Context, uint64, *big.Int, []float64) (*ethereum.FeeHistory, error) {
+	return nil, errors.New("fee history unsupported by simulated backend")
+}
+
+func (l *L1) ChainID() *big.Int { return l.Blockchain().Config().ChainID }
+
+func (l *L1) GetSequencerInboxAddr() common.Address { return InboxAddr }
+func (l *L1) GetRollupAddr() common.Address         { return RollupAddr }
+
+// Returns a tx manager sending txs to the inbox as the sequencer.
+func (l *L1) NewTxManager(t testing.TB) *bridge.TxManager {
+	var (
+		signer = types.LatestSignerForChainID(l.ChainID())
+		cfg    = txmgr.Config{
+			ChainID:                   l.ChainID(),
+			NetworkTimeout:            time.Second,
+			FeeLimitMultiplier:        5,
+			ResubmissionTimeout:       time.Minute,
+			ReceiptQueryInterval:      10 * time.Millisecond,
+			TxNotInMempoolTimeout:     time.Minute,
+			NumConfirmations:          1,
+			SafeAbortNonceTooLowCount: 3,
+			FeeStrategy:               txmgr.NodeFeeStrategy,
+			From:                      crypto.PubkeyToAddress(l.Sequencer.PublicKey),
+		}
+		signerFn = func(_ context.Context, _ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return types.SignTx(tx, signer, l.Sequencer)
+		}
+	)
+	txMgr, err := bridge.NewTxManager(txmgr.NewTxManager(log.Root(), cfg, l, signerFn, &metrics.NoopTxMetrics{}), l)
+	require.NoError(t, err)
+	return txMgr
+}
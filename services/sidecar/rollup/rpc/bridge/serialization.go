@@ -1,12 +1,14 @@
 package bridge
 
 import (
+	"bytes"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/specularL2/specular/bindings-go/bindings"
@@ -24,13 +26,24 @@ const (
 	ConfirmFirstUnresolvedAssertionFnName = "confirmFirstUnresolvedAssertion"
 	RejectFirstUnresolvedAssertionFnName  = "rejectFirstUnresolvedAssertion"
 	packRemoveStakeFnName                 = "removeStake"
+	ChallengeAssertionFnName              = "challengeAssertion"
 	// IChallenge.sol functions
-	// bisectExecutionFn = "bisectExecution"
+	TimeoutFnName                   = "timeout"
+	InitializeChallengeLengthFnName = "initializeChallengeLength"
+	BisectExecutionFnName           = "bisectExecution"
+	VerifyOneStepProofFnName        = "verifyOneStepProof"
 	// IRollup.sol errors (TODO: figure out a work-around to hardcoding)
 	NoUnresolvedAssertionErr     = "NoUnresolvedAssertion"
 	ConfirmationPeriodPendingErr = "ConfirmationPeriodPending"
 	InvalidParentErr             = "InvalidParent"
 	NotAllStakedErr              = "NotAllStaked"
+	MismatchingL1BlockhashesErr  = "MismatchingL1Blockhashes"
+	// IChallenge.sol errors
+	DeadlineNotPassedErr = "DeadlineNotPassed"
+	// ChallengeBase.sol and SymChallenge.sol getters (not part of any interface)
+	LastMoveBlockFnSig = "lastMoveBlock()"
+	CreationBlockFnSig = "creationBlock()"
+	NumStepsFnSig      = "numSteps()"
 	// L1Oracle.sol functions
 	SetL1OracleValues = "setL1OracleValues"
 
@@ -55,6 +68,26 @@ func UnpackAppendTxBatchInput(tx *types.Transaction) ([]any, error) {
 	return serializationUtil.inboxAbi.Methods[AppendTxBatchFnName].Inputs.Unpack(tx.Data()[MethodNumBytes:])
 }
 
+// Returns the batch data passed to `appendTxBatch`, if `tx` is a call to it.
+// Returns an error otherwise.
+func UnpackAppendTxBatchData(tx *types.Transaction) ([]byte, error) {
+	if err := ensureUtilInit(); err != nil {
+		return nil, err
+	}
+	var (
+		data   = tx.Data()
+		method = serializationUtil.inboxAbi.Methods[AppendTxBatchFnName]
+	)
+	if len(data) < MethodNumBytes || !bytes.Equal(data[:MethodNumBytes], method.ID) {
+		return nil, fmt.Errorf("tx %s is not an %s call", tx.Hash(), AppendTxBatchFnName)
+	}
+	in, err := method.Inputs.Unpack(data[MethodNumBytes:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s input: %w", AppendTxBatchFnName, err)
+	}
+	return in[0].([]byte), nil
+}
+
 func packAppendTxBatchInput(batch []byte) ([]byte, error) {
 	return serializationUtil.inboxAbi.Pack(AppendTxBatchFnName, batch)
 }
@@ -72,8 +105,14 @@ func UnpackCreateAssertionInput(tx *types.Transaction) (common.Hash, *big.Int, e
 }
 
 func UnpackRollupError(dataErr rpc.DataError) (*abi.Error, error) {
-	reason := dataErr.ErrorData()
-	data, err := hexutil.Decode(reason.(string))
+	if err := ensureUtilInit(); err != nil {
+		return nil, err
+	}
+	reason, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected rollup error data: %v", dataErr.ErrorData())
+	}
+	data, err := hexutil.Decode(reason)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode rollup error: %w", err)
 	}
@@ -82,6 +121,10 @@ func UnpackRollupError(dataErr rpc.DataError) (*abi.Error, error) {
 	return serializationUtil.rollupAbi.ErrorByID(id)
 }
 
+func packChallengeAssertionInput(players [2]common.Address, assertionIDs [2]*big.Int) ([]byte, error) {
+	return serializationUtil.rollupAbi.Pack(ChallengeAssertionFnName, players, assertionIDs)
+}
+
 func packStakeInput() ([]byte, error) {
 	return serializationUtil.rollupAbi.Pack(StakeFnName)
 }
@@ -106,9 +149,122 @@ func packRemoveStakeInput(stakerAddress common.Address) ([]byte, error) {
 	return serializationUtil.rollupAbi.Pack(packRemoveStakeFnName, stakerAddress)
 }
 
+// IChallenge.sol
+
+func UnpackChallengeError(dataErr rpc.DataError) (*abi.Error, error) {
+	if err := ensureUtilInit(); err != nil {
+		return nil, err
+	}
+	reason, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected challenge error data: %v", dataErr.ErrorData())
+	}
+	data, err := hexutil.Decode(reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode challenge error: %w", err)
+	}
+	var id [4]byte
+	copy(id[:], data)
+	return serializationUtil.challengeAbi.ErrorByID(id)
+}
+
+// Returns the bisection passed to `bisectExecution`, if `tx` is a call to it.
+// Returns an error otherwise.
+func UnpackBisectExecutionBisection(tx *types.Transaction) ([][32]byte, error) {
+	if err := ensureUtilInit(); err != nil {
+		return nil, err
+	}
+	var (
+		data   = tx.Data()
+		method = serializationUtil.challengeAbi.Methods[BisectExecutionFnName]
+	)
+	if len(data) < MethodNumBytes || !bytes.Equal(data[:MethodNumBytes], method.ID) {
+		return nil, fmt.Errorf("tx %s is not a %s call", tx.Hash(), BisectExecutionFnName)
+	}
+	in, err := method.Inputs.Unpack(data[MethodNumBytes:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s input: %w", BisectExecutionFnName, err)
+	}
+	return in[0].([][32]byte), nil
+}
+
+// Returns the input for calling a getter by signature, for public state not exposed by the bindings.
+func packGetterInput(sig string) []byte {
+	return crypto.Keccak256([]byte(sig))[:MethodNumBytes]
+}
+
+func packTimeoutInput() ([]byte, error) {
+	return serializationUtil.challengeAbi.Pack(TimeoutFnName)
+}
+
+func packInitializeChallengeLengthInput(numSteps *big.Int) ([]byte, error) {
+	return serializationUtil.challengeAbi.Pack(InitializeChallengeLengthFnName, numSteps)
+}
+
+func packBisectExecutionInput(
+	bisection [][32]byte,
+	challengedSegmentIndex *big.Int,
+	prevBisection [][32]byte,
+	prevChallengedSegmentStart *big.Int,
+	prevChallengedSegmentLength *big.Int,
+) ([]byte, error) {
+	return serializationUtil.challengeAbi.Pack(
+		BisectExecutionFnName,
+		bisection,
+		challengedSegmentIndex,
+		prevBisection,
+		prevChallengedSegmentStart,
+		prevChallengedSegmentLength,
+	)
+}
+
+func packVerifyOneStepProofInput(
+	oneStepProof []byte,
+	txInclusionProof []byte,
+	vCtx bindings.VerificationContextLibRawContext,
+	challengedStepIndex *big.Int,
+	prevBisection [][32]byte,
+	prevChallengedSegmentStart *big.Int,
+	prevChallengedSegmentLength *big.Int,
+) ([]byte, error) {
+	return serializationUtil.challengeAbi.Pack(
+		VerifyOneStepProofFnName,
+		oneStepProof,
+		txInclusionProof,
+		vCtx,
+		challengedStepIndex,
+		prevBisection,
+		prevChallengedSegmentStart,
+		prevChallengedSegmentLength,
+	)
+}
+
+// Returns the players and assertion IDs passed to `challengeAssertion`, if `tx` is a call to it.
+// Returns an error otherwise.
+func UnpackChallengeAssertionInput(tx *types.Transaction) ([2]common.Address, [2]*big.Int, error) {
+	if err := ensureUtilInit(); err != nil {
+		return [2]common.Address{}, [2]*big.Int{}, err
+	}
+	var (
+		data   = tx.Data()
+		method = serializationUtil.rollupAbi.Methods[ChallengeAssertionFnName]
+	)
+	if len(data) < MethodNumBytes || !bytes.Equal(data[:MethodNumBytes], method.ID) {
+		return [2]common.Address{}, [2]*big.Int{}, fmt.Errorf("tx %s is not a %s call", tx.Hash(), ChallengeAssertionFnName)
+	}
+	in, err := method.Inputs.Unpack(data[MethodNumBytes:])
+	if err != nil {
+		return [2]common.Address{}, [2]*big.Int{}, fmt.Errorf("failed to unpack %s input: %w", ChallengeAssertionFnName, err)
+	}
+	return in[0].([2]common.Address), in[1].([2]*big.Int), nil
+}
+
 // L1Oracle.sol
 
 func UnpackL1OracleInput(tx *types.Transaction) (uint64, uint64, uint64, common.Hash, common.Hash, error) {
+	if err := ensureUtilInit(); err != nil {
+		return 0, 0, 0, common.Hash{}, common.Hash{}, err
+	}
 	in, err := serializationUtil.l1OracleAbi.Methods[SetL1OracleValues].Inputs.Unpack(tx.Data()[MethodNumBytes:])
 	if err != nil {
 		return 0, 0, 0, common.Hash{}, common.Hash{}, err
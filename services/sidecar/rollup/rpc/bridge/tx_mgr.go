@@ -6,7 +6,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 
+	"github.com/specularL2/specular/bindings-go/bindings"
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
 )
 
@@ -18,6 +20,10 @@ type TxManager struct {
 
 type EthTxManager interface {
 	Send(ctx context.Context, candidate txmgr.TxCandidate) (*types.Receipt, error)
+	SendAsync(ctx context.Context, candidate txmgr.TxCandidate) (*types.Transaction, <-chan txmgr.SendResult, error)
+	Recover(ctx context.Context) error
+	CancelPending(ctx context.Context) error
+	ResetNonce()
 }
 
 type bridgeConfig interface {
@@ -44,6 +50,47 @@ func (m *TxManager) AppendTxBatch(
 	return m.Send(ctx, txmgr.TxCandidate{TxData: data, To: &addr})
 }
 
+// Appends a batch whose data is carried in `blobs`, passing `batch` as the calldata payload.
+func (m *TxManager) AppendTxBatchWithBlobs(
+	ctx context.Context,
+	batch []byte,
+	blobs []kzg4844.Blob,
+) (*types.Receipt, error) {
+	data, err := packAppendTxBatchInput(batch)
+	if err != nil {
+		return nil, err
+	}
+	addr := m.cfg.GetSequencerInboxAddr()
+	return m.Send(ctx, txmgr.TxCandidate{TxData: data, To: &addr, Blobs: blobs})
+}
+
+// Like AppendTxBatch, but returns as soon as the tx is signed (see `txmgr.TxManager.SendAsync`).
+func (m *TxManager) AppendTxBatchAsync(
+	ctx context.Context,
+	batch []byte,
+) (*types.Transaction, <-chan txmgr.SendResult, error) {
+	data, err := packAppendTxBatchInput(batch)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr := m.cfg.GetSequencerInboxAddr()
+	return m.SendAsync(ctx, txmgr.TxCandidate{TxData: data, To: &addr})
+}
+
+// Like AppendTxBatchWithBlobs, but returns as soon as the tx is signed (see `txmgr.TxManager.SendAsync`).
+func (m *TxManager) AppendTxBatchWithBlobsAsync(
+	ctx context.Context,
+	batch []byte,
+	blobs []kzg4844.Blob,
+) (*types.Transaction, <-chan txmgr.SendResult, error) {
+	data, err := packAppendTxBatchInput(batch)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr := m.cfg.GetSequencerInboxAddr()
+	return m.SendAsync(ctx, txmgr.TxCandidate{TxData: data, To: &addr, Blobs: blobs})
+}
+
 // IRollup
 
 func (m *TxManager) Stake(ctx context.Context, stakeAmount *big.Int) (*types.Receipt, error) {
@@ -100,6 +147,84 @@ func (m *TxManager) RemoveStake(ctx context.Context, stakerAddress common.Addres
 	return m.sendRollupTx(ctx, data, 0)
 }
 
+func (m *TxManager) ChallengeAssertion(
+	ctx context.Context,
+	players [2]common.Address,
+	assertionIDs [2]*big.Int,
+) (*types.Receipt, error) {
+	data, err := packChallengeAssertionInput(players, assertionIDs)
+	if err != nil {
+		return nil, err
+	}
+	return m.sendRollupTx(ctx, data, 0)
+}
+
+// IChallenge
+
+func (m *TxManager) Timeout(ctx context.Context, challengeAddr common.Address) (*types.Receipt, error) {
+	data, err := packTimeoutInput()
+	if err != nil {
+		return nil, err
+	}
+	return m.Send(ctx, txmgr.TxCandidate{TxData: data, To: &challengeAddr})
+}
+
+func (m *TxManager) InitializeChallengeLength(
+	ctx context.Context,
+	challengeAddr common.Address,
+	numSteps *big.Int,
+) (*types.Receipt, error) {
+	data, err := packInitializeChallengeLengthInput(numSteps)
+	if err != nil {
+		return nil, err
+	}
+	return m.Send(ctx, txmgr.TxCandidate{TxData: data, To: &challengeAddr})
+}
+
+func (m *TxManager) BisectExecution(
+	ctx context.Context,
+	challengeAddr common.Address,
+	bisection [][32]byte,
+	challengedSegmentIndex *big.Int,
+	prevBisection [][32]byte,
+	prevChallengedSegmentStart *big.Int,
+	prevChallengedSegmentLength *big.Int,
+) (*types.Receipt, error) {
+	data, err := packBisectExecutionInput(
+		bisection, challengedSegmentIndex, prevBisection, prevChallengedSegmentStart, prevChallengedSegmentLength,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m.Send(ctx, txmgr.TxCandidate{TxData: data, To: &challengeAddr})
+}
+
+func (m *TxManager) VerifyOneStepProof(
+	ctx context.Context,
+	challengeAddr common.Address,
+	oneStepProof []byte,
+	txInclusionProof []byte,
+	vCtx bindings.VerificationContextLibRawContext,
+	challengedStepIndex *big.Int,
+	prevBisection [][32]byte,
+	prevChallengedSegmentStart *big.Int,
+	prevChallengedSegmentLength *big.Int,
+) (*types.Receipt, error) {
+	data, err := packVerifyOneStepProofInput(
+		oneStepProof,
+		txInclusionProof,
+		vCtx,
+		challengedStepIndex,
+		prevBisection,
+		prevChallengedSegmentStart,
+		prevChallengedSegmentLength,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return m.Send(ctx, txmgr.TxCandidate{TxData: data, To: &challengeAddr})
+}
+
 func (m *TxManager) sendRollupTx(ctx context.Context, data []byte, value uint64) (*types.Receipt, error) {
 	addr := m.cfg.GetRollupAddr()
 	return m.Send(ctx, txmgr.TxCandidate{TxData: data, To: &addr, Value: big.NewInt(0).SetUint64(value)})
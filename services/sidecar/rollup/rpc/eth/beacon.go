@@ -0,0 +1,149 @@
+package eth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+// BeaconClient fetches blobs from a beacon node (via the standard beacon API), since execution clients don't serve
+// them. Blobs are only retained by beacon nodes for ~18 days, unless they're archival.
+type BeaconClient struct {
+	endpoint string
+	client   *http.Client
+
+	mu             sync.Mutex
+	genesisTime    uint64
+	secondsPerSlot uint64
+}
+
+func NewBeaconClient(endpoint string) *BeaconClient {
+	return &BeaconClient{endpoint: strings.TrimRight(endpoint, "/"), client: http.DefaultClient}
+}
+
+type beaconResponse[T any] struct {
+	Data T `json:"data"`
+}
+
+type beaconGenesis struct {
+	GenesisTime string `json:"genesis_time"`
+}
+
+type beaconSpec struct {
+	SecondsPerSlot string `json:"SECONDS_PER_SLOT"`
+}
+
+type BlobSidecar struct {
+	Index         string        `json:"index"`
+	Blob          hexutil.Bytes `json:"blob"`
+	KZGCommitment hexutil.Bytes `json:"kzg_commitment"`
+	KZGProof      hexutil.Bytes `json:"kzg_proof"`
+}
+
+// Returns the blobs with the given versioned hashes, carried by txs in the L1 block with the given header.
+// Each blob is checked against its KZG commitment.
+func (c *BeaconClient) GetBlobs(ctx context.Context, l1Header *types.Header, hashes []common.Hash) ([]kzg4844.Blob, error) {
+	slot, err := c.slotAt(ctx, l1Header.Time)
+	if err != nil {
+		return nil, err
+	}
+	var sidecars beaconResponse[[]BlobSidecar]
+	if err := c.get(ctx, fmt.Sprintf("/eth/v1/beacon/blob_sidecars/%d", slot), &sidecars); err != nil {
+		return nil, fmt.Errorf("failed to get blob sidecars (slot=%d): %w", slot, err)
+	}
+	found := make(map[common.Hash]*kzg4844.Blob, len(sidecars.Data))
+	for _, sidecar := range sidecars.Data {
+		if len(sidecar.Blob) != len(kzg4844.Blob{}) ||
+			len(sidecar.KZGCommitment) != len(kzg4844.Commitment{}) ||
+			len(sidecar.KZGProof) != len(kzg4844.Proof{}) {
+			return nil, fmt.Errorf("malformed blob sidecar (slot=%d, index=%s)", slot, sidecar.Index)
+		}
+		var (
+			blob       = new(kzg4844.Blob)
+			commitment kzg4844.Commitment
+			proof      kzg4844.Proof
+		)
+		copy(blob[:], sidecar.Blob)
+		copy(commitment[:], sidecar.KZGCommitment)
+		copy(proof[:], sidecar.KZGProof)
+		hash := BlobHash(&commitment)
+		if err := kzg4844.VerifyBlobProof(*blob, commitment, proof); err != nil {
+			return nil, fmt.Errorf("invalid blob (slot=%d, hash=%s): %w", slot, hash, err)
+		}
+		found[hash] = blob
+	}
+	blobs := make([]kzg4844.Blob, len(hashes))
+	for i, hash := range hashes {
+		blob, ok := found[hash]
+		if !ok {
+			return nil, fmt.Errorf("blob not found (slot=%d, hash=%s)", slot, hash)
+		}
+		blobs[i] = *blob
+	}
+	return blobs, nil
+}
+
+// Returns the versioned hash of a blob with the given commitment.
+func BlobHash(commitment *kzg4844.Commitment) common.Hash {
+	hash := sha256.Sum256(commitment[:])
+	hash[0] = params.BlobTxHashVersion
+	return hash
+}
+
+// Returns the slot of the beacon block whose execution payload has the given timestamp.
+func (c *BeaconClient) slotAt(ctx context.Context, timestamp uint64) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.secondsPerSlot == 0 {
+		var genesis beaconResponse[beaconGenesis]
+		if err := c.get(ctx, "/eth/v1/beacon/genesis", &genesis); err != nil {
+			return 0, fmt.Errorf("failed to get beacon genesis: %w", err)
+		}
+		var spec beaconResponse[beaconSpec]
+		if err := c.get(ctx, "/eth/v1/config/spec", &spec); err != nil {
+			return 0, fmt.Errorf("failed to get beacon spec: %w", err)
+		}
+		genesisTime, err := strconv.ParseUint(genesis.Data.GenesisTime, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid beacon genesis time: %w", err)
+		}
+		secondsPerSlot, err := strconv.ParseUint(spec.Data.SecondsPerSlot, 10, 64)
+		if err != nil || secondsPerSlot == 0 {
+			return 0, fmt.Errorf("invalid beacon seconds per slot: %q", spec.Data.SecondsPerSlot)
+		}
+		c.genesisTime, c.secondsPerSlot = genesisTime, secondsPerSlot
+	}
+	if timestamp < c.genesisTime {
+		return 0, fmt.Errorf("timestamp %d precedes beacon genesis (%d)", timestamp, c.genesisTime)
+	}
+	return (timestamp - c.genesisTime) / c.secondsPerSlot, nil
+}
+
+func (c *BeaconClient) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/avast/retry-go/v4"
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -74,6 +75,24 @@ func (c *EthClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 	return gasTipCap, nil
 }
 
+// Returns the storage value of `account` at `key` in the given block.
+func (c *EthClient) StorageAtHash(
+	ctx context.Context, account common.Address, key common.Hash, blockHash common.Hash,
+) ([]byte, error) {
+	var result hexutil.Bytes
+	err := c.C.CallContext(ctx, &result, "eth_getStorageAt", account, key, rpc.BlockNumberOrHashWithHash(blockHash, false))
+	return result, err
+}
+
+// Returns the storage root of `account` in the given block, via `eth_getProof`.
+func (c *EthClient) StorageRoot(ctx context.Context, account common.Address, blockHash common.Hash) (common.Hash, error) {
+	var result struct {
+		StorageHash common.Hash `json:"storageHash"`
+	}
+	err := c.C.CallContext(ctx, &result, "eth_getProof", account, []string{}, rpc.BlockNumberOrHashWithHash(blockHash, false))
+	return result.StorageHash, err
+}
+
 func (c *EthClient) TxPoolStatus(ctx context.Context) (map[string]hexutil.Uint, error) {
 	var status map[string]hexutil.Uint
 	err := c.C.CallContext(ctx, &status, "txpool_status")
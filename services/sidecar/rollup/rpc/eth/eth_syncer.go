@@ -43,11 +43,21 @@ func NewEthSyncer(handler OnNewHandler) *EthSyncer {
 	}
 }
 
-// Starts a subscription in a separate goroutine for each commitment level.
+// Starts a subscription in a separate goroutine for each commitment level, polling at L1 intervals.
 func (s *EthSyncer) Start(ctx context.Context, client SyncerEthClient) {
-	s.subscribeNewHead(ctx, client, Latest, s.LatestHeaderBroker, s.OnLatest, EthSlotInterval)
-	s.subscribeNewHead(ctx, client, Safe, s.SafeHeaderBroker, s.OnSafe, EthEpochInterval)
-	s.subscribeNewHead(ctx, client, Finalized, s.FinalizedHeaderBroker, s.OnFinalized, EthEpochInterval)
+	s.StartWithPollIntervals(ctx, client, EthSlotInterval, EthEpochInterval)
+}
+
+// Like Start, but polls for latest headers every `latestInterval`, and safe/finalized headers every `tipInterval`.
+func (s *EthSyncer) StartWithPollIntervals(
+	ctx context.Context,
+	client SyncerEthClient,
+	latestInterval time.Duration,
+	tipInterval time.Duration,
+) {
+	s.subscribeNewHead(ctx, client, Latest, s.LatestHeaderBroker, s.OnLatest, latestInterval)
+	s.subscribeNewHead(ctx, client, Safe, s.SafeHeaderBroker, s.OnSafe, tipInterval)
+	s.subscribeNewHead(ctx, client, Finalized, s.FinalizedHeaderBroker, s.OnFinalized, tipInterval)
 }
 
 func (s *EthSyncer) Stop(ctx context.Context) error {
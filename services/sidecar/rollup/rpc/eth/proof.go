@@ -0,0 +1,70 @@
+package eth
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/specularL2/specular/services/sidecar/proof"
+	"github.com/specularL2/specular/services/sidecar/proof/prover"
+)
+
+// Methods of the `proof` namespace (see `proof.ProverAPI`), served by EL nodes.
+
+// Returns the execution states across L2 blocks [start, end).
+func (c *EthClient) GenerateStates(ctx context.Context, start, end uint64) ([]proof.ExecutionStateRef, error) {
+	var states []proof.ExecutionStateRef
+	err := c.C.CallContext(ctx, &states, "proof_generateStates", start, end)
+	return states, err
+}
+
+// Returns the number of execution states across L2 blocks [start, end).
+func (c *EthClient) CountStates(ctx context.Context, start, end uint64) (uint64, error) {
+	var count uint64
+	err := c.C.CallContext(ctx, &count, "proof_countStates", start, end)
+	return count, err
+}
+
+// Returns the execution states at the given indices across L2 blocks [start, end).
+func (c *EthClient) GenerateStatesAt(
+	ctx context.Context, start, end uint64, indices []uint64,
+) ([]proof.ExecutionStateRef, error) {
+	var states []proof.ExecutionStateRef
+	err := c.C.CallContext(ctx, &states, "proof_generateStatesAt", start, end, indices)
+	return states, err
+}
+
+// Returns up to `limit` execution states across L2 blocks [start, end), starting at index `offset`.
+func (c *EthClient) GenerateStatesPage(
+	ctx context.Context, start, end uint64, offset, limit uint64,
+) ([]proof.ExecutionStateRef, error) {
+	var states []proof.ExecutionStateRef
+	err := c.C.CallContext(ctx, &states, "proof_generateStatesPage", start, end, offset, limit)
+	return states, err
+}
+
+// Returns the encoded one-step proof of the step starting from `state`.
+func (c *EthClient) GenerateProof(ctx context.Context, state proof.ExecutionStateRef) ([]byte, error) {
+	var osp hexutil.Bytes
+	err := c.C.CallContext(ctx, &osp, "proof_generateProof", state)
+	return osp, err
+}
+
+// Returns up to `limit` steps of the trace of the given transaction, starting at step `offset+1`.
+func (c *EthClient) DebugTransaction(
+	ctx context.Context, txHash common.Hash, offset, limit uint64,
+) ([]prover.TraceStep, error) {
+	var trace []prover.TraceStep
+	err := c.C.CallContext(ctx, &trace, "proof_debugTransaction", txHash, offset, limit)
+	return trace, err
+}
+
+// Returns the execution state at step `step` of transaction `txIdx` in the given block.
+func (c *EthClient) StateAt(
+	ctx context.Context, blockHash common.Hash, txIdx uint64, step uint64,
+) (*proof.ExecutionStateRef, error) {
+	var state *proof.ExecutionStateRef
+	err := c.C.CallContext(ctx, &state, "proof_stateAt", blockHash, txIdx, step)
+	return state, err
+}
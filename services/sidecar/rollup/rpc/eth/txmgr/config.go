@@ -6,7 +6,10 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/urfave/cli/v2"
+
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
 )
 
 type Config struct {
@@ -48,6 +51,27 @@ type Config struct {
 	// confirmation.
 	SafeAbortNonceTooLowCount uint64
 
+	// JournalPath is the path of the directory journaling txs being sent, so that they can be resumed (or
+	// cancelled) after a restart. If empty, the journal is only kept in memory.
+	JournalPath string
+
+	// FeeStrategy is how tips are estimated: `NodeFeeStrategy` (the default) or `FeeHistoryFeeStrategy` (opt-in).
+	FeeStrategy string
+
+	// FeeHistoryBlocks is the number of recent blocks whose tips are considered by `FeeHistoryFeeStrategy`.
+	FeeHistoryBlocks uint64
+
+	// FeeHistoryPercentile is the percentile of the tips paid in each block considered by `FeeHistoryFeeStrategy`.
+	FeeHistoryPercentile float64
+
+	// TargetInclusionBlocks is the number of full blocks a tx should survive before its fee cap falls below the
+	// basefee. If 0, the fee cap allows for the basefee to double.
+	TargetInclusionBlocks uint64
+
+	// MaxGasFeeCap is a hard ceiling on the gas fee cap (in wei) of any tx sent. While L1 fees exceed it, new txs
+	// wait and pending txs aren't bumped. If nil, there's no ceiling.
+	MaxGasFeeCap *big.Int
+
 	From common.Address
 }
 
@@ -76,6 +100,21 @@ func (m Config) Validate() error {
 	if m.ChainID == nil {
 		return errors.New("must provide the ChainID")
 	}
+	switch m.FeeStrategy {
+	case "", NodeFeeStrategy: // Defaults to `NodeFeeStrategy`.
+	case FeeHistoryFeeStrategy:
+		if m.FeeHistoryBlocks == 0 || m.FeeHistoryBlocks > maxFeeHistoryBlocks {
+			return fmt.Errorf("FeeHistoryBlocks must be in [1, %d]", maxFeeHistoryBlocks)
+		}
+		if m.FeeHistoryPercentile < 0 || m.FeeHistoryPercentile > 100 {
+			return errors.New("FeeHistoryPercentile must be in [0, 100]")
+		}
+	default:
+		return fmt.Errorf("unknown FeeStrategy: %q", m.FeeStrategy)
+	}
+	if m.TargetInclusionBlocks > maxTargetInclusionBlocks {
+		return fmt.Errorf("TargetInclusionBlocks must not exceed %d", maxTargetInclusionBlocks)
+	}
 	return nil
 }
 
@@ -88,6 +127,12 @@ const (
 	ReceiptQueryIntervalFlagName      = "receipt-query-interval"
 	NumConfirmationsFlagName          = "num-confirmations"
 	SafeAbortNonceTooLowCountFlagName = "safe-abort-nonce-too-low-count"
+	JournalPathFlagName               = "journal-path"
+	FeeStrategyFlagName               = "fee-strategy"
+	FeeHistoryBlocksFlagName          = "fee-history-blocks"
+	FeeHistoryPercentileFlagName      = "fee-history-percentile"
+	TargetInclusionBlocksFlagName     = "target-inclusion-blocks"
+	MaxGasFeeCapFlagName              = "max-fee-gwei"
 )
 
 type DefaultFlagValues struct {
@@ -99,6 +144,10 @@ type DefaultFlagValues struct {
 	TxSendTimeout             time.Duration
 	TxNotInMempoolTimeout     time.Duration
 	ReceiptQueryInterval      time.Duration
+	FeeStrategy               string
+	FeeHistoryBlocks          uint64
+	FeeHistoryPercentile      float64
+	TargetInclusionBlocks     uint64
 }
 
 var (
@@ -111,6 +160,12 @@ var (
 		TxSendTimeout:             0 * time.Second,
 		TxNotInMempoolTimeout:     2 * time.Minute,
 		ReceiptQueryInterval:      12 * time.Second,
+		FeeStrategy:               NodeFeeStrategy,
+		TargetInclusionBlocks:     0,
+		// Only used if the 'fee-history' strategy is opted into.
+		// Batches can wait (within the sequencing window), so don't overpay for them.
+		FeeHistoryBlocks:     20,
+		FeeHistoryPercentile: 40,
 	}
 	// TODO: tweak these values.
 	DefaultValidatorFlagValues = DefaultFlagValues{
@@ -122,6 +177,12 @@ var (
 		TxSendTimeout:             0 * time.Second,
 		TxNotInMempoolTimeout:     2 * time.Minute,
 		ReceiptQueryInterval:      12 * time.Second,
+		FeeStrategy:               NodeFeeStrategy,
+		TargetInclusionBlocks:     0,
+		// Only used if the 'fee-history' strategy is opted into.
+		// Assertions and challenge moves have deadlines, so pay for faster inclusion.
+		FeeHistoryBlocks:     20,
+		FeeHistoryPercentile: 60,
 	}
 )
 
@@ -167,6 +228,34 @@ func CLIFlags(namespace string, defaults DefaultFlagValues) []cli.Flag {
 			Usage: "Frequency to poll for receipts",
 			Value: defaults.ReceiptQueryInterval,
 		},
+		&cli.StringFlag{
+			Name:  namespace + "." + JournalPathFlagName,
+			Usage: "Path of the directory journaling pending txs, resumed or cancelled on restart. If empty, the journal isn't persisted.",
+		},
+		&cli.StringFlag{
+			Name:  namespace + "." + FeeStrategyFlagName,
+			Usage: "How tips are estimated: 'node' (eth_maxPriorityFeePerGas, the default) or 'fee-history' (eth_feeHistory percentile, opt-in)",
+			Value: defaults.FeeStrategy,
+		},
+		&cli.Uint64Flag{
+			Name:  namespace + "." + FeeHistoryBlocksFlagName,
+			Usage: "Number of recent blocks considered by the 'fee-history' strategy",
+			Value: defaults.FeeHistoryBlocks,
+		},
+		&cli.Float64Flag{
+			Name:  namespace + "." + FeeHistoryPercentileFlagName,
+			Usage: "Percentile of the tips paid in each block used by the 'fee-history' strategy",
+			Value: defaults.FeeHistoryPercentile,
+		},
+		&cli.Uint64Flag{
+			Name:  namespace + "." + TargetInclusionBlocksFlagName,
+			Usage: "Number of full blocks a tx's fee cap should cover. If 0, the fee cap covers a doubling of the basefee.",
+			Value: defaults.TargetInclusionBlocks,
+		},
+		&cli.Uint64Flag{
+			Name:  namespace + "." + MaxGasFeeCapFlagName,
+			Usage: "Hard ceiling on the gas fee cap (in gwei); txs wait while L1 fees exceed it. If 0, there's no ceiling.",
+		},
 	}
 }
 
@@ -186,6 +275,20 @@ func NewConfigFromCLI(
 		ReceiptQueryInterval:      cliCtx.Duration(namespace + "." + ReceiptQueryIntervalFlagName),
 		NumConfirmations:          cliCtx.Uint64(namespace + "." + NumConfirmationsFlagName),
 		SafeAbortNonceTooLowCount: cliCtx.Uint64(namespace + "." + SafeAbortNonceTooLowCountFlagName),
+		JournalPath:               cliCtx.String(namespace + "." + JournalPathFlagName),
+		FeeStrategy:               cliCtx.String(namespace + "." + FeeStrategyFlagName),
+		FeeHistoryBlocks:          cliCtx.Uint64(namespace + "." + FeeHistoryBlocksFlagName),
+		FeeHistoryPercentile:      cliCtx.Float64(namespace + "." + FeeHistoryPercentileFlagName),
+		TargetInclusionBlocks:     cliCtx.Uint64(namespace + "." + TargetInclusionBlocksFlagName),
+		MaxGasFeeCap:              gweiToWei(cliCtx.Uint64(namespace + "." + MaxGasFeeCapFlagName)),
 		From:                      from,
 	}
 }
+
+// Returns nil for zero.
+func gweiToWei(gwei uint64) *big.Int {
+	if gwei == 0 {
+		return nil
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(gwei), big.NewInt(params.GWei))
+}
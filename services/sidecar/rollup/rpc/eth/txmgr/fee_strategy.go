@@ -0,0 +1,97 @@
+package txmgr
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+const (
+	// Tips are suggested by the L1 node (i.e. `eth_maxPriorityFeePerGas`).
+	NodeFeeStrategy = "node"
+	// Tips are the median of a percentile of the tips paid in recent blocks (i.e. `eth_feeHistory`).
+	FeeHistoryFeeStrategy = "fee-history"
+
+	maxFeeHistoryBlocks      = 1024
+	maxTargetInclusionBlocks = 64
+)
+
+// FeeStrategy prices txs, given the latest L1 header.
+type FeeStrategy interface {
+	// Returns the gas tip cap to pay.
+	SuggestGasTipCap(ctx context.Context, head *types.Header) (*big.Int, error)
+	// Returns the gas fee cap to pay with the given tip, allowing for basefee increases until inclusion.
+	GasFeeCap(baseFee, tip *big.Int) *big.Int
+}
+
+func NewFeeStrategy(cfg Config, backend ETHBackend) FeeStrategy {
+	target := inclusionTarget(cfg.TargetInclusionBlocks)
+	switch cfg.FeeStrategy {
+	case FeeHistoryFeeStrategy:
+		return &feeHistoryStrategy{target, backend, cfg.FeeHistoryBlocks, cfg.FeeHistoryPercentile}
+	default:
+		return &nodeFeeStrategy{target, backend}
+	}
+}
+
+// inclusionTarget is the number of blocks within which a tx should remain includable, even if they're all full
+// (each raising the basefee by 12.5%). If zero, a tx remains includable until the basefee doubles.
+type inclusionTarget uint64
+
+func (t inclusionTarget) GasFeeCap(baseFee, tip *big.Int) *big.Int {
+	if t == 0 {
+		return calcGasFeeCap(baseFee, tip)
+	}
+	var (
+		maxBaseFee = new(big.Int).Set(baseFee)
+		nine       = big.NewInt(9)
+		seven      = big.NewInt(7)
+		eight      = big.NewInt(8)
+	)
+	for i := uint64(0); i < uint64(t); i++ {
+		// Rounded up, so that the basefee always increases.
+		maxBaseFee.Div(maxBaseFee.Add(maxBaseFee.Mul(maxBaseFee, nine), seven), eight)
+	}
+	return maxBaseFee.Add(maxBaseFee, tip)
+}
+
+type nodeFeeStrategy struct {
+	inclusionTarget
+	backend ETHBackend
+}
+
+func (s *nodeFeeStrategy) SuggestGasTipCap(ctx context.Context, _ *types.Header) (*big.Int, error) {
+	return s.backend.SuggestGasTipCap(ctx)
+}
+
+type feeHistoryStrategy struct {
+	inclusionTarget
+	backend    ETHBackend
+	numBlocks  uint64
+	percentile float64
+}
+
+// Returns the median (over the last `numBlocks` blocks) of the `percentile`-th tip paid in each block.
+// Empty blocks are ignored; if all are empty, the node's suggestion is used.
+func (s *feeHistoryStrategy) SuggestGasTipCap(ctx context.Context, head *types.Header) (*big.Int, error) {
+	history, err := s.backend.FeeHistory(ctx, s.numBlocks, head.Number, []float64{s.percentile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fee history: %w", err)
+	}
+	var tips []*big.Int
+	for i, rewards := range history.Reward {
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 || len(rewards) == 0 || rewards[0] == nil {
+			continue
+		}
+		tips = append(tips, rewards[0])
+	}
+	if len(tips) == 0 {
+		return s.backend.SuggestGasTipCap(ctx)
+	}
+	sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+	return new(big.Int).Set(tips[len(tips)/2]), nil
+}
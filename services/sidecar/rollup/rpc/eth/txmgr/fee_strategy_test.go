@@ -0,0 +1,127 @@
+package txmgr
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInclusionTargetGasFeeCap(t *testing.T) {
+	var (
+		baseFee = big.NewInt(800)
+		tip     = big.NewInt(10)
+	)
+	// Untargeted: tip + 2*basefee.
+	require.Equal(t, big.NewInt(1610), inclusionTarget(0).GasFeeCap(baseFee, tip))
+	// 800 -> 900 -> 1013 (rounded up).
+	require.Equal(t, big.NewInt(910), inclusionTarget(1).GasFeeCap(baseFee, tip))
+	require.Equal(t, big.NewInt(1023), inclusionTarget(2).GasFeeCap(baseFee, tip))
+	// The basefee isn't modified.
+	require.Equal(t, big.NewInt(800), baseFee)
+}
+
+func TestFeeHistoryStrategy(t *testing.T) {
+	var (
+		backend = &testBackend{feeHistory: &ethereum.FeeHistory{
+			Reward:       [][]*big.Int{{big.NewInt(3)}, {big.NewInt(0)}, {big.NewInt(7)}, {big.NewInt(5)}},
+			GasUsedRatio: []float64{0.5, 0, 0.9, 0.2},
+		}}
+		cfg      = Config{FeeStrategy: FeeHistoryFeeStrategy, FeeHistoryBlocks: 4, FeeHistoryPercentile: 50}
+		strategy = NewFeeStrategy(cfg, backend)
+		head     = &types.Header{Number: big.NewInt(1)}
+	)
+	// The empty block is ignored: median of [3, 5, 7].
+	tip, err := strategy.SuggestGasTipCap(context.Background(), head)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5), tip)
+
+	// Falls back to the node's suggestion if all blocks are empty.
+	backend.feeHistory = &ethereum.FeeHistory{Reward: [][]*big.Int{{big.NewInt(0)}}, GasUsedRatio: []float64{0}}
+	tip, err = strategy.SuggestGasTipCap(context.Background(), head)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(10), tip)
+}
+
+func TestConfigValidateFeeStrategy(t *testing.T) {
+	cfg := Config{
+		ChainID:                   big.NewInt(1),
+		NetworkTimeout:            time.Second,
+		FeeLimitMultiplier:        5,
+		ResubmissionTimeout:       time.Second,
+		ReceiptQueryInterval:      time.Second,
+		TxNotInMempoolTimeout:     time.Second,
+		NumConfirmations:          1,
+		SafeAbortNonceTooLowCount: 3,
+		FeeStrategy:               FeeHistoryFeeStrategy,
+		FeeHistoryBlocks:          20,
+		FeeHistoryPercentile:      50,
+	}
+	require.NoError(t, cfg.Validate())
+	cfg.FeeHistoryBlocks = 0
+	require.Error(t, cfg.Validate())
+	cfg.FeeHistoryBlocks, cfg.FeeHistoryPercentile = 20, 101
+	require.Error(t, cfg.Validate())
+	cfg.FeeStrategy = "unknown"
+	require.Error(t, cfg.Validate())
+}
+
+// feeBackend is a testBackend with a changeable basefee.
+type feeBackend struct {
+	testBackend
+	baseFee atomic.Int64
+}
+
+func (b *feeBackend) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(b.baseFee.Load())}, nil
+}
+
+func TestCraftTxMaxGasFeeCap(t *testing.T) {
+	var (
+		backend   = &feeBackend{}
+		m         = newTestTxManager(t, backend)
+		to        = common.HexToAddress("0x01")
+		candidate = TxCandidate{To: &to}
+	)
+	m.cfg.MaxGasFeeCap = big.NewInt(150)
+	// The fee cap (10 + 2*100) is clamped, since the tx is still includable.
+	backend.baseFee.Store(100)
+	tx, err := m.craftTx(context.Background(), candidate)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(150), tx.GasFeeCap())
+
+	// Bumps can't exceed the ceiling.
+	_, err = m.increaseGasPrice(context.Background(), tx)
+	require.ErrorIs(t, err, ErrMaxFeeExceeded)
+
+	backend.baseFee.Store(141)
+	_, err = m.craftTx(context.Background(), candidate)
+	require.ErrorIs(t, err, ErrMaxFeeExceeded)
+}
+
+func TestSendWaitsForFees(t *testing.T) {
+	var (
+		backend = &minedBackend{}
+		m       = newTestTxManager(t, backend)
+		to      = common.HexToAddress("0x01")
+	)
+	m.cfg.MaxGasFeeCap = big.NewInt(100)
+	m.cfg.ResubmissionTimeout = 10 * time.Millisecond
+	// The test backend's fees (10 + 100) exceed the ceiling.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := m.Send(ctx, TxCandidate{To: &to})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Empty(t, backend.sentNonces())
+
+	m.cfg.MaxGasFeeCap = big.NewInt(200)
+	_, err = m.Send(context.Background(), TxCandidate{To: &to})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{0}, backend.sentNonces())
+}
@@ -0,0 +1,191 @@
+package txmgr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+// Journal is a write-ahead log of the signed txs being sent by a `TxManager`, keyed by nonce.
+// A tx is recorded before it's published, so that txs still in the mempool after a restart can be resumed.
+type Journal interface {
+	// Returns the journaled txs, in nonce order.
+	Load() ([]*types.Transaction, error)
+	// Records a tx, replacing any previously recorded tx with the same nonce (e.g. when fee-bumped).
+	Record(tx *types.Transaction) error
+	// Removes the tx with the given nonce, if any.
+	Remove(nonce uint64) error
+	// Locks the journal for this process, until it exits. Fails with `ErrJournalLocked` if another process holds it.
+	Lock() error
+}
+
+// ErrJournalLocked is returned when locking a journal held by another process (e.g. a running sidecar).
+var ErrJournalLocked = errors.New("tx journal is locked by another process")
+
+// Stores each journaled tx in its own file (named by nonce) in a directory, written atomically, so that updating
+// one tx (e.g. a blob tx) doesn't rewrite the others.
+// If the path is empty, the journal is only kept in memory.
+type FileJournal struct {
+	path    string
+	txs     map[uint64]*types.Transaction
+	ensured bool     // Whether `txs` has been loaded from the directory.
+	lock    *os.File // Held open while locked.
+	mu      sync.Mutex
+}
+
+const (
+	journalFileExt  = ".tx"
+	journalLockFile = "LOCK"
+)
+
+func NewFileJournal(path string) *FileJournal {
+	return &FileJournal{path: path, txs: map[uint64]*types.Transaction{}}
+}
+
+func (j *FileJournal) Load() ([]*types.Transaction, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	txs := make([]*types.Transaction, 0, len(j.txs))
+	for _, tx := range j.txs {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, k int) bool { return txs[i].Nonce() < txs[k].Nonce() })
+	return txs, nil
+}
+
+func (j *FileJournal) Record(tx *types.Transaction) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.ensureLoaded(); err != nil {
+		return err
+	}
+	if prev, ok := j.txs[tx.Nonce()]; ok && prev.Hash() == tx.Hash() {
+		return nil
+	}
+	if err := j.write(tx); err != nil {
+		return err
+	}
+	j.txs[tx.Nonce()] = tx
+	return nil
+}
+
+func (j *FileJournal) Remove(nonce uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.ensureLoaded(); err != nil {
+		return err
+	}
+	if _, ok := j.txs[nonce]; !ok {
+		return nil
+	}
+	if j.path != "" {
+		if err := os.Remove(j.txPath(nonce)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove journaled tx (nonce=%d): %w", nonce, err)
+		}
+	}
+	delete(j.txs, nonce)
+	return nil
+}
+
+// Locks the journal directory with an advisory lock on its lock file, released by the OS when the process exits.
+// A journal only kept in memory can't be shared, so locking it is a no-op.
+func (j *FileJournal) Lock() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.path == "" || j.lock != nil {
+		return nil
+	}
+	if err := os.MkdirAll(j.path, 0o700); err != nil {
+		return fmt.Errorf("failed to create tx journal: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(j.path, journalLockFile), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open tx journal lock: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return ErrJournalLocked
+		}
+		return fmt.Errorf("failed to lock tx journal: %w", err)
+	}
+	j.lock = f
+	return nil
+}
+
+func (j *FileJournal) txPath(nonce uint64) string {
+	return filepath.Join(j.path, strconv.FormatUint(nonce, 10)+journalFileExt)
+}
+
+// Loads the journal directory (once), if any.
+func (j *FileJournal) ensureLoaded() error {
+	if j.ensured || j.path == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		j.ensured = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read tx journal: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, journalFileExt) {
+			continue
+		}
+		nonce, err := strconv.ParseUint(strings.TrimSuffix(name, journalFileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(j.path, name))
+		if err != nil {
+			return fmt.Errorf("failed to read journaled tx (nonce=%d): %w", nonce, err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("failed to decode journaled tx (nonce=%d): %w", nonce, err)
+		}
+		j.txs[nonce] = tx
+	}
+	j.ensured = true
+	return nil
+}
+
+// Writes the tx to its file, replacing any previous tx with the same nonce.
+func (j *FileJournal) write(tx *types.Transaction) error {
+	if j.path == "" {
+		return nil
+	}
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode tx (nonce=%d): %w", tx.Nonce(), err)
+	}
+	if err := os.MkdirAll(j.path, 0o700); err != nil {
+		return fmt.Errorf("failed to create tx journal: %w", err)
+	}
+	var (
+		path    = j.txPath(tx.Nonce())
+		tmpPath = path + ".tmp"
+	)
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write journaled tx (nonce=%d): %w", tx.Nonce(), err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace journaled tx (nonce=%d): %w", tx.Nonce(), err)
+	}
+	return nil
+}
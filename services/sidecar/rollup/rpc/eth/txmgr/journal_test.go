@@ -0,0 +1,60 @@
+package txmgr
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileJournal(t *testing.T) {
+	var (
+		path    = filepath.Join(t.TempDir(), "journal")
+		journal = NewFileJournal(path)
+		tx      = func(nonce uint64, tip int64) *types.Transaction {
+			return types.NewTx(&types.DynamicFeeTx{Nonce: nonce, GasTipCap: big.NewInt(tip), GasFeeCap: big.NewInt(tip)})
+		}
+		blobTx = types.NewTx(&types.BlobTx{
+			Nonce:   3,
+			Sidecar: &types.BlobTxSidecar{Blobs: make([]kzg4844.Blob, 1), Commitments: make([]kzg4844.Commitment, 1), Proofs: make([]kzg4844.Proof, 1)},
+			Value:   uint256.NewInt(0),
+		})
+	)
+	txs, err := journal.Load()
+	require.NoError(t, err)
+	require.Empty(t, txs)
+
+	// Replacements overwrite the tx with the same nonce.
+	require.NoError(t, journal.Record(tx(2, 1)))
+	require.NoError(t, journal.Record(tx(1, 1)))
+	require.NoError(t, journal.Record(tx(2, 2)))
+	require.NoError(t, journal.Record(blobTx))
+	require.NoError(t, journal.Remove(1))
+	require.NoError(t, journal.Remove(4))
+
+	// The journal is persisted (one file per nonce), including blob sidecars.
+	files, err := os.ReadDir(path)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	txs, err = NewFileJournal(path).Load()
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.Equal(t, tx(2, 2).Hash(), txs[0].Hash())
+	require.Equal(t, blobTx.Hash(), txs[1].Hash())
+	require.NotNil(t, txs[1].BlobTxSidecar())
+	require.Len(t, txs[1].BlobTxSidecar().Blobs, 1)
+}
+
+func TestFileJournalLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	require.NoError(t, NewFileJournal(path).Lock())
+	// Another holder (e.g. another process) can't lock the same journal.
+	require.ErrorIs(t, NewFileJournal(path).Lock(), ErrJournalLocked)
+	// Journals only kept in memory aren't shared.
+	require.NoError(t, NewFileJournal("").Lock())
+}
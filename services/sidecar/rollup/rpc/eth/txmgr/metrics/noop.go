@@ -2,7 +2,6 @@ package metrics
 
 import "github.com/ethereum/go-ethereum/core/types"
 
-// TODO: prometheus-based metrics type
 type NoopTxMetrics struct{}
 
 func (*NoopTxMetrics) RecordNonce(uint64)                {}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const subsystem = "txmgr"
+
+// TxMetrics records `TxManager` metrics in prometheus, labelled by the service sending the txs.
+type TxMetrics struct {
+	gasBumpCount          prometheus.Gauge
+	txConfirmationLatency prometheus.Histogram
+	nonce                 prometheus.Gauge
+	pendingTxs            prometheus.Gauge
+	txConfirmed           prometheus.Counter
+	txFeesGwei            prometheus.Counter
+	txGasUsed             prometheus.Histogram
+	txPublished           *prometheus.CounterVec
+	rpcErrors             prometheus.Counter
+}
+
+func NewTxMetrics(registerer prometheus.Registerer, namespace string, service string) *TxMetrics {
+	var (
+		factory = promauto.With(registerer)
+		labels  = prometheus.Labels{"service": service}
+	)
+	return &TxMetrics{
+		gasBumpCount: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "gas_bump_count",
+			Help:        "Number of times the last confirmed tx was fee-bumped",
+			ConstLabels: labels,
+		}),
+		txConfirmationLatency: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "confirmation_latency_ms",
+			Help:        "Latency between sending and confirming txs, in ms",
+			Buckets:     prometheus.ExponentialBuckets(1000, 2, 12),
+			ConstLabels: labels,
+		}),
+		nonce: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "nonce",
+			Help:        "Nonce of the last signed tx",
+			ConstLabels: labels,
+		}),
+		pendingTxs: factory.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "pending_txs",
+			Help:        "Number of txs being sent",
+			ConstLabels: labels,
+		}),
+		txConfirmed: factory.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "confirmed_txs_total",
+			Help:        "Number of confirmed txs",
+			ConstLabels: labels,
+		}),
+		txFeesGwei: factory.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "fees_gwei_total",
+			Help:        "Fees paid by confirmed txs, in gwei",
+			ConstLabels: labels,
+		}),
+		txGasUsed: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "gas_used",
+			Help:        "Gas used by confirmed txs",
+			Buckets:     prometheus.ExponentialBuckets(21_000, 2, 10),
+			ConstLabels: labels,
+		}),
+		txPublished: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "published_txs_total",
+			Help:        "Number of tx publication attempts, by error (empty if successful)",
+			ConstLabels: labels,
+		}, []string{"error"}),
+		rpcErrors: factory.NewCounter(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "rpc_errors_total",
+			Help:        "Number of L1 RPC errors",
+			ConstLabels: labels,
+		}),
+	}
+}
+
+func (m *TxMetrics) RecordGasBumpCount(count int) { m.gasBumpCount.Set(float64(count)) }
+
+func (m *TxMetrics) RecordTxConfirmationLatency(latency int64) {
+	m.txConfirmationLatency.Observe(float64(latency))
+}
+
+func (m *TxMetrics) RecordNonce(nonce uint64) { m.nonce.Set(float64(nonce)) }
+
+func (m *TxMetrics) RecordPendingTx(pending int64) { m.pendingTxs.Set(float64(pending)) }
+
+func (m *TxMetrics) TxConfirmed(receipt *types.Receipt) {
+	m.txConfirmed.Inc()
+	m.txGasUsed.Observe(float64(receipt.GasUsed))
+	if receipt.EffectiveGasPrice != nil {
+		fee := float64(receipt.GasUsed) * float64(receipt.EffectiveGasPrice.Uint64()) / params.GWei
+		m.txFeesGwei.Add(fee)
+	}
+}
+
+func (m *TxMetrics) TxPublished(errReason string) { m.txPublished.WithLabelValues(errReason).Inc() }
+
+func (m *TxMetrics) RPCError() { m.rpcErrors.Inc() }
@@ -12,10 +12,14 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
 
 	"github.com/specularL2/specular/services/sidecar/utils/fmt"
 	"github.com/specularL2/specular/services/sidecar/utils/retry"
@@ -24,12 +28,21 @@ import (
 const (
 	// Geth requires a minimum fee bump of 10% for tx resubmission
 	priceBump int64 = 10
+	// Geth's blob pool requires a minimum fee bump of 100% for blob tx resubmission
+	blobPriceBump int64 = 100
 )
 
 // new = old * (100 + priceBump) / 100
 var priceBumpPercent = big.NewInt(100 + priceBump)
+var blobPriceBumpPercent = big.NewInt(100 + blobPriceBump)
 var oneHundred = big.NewInt(100)
 
+// ErrBlobsUnsupported is returned when sending a blob tx to an L1 that hasn't activated EIP-4844.
+var ErrBlobsUnsupported = errors.New("l1 does not support blob transactions")
+
+// ErrMaxFeeExceeded is returned when L1 fees exceed the configured max gas fee cap.
+var ErrMaxFeeExceeded = errors.New("l1 fees exceed the max gas fee cap")
+
 // ETHBackend is the set of methods that the transaction manager uses to resubmit gas & determine
 // when transactions are included on L1.
 type ETHBackend interface {
@@ -48,6 +61,8 @@ type ETHBackend interface {
 	// TODO(CLI-3318): Maybe need a generic interface to support different RPC providers
 	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	// FeeHistory returns the basefees, gas used ratios and tips (at the given percentiles) of recent blocks.
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
 	// NonceAt returns the account nonce of the given account.
 	// The block number can be nil, in which case the nonce is taken from the latest known block.
 	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
@@ -77,11 +92,18 @@ type TxManager struct {
 	signer  SignerFn
 	l       log.Logger
 	metr    TxMetricer
+	journal Journal
+	fees    FeeStrategy
 
 	nonce     *uint64
 	nonceLock sync.RWMutex
+	// Held (for reading) by each send until it's resolved, and (for writing) by `CancelPending`,
+	// which reuses the nonces of pending txs.
+	sendLock sync.RWMutex
 
 	pending atomic.Int64
+
+	publishHook func(tx *types.Transaction)
 }
 
 // NewTxManager initializes a new TxManager with the passed Config.
@@ -92,9 +114,21 @@ func NewTxManager(l log.Logger, cfg Config, backend ETHBackend, signer SignerFn,
 		signer:  signer,
 		l:       l,
 		metr:    m,
+		journal: NewFileJournal(cfg.JournalPath),
+		fees:    NewFeeStrategy(cfg, backend),
 	}
 }
 
+// SetPublishHook sets a function to be called with every successfully published tx
+// (including fee-bumped replacements), e.g. to persist pending tx hashes.
+func (m *TxManager) SetPublishHook(hook func(tx *types.Transaction)) {
+	m.publishHook = hook
+}
+
+// LockJournal locks the tx journal for this process, so that no other process (e.g. `cancel-pending`) can send
+// txs from the same account concurrently. Fails with `ErrJournalLocked` if another process holds it.
+func (m *TxManager) LockJournal() error { return m.journal.Lock() }
+
 func (m *TxManager) From() common.Address {
 	return m.cfg.From
 }
@@ -103,6 +137,266 @@ func (m *TxManager) BlockNumber(ctx context.Context) (uint64, error) {
 	return m.backend.BlockNumber(ctx)
 }
 
+// Recover resumes sending the txs left in the journal by a previous run, and blocks until they're resolved.
+// Journaled txs whose nonces were already used on L1 are dropped. The nonce tracking is reconciled against
+// both the journal and the L1 mempool, so that new txs don't replace the resumed ones (or other pending txs).
+// Should be called once, before sending any new txs.
+func (m *TxManager) Recover(ctx context.Context) error {
+	m.sendLock.RLock()
+	defer m.sendLock.RUnlock()
+	txs, err := m.journal.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load tx journal: %w", err)
+	}
+	confirmedNonce, pendingNonce, err := m.getNonces(ctx)
+	if err != nil {
+		return err
+	}
+	var resumed []*types.Transaction
+	for _, tx := range txs {
+		if tx.Nonce() < confirmedNonce {
+			m.l.Info("Journaled transaction nonce already used", "hash", tx.Hash(), "nonce", tx.Nonce())
+			if err := m.journal.Remove(tx.Nonce()); err != nil {
+				return fmt.Errorf("failed to update tx journal: %w", err)
+			}
+			continue
+		}
+		resumed = append(resumed, tx)
+	}
+	nextNonce := confirmedNonce
+	if len(resumed) > 0 {
+		nextNonce = resumed[len(resumed)-1].Nonce() + 1
+	}
+	if pendingNonce > nextNonce {
+		m.l.Warn("Unjournaled transactions pending in the mempool", "journaled_next", nextNonce, "pending", pendingNonce)
+		nextNonce = pendingNonce
+	}
+	m.nonceLock.Lock()
+	if nextNonce > confirmedNonce {
+		lastNonce := nextNonce - 1
+		m.nonce = &lastNonce
+	} else {
+		m.nonce = nil
+	}
+	m.nonceLock.Unlock()
+	m.l.Info(
+		"Recovering journaled transactions",
+		"num_txs", len(resumed), "confirmed_nonce", confirmedNonce, "pending_nonce", pendingNonce, "next_nonce", nextNonce,
+	)
+	if len(resumed) > 0 && resumed[0].Nonce() > confirmedNonce {
+		m.l.Warn("Nonce gap before journaled transactions", "confirmed_nonce", confirmedNonce, "first_nonce", resumed[0].Nonce())
+	}
+	// Journaled txs are resumed concurrently, as when they were first sent.
+	var wg sync.WaitGroup
+	for _, tx := range resumed {
+		wg.Add(1)
+		m.metr.RecordPendingTx(m.pending.Add(1))
+		go func(tx *types.Transaction) {
+			defer wg.Done()
+			defer func() {
+				m.metr.RecordPendingTx(m.pending.Add(-1))
+			}()
+			sCtx, cancel := m.withSendTimeout(ctx)
+			defer cancel()
+			receipt, err := m.sendTx(sCtx, tx)
+			if err != nil {
+				m.l.Warn("Failed to resume journaled transaction", "nonce", tx.Nonce(), "err", err)
+				return
+			}
+			m.l.Info("Resumed journaled transaction confirmed", "hash", receipt.TxHash, "nonce", tx.Nonce())
+		}(tx)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// forget removes a tx that's no longer being sent from the journal.
+func (m *TxManager) forget(nonce uint64) {
+	if err := m.journal.Remove(nonce); err != nil {
+		m.l.Error("Failed to remove transaction from journal", "nonce", nonce, "err", err)
+	}
+}
+
+// CancelPending replaces all of the account's pending txs (journaled or in the L1 mempool) with zero-value
+// self-transfers at bumped fees, and blocks until the cancellations are resolved, so that no nonce is left stuck.
+// Waits for txs being sent to be resolved first, and blocks new ones until done.
+// Note: unjournaled blob txs can't be replaced this way.
+func (m *TxManager) CancelPending(ctx context.Context) error {
+	m.sendLock.Lock()
+	defer m.sendLock.Unlock()
+	defer m.ResetNonce()
+	txs, err := m.journal.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load tx journal: %w", err)
+	}
+	confirmedNonce, pendingNonce, err := m.getNonces(ctx)
+	if err != nil {
+		return err
+	}
+	var (
+		journaled = map[uint64]*types.Transaction{}
+		endNonce  = pendingNonce
+	)
+	for _, tx := range txs {
+		if tx.Nonce() < confirmedNonce {
+			m.forget(tx.Nonce())
+			continue
+		}
+		journaled[tx.Nonce()] = tx
+		if tx.Nonce() >= endNonce {
+			endNonce = tx.Nonce() + 1
+		}
+	}
+	if endNonce <= confirmedNonce {
+		m.l.Info("No pending transactions to cancel", "confirmed_nonce", confirmedNonce)
+		return nil
+	}
+	m.l.Info("Cancelling pending transactions", "first_nonce", confirmedNonce, "last_nonce", endNonce-1)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for nonce := confirmedNonce; nonce < endNonce; nonce++ {
+		wg.Add(1)
+		go func(nonce uint64) {
+			defer wg.Done()
+			if err := m.cancelNonce(ctx, nonce, journaled[nonce]); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(nonce)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// cancelIfStuck cancels the tx of an abandoned send if it's still pending, since it would block all later nonces.
+// Sends abandoned by the caller (i.e. whose context is done) are left to the caller, or resumed on restart.
+func (m *TxManager) cancelIfStuck(ctx context.Context, tx *types.Transaction) {
+	if ctx.Err() != nil {
+		return
+	}
+	confirmedNonce, pendingNonce, err := m.getNonces(ctx)
+	if err != nil {
+		m.l.Warn("Failed to check for stuck transaction", "nonce", tx.Nonce(), "err", err)
+		return
+	}
+	if tx.Nonce() < confirmedNonce || tx.Nonce() >= pendingNonce {
+		// The nonce was used, or nothing is pending at it.
+		m.forget(tx.Nonce())
+		return
+	}
+	m.l.Warn("Abandoned transaction is stuck, cancelling it", "hash", tx.Hash(), "nonce", tx.Nonce())
+	sCtx, cancel := m.withSendTimeout(ctx)
+	defer cancel()
+	// The journal holds the last published replacement, if any.
+	stuck := tx
+	if txs, err := m.journal.Load(); err == nil {
+		for _, journaled := range txs {
+			if journaled.Nonce() == tx.Nonce() {
+				stuck = journaled
+			}
+		}
+	}
+	if err := m.cancelNonce(sCtx, tx.Nonce(), stuck); err != nil {
+		m.l.Error("Failed to cancel stuck transaction", "nonce", tx.Nonce(), "err", err)
+	}
+}
+
+// cancelNonce sends a zero-value self-transfer with the given nonce, replacing the (optional) stuck tx.
+// Succeeds if the nonce is used, whether by the cancellation or not.
+func (m *TxManager) cancelNonce(ctx context.Context, nonce uint64, stuck *types.Transaction) error {
+	tx, err := m.craftCancellation(ctx, nonce, stuck)
+	if err != nil {
+		return fmt.Errorf("failed to create cancellation tx (nonce=%d): %w", nonce, err)
+	}
+	m.l.Info("Cancelling transaction", "nonce", nonce, "hash", tx.Hash())
+	if _, err := m.sendTx(ctx, tx); err != nil {
+		// The stuck tx may have been mined instead.
+		if confirmedNonce, _, nErr := m.getNonces(ctx); nErr == nil && confirmedNonce > nonce {
+			m.forget(nonce)
+			return nil
+		}
+		return fmt.Errorf("failed to cancel tx (nonce=%d): %w", nonce, err)
+	}
+	return nil
+}
+
+// craftCancellation creates a signed zero-value self-transfer with the given nonce, with fees suggested by L1 and
+// bumped enough to replace the stuck tx (if known). If the stuck tx is unknown and the fees don't suffice,
+// they're bumped when publishing.
+func (m *TxManager) craftCancellation(ctx context.Context, nonce uint64, stuck *types.Transaction) (*types.Transaction, error) {
+	gasTipCap, basefee, blobBasefee, err := m.suggestGasPriceCaps(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price info: %w", err)
+	}
+	var (
+		gasFeeCap = m.fees.GasFeeCap(basefee, gasTipCap)
+		isBlobTx  = stuck != nil && stuck.Type() == types.BlobTxType
+	)
+	if stuck != nil {
+		gasTipCap, gasFeeCap = updateFees(m.fees, stuck.GasTipCap(), stuck.GasFeeCap(), gasTipCap, basefee, isBlobTx, m.l)
+	}
+	if m.cfg.MaxGasFeeCap != nil && gasFeeCap.Cmp(m.cfg.MaxGasFeeCap) > 0 {
+		return nil, fmt.Errorf("cancellation fee 0x%s: %w", gasFeeCap.Text(16), ErrMaxFeeExceeded)
+	}
+	var rawTx types.TxData
+	if isBlobTx {
+		// Blob txs can only be replaced by blob txs, so a single empty blob is carried.
+		if blobBasefee == nil {
+			return nil, ErrBlobsUnsupported
+		}
+		sidecar, err := makeSidecar(make([]kzg4844.Blob, 1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to make blob sidecar: %w", err)
+		}
+		rawTx = &types.BlobTx{
+			ChainID:    uint256.MustFromBig(m.cfg.ChainID),
+			Nonce:      nonce,
+			To:         m.cfg.From,
+			Gas:        params.TxGas,
+			GasTipCap:  uint256.MustFromBig(gasTipCap),
+			GasFeeCap:  uint256.MustFromBig(gasFeeCap),
+			Value:      new(uint256.Int),
+			BlobFeeCap: uint256.MustFromBig(updateBlobFee(stuck.BlobGasFeeCap(), blobBasefee)),
+			BlobHashes: sidecar.BlobHashes(),
+			Sidecar:    sidecar,
+		}
+	} else {
+		rawTx = &types.DynamicFeeTx{
+			ChainID:   m.cfg.ChainID,
+			Nonce:     nonce,
+			To:        &m.cfg.From,
+			Gas:       params.TxGas,
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Value:     new(big.Int),
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
+	return m.signer(ctx, m.cfg.From, types.NewTx(rawTx))
+}
+
+// getNonces returns the account's nonces as of the latest block, and including the L1 mempool.
+func (m *TxManager) getNonces(ctx context.Context) (uint64, uint64, error) {
+	cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
+	confirmedNonce, err := m.backend.NonceAt(cCtx, m.cfg.From, nil)
+	if err != nil {
+		m.metr.RPCError()
+		return 0, 0, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	pendingNonce, err := m.backend.PendingNonceAt(cCtx, m.cfg.From)
+	if err != nil {
+		m.metr.RPCError()
+		return 0, 0, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	return confirmedNonce, pendingNonce, nil
+}
+
 // TxCandidate is a transaction candidate that can be submitted to ask the
 // [TxManager] to construct a transaction with gas price bounds.
 type TxCandidate struct {
@@ -114,6 +408,8 @@ type TxCandidate struct {
 	GasLimit uint64
 	// Value is the value to be used in the constructed tx.
 	Value *big.Int
+	// Blobs are the blobs to be carried by the constructed tx. If non-empty, a blob tx is constructed.
+	Blobs []kzg4844.Blob
 }
 
 // Send is used to publish a transaction with incrementally higher gas prices
@@ -126,23 +422,88 @@ type TxCandidate struct {
 //
 // NOTE: Send can be called concurrently, the nonce will be managed internally.
 func (m *TxManager) Send(ctx context.Context, candidate TxCandidate) (*types.Receipt, error) {
+	m.sendLock.RLock()
+	defer m.sendLock.RUnlock()
 	m.metr.RecordPendingTx(m.pending.Add(1))
 	defer func() {
 		m.metr.RecordPendingTx(m.pending.Add(-1))
 	}()
 	receipt, err := m.send(ctx, candidate)
 	if err != nil {
-		m.resetNonce()
+		m.ResetNonce()
 	}
 	return receipt, err
 }
 
+// SendResult is the outcome of an asynchronous send.
+type SendResult struct {
+	Receipt *types.Receipt
+	Err     error
+}
+
+// SendAsync is like Send, except that it returns as soon as the transaction is signed. The transaction
+// is then published (with fee bumping) in the background, and the result is delivered on the returned channel.
+// Since the nonce is assigned before returning, sequential calls get sequential nonces.
+func (m *TxManager) SendAsync(ctx context.Context, candidate TxCandidate) (*types.Transaction, <-chan SendResult, error) {
+	m.sendLock.RLock()
+	m.metr.RecordPendingTx(m.pending.Add(1))
+	sCtx, cancel := m.withSendTimeout(ctx)
+	tx, err := m.prepare(sCtx, candidate)
+	if err != nil {
+		cancel()
+		m.ResetNonce()
+		m.metr.RecordPendingTx(m.pending.Add(-1))
+		m.sendLock.RUnlock()
+		return nil, nil, err
+	}
+	resultChan := make(chan SendResult, 1)
+	go func() {
+		defer m.sendLock.RUnlock()
+		defer cancel()
+		defer func() {
+			m.metr.RecordPendingTx(m.pending.Add(-1))
+		}()
+		receipt, err := m.sendTx(sCtx, tx)
+		if err != nil {
+			m.cancelIfStuck(ctx, tx)
+			m.ResetNonce()
+		}
+		resultChan <- SendResult{receipt, err}
+	}()
+	return tx, resultChan, nil
+}
+
 // send performs the actual transaction creation and sending.
 func (m *TxManager) send(ctx context.Context, candidate TxCandidate) (*types.Receipt, error) {
+	sCtx, cancel := m.withSendTimeout(ctx)
+	defer cancel()
+	tx, err := m.prepare(sCtx, candidate)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := m.sendTx(sCtx, tx)
+	if err != nil {
+		m.cancelIfStuck(ctx, tx)
+	}
+	return receipt, err
+}
+
+func (m *TxManager) withSendTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
 	if m.cfg.TxSendTimeout != 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, m.cfg.TxSendTimeout)
-		defer cancel()
+		return context.WithTimeout(ctx, m.cfg.TxSendTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// prepare creates the signed transaction, retrying on failure.
+func (m *TxManager) prepare(ctx context.Context, candidate TxCandidate) (*types.Transaction, error) {
+	if len(candidate.Blobs) > 0 {
+		if err := m.ensureBlobsSupported(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.waitForFees(ctx); err != nil {
+		return nil, err
 	}
 	tx, err := retry.Do(ctx, 10, retry.Fixed(2*time.Second), func() (*types.Transaction, error) {
 		tx, err := m.craftTx(ctx, candidate)
@@ -154,7 +515,45 @@ func (m *TxManager) send(ctx context.Context, candidate TxCandidate) (*types.Rec
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the tx: %w", err)
 	}
-	return m.sendTx(ctx, tx)
+	return tx, nil
+}
+
+// waitForFees blocks until L1 fees drop below the max gas fee cap (if any), polling at the resubmission interval.
+// Other errors are left to be handled when crafting the tx.
+func (m *TxManager) waitForFees(ctx context.Context) error {
+	if m.cfg.MaxGasFeeCap == nil {
+		return nil
+	}
+	ticker := time.NewTicker(m.cfg.ResubmissionTimeout)
+	defer ticker.Stop()
+	for {
+		tip, basefee, _, err := m.suggestGasPriceCaps(ctx)
+		if err == nil {
+			_, err = m.capGasFeeCap(basefee, tip)
+		}
+		if !errors.Is(err, ErrMaxFeeExceeded) {
+			return nil
+		}
+		m.l.Warn("Waiting for L1 fees to drop", "tip", tip, "basefee", basefee, "maxFeeCap", m.cfg.MaxGasFeeCap)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up waiting for L1 fees to drop: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// capGasFeeCap returns the gas fee cap suggested by the fee strategy, clamped to the max gas fee cap (if any).
+// Returns `ErrMaxFeeExceeded` if even the current tip and basefee exceed it.
+func (m *TxManager) capGasFeeCap(basefee, tip *big.Int) (*big.Int, error) {
+	gasFeeCap := m.fees.GasFeeCap(basefee, tip)
+	if m.cfg.MaxGasFeeCap == nil || gasFeeCap.Cmp(m.cfg.MaxGasFeeCap) <= 0 {
+		return gasFeeCap, nil
+	}
+	if new(big.Int).Add(basefee, tip).Cmp(m.cfg.MaxGasFeeCap) > 0 {
+		return nil, fmt.Errorf("fee cap 0x%s: %w", gasFeeCap.Text(16), ErrMaxFeeExceeded)
+	}
+	return new(big.Int).Set(m.cfg.MaxGasFeeCap), nil
 }
 
 // craftTx creates the signed transaction
@@ -163,44 +562,105 @@ func (m *TxManager) send(ctx context.Context, candidate TxCandidate) (*types.Rec
 // NOTE: If the [TxCandidate.GasLimit] is non-zero, it will be used as the transaction's gas.
 // NOTE: Otherwise, the [TxManager] will query the specified backend for an estimate.
 func (m *TxManager) craftTx(ctx context.Context, candidate TxCandidate) (*types.Transaction, error) {
-	gasTipCap, basefee, err := m.suggestGasPriceCaps(ctx)
+	gasTipCap, basefee, blobBasefee, err := m.suggestGasPriceCaps(ctx)
 	if err != nil {
 		m.metr.RPCError()
 		return nil, fmt.Errorf("failed to get gas price info: %w", err)
 	}
-	gasFeeCap := calcGasFeeCap(basefee, gasTipCap)
-
-	rawTx := &types.DynamicFeeTx{
-		ChainID:   m.cfg.ChainID,
-		To:        candidate.To,
-		GasTipCap: gasTipCap,
-		GasFeeCap: gasFeeCap,
-		Data:      candidate.TxData,
-		Value:     candidate.Value,
+	gasFeeCap, err := m.capGasFeeCap(basefee, gasTipCap)
+	if err != nil {
+		return nil, err
 	}
 
-	m.l.Info("Creating tx", "to", rawTx.To, "from", m.cfg.From)
+	m.l.Info("Creating tx", "to", candidate.To, "from", m.cfg.From, "numBlobs", len(candidate.Blobs))
 
 	// If the gas limit is set, we can use that as the gas
-	if candidate.GasLimit != 0 {
-		rawTx.Gas = candidate.GasLimit
-	} else {
+	gas := candidate.GasLimit
+	if gas == 0 {
 		// Calculate the intrinsic gas for the transaction
-		gas, err := m.backend.EstimateGas(ctx, ethereum.CallMsg{
+		gas, err = m.backend.EstimateGas(ctx, ethereum.CallMsg{
 			From:      m.cfg.From,
 			To:        candidate.To,
 			GasFeeCap: gasFeeCap,
 			GasTipCap: gasTipCap,
-			Data:      rawTx.Data,
-			Value:     rawTx.Value,
+			Data:      candidate.TxData,
+			Value:     candidate.Value,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to estimate gas: %w", err)
 		}
-		rawTx.Gas = gas
 	}
 
-	return m.signWithNextNonce(ctx, rawTx)
+	if len(candidate.Blobs) == 0 {
+		return m.signWithNextNonce(ctx, &types.DynamicFeeTx{
+			ChainID:   m.cfg.ChainID,
+			To:        candidate.To,
+			Gas:       gas,
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Data:      candidate.TxData,
+			Value:     candidate.Value,
+		})
+	}
+	if candidate.To == nil {
+		return nil, errors.New("blob txs cannot be contract creations")
+	}
+	if blobBasefee == nil {
+		return nil, ErrBlobsUnsupported
+	}
+	sidecar, err := makeSidecar(candidate.Blobs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make blob sidecar: %w", err)
+	}
+	value := new(uint256.Int)
+	if candidate.Value != nil {
+		value = uint256.MustFromBig(candidate.Value)
+	}
+	return m.signWithNextNonce(ctx, &types.BlobTx{
+		ChainID:    uint256.MustFromBig(m.cfg.ChainID),
+		To:         *candidate.To,
+		Gas:        gas,
+		GasTipCap:  uint256.MustFromBig(gasTipCap),
+		GasFeeCap:  uint256.MustFromBig(gasFeeCap),
+		Data:       candidate.TxData,
+		Value:      value,
+		BlobFeeCap: uint256.MustFromBig(calcBlobFeeCap(blobBasefee)),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+}
+
+// makeSidecar computes the KZG commitments and proofs for the given blobs.
+func makeSidecar(blobs []kzg4844.Blob) (*types.BlobTxSidecar, error) {
+	sidecar := &types.BlobTxSidecar{Blobs: blobs}
+	for i := range blobs {
+		commitment, err := kzg4844.BlobToCommitment(blobs[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute commitment for blob %d: %w", i, err)
+		}
+		proof, err := kzg4844.ComputeBlobProof(blobs[i], commitment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute proof for blob %d: %w", i, err)
+		}
+		sidecar.Commitments = append(sidecar.Commitments, commitment)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+	}
+	return sidecar, nil
+}
+
+// ensureBlobsSupported returns ErrBlobsUnsupported if the latest L1 header has no excess blob gas (pre-Cancun).
+func (m *TxManager) ensureBlobsSupported(ctx context.Context) error {
+	cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
+	defer cancel()
+	head, err := m.backend.HeaderByNumber(cCtx, nil)
+	if err != nil {
+		m.metr.RPCError()
+		return fmt.Errorf("failed to fetch the latest header: %w", err)
+	}
+	if head.ExcessBlobGas == nil {
+		return ErrBlobsUnsupported
+	}
+	return nil
 }
 
 // signWithNextNonce returns a signed transaction with the next available nonce.
@@ -208,7 +668,7 @@ func (m *TxManager) craftTx(ctx context.Context, candidate TxCandidate) (*types.
 // then subsequent calls simply increment this number. If the transaction manager
 // is reset, it will query the eth_getTransactionCount nonce again. If signing
 // fails, the nonce is not incremented.
-func (m *TxManager) signWithNextNonce(ctx context.Context, rawTx *types.DynamicFeeTx) (*types.Transaction, error) {
+func (m *TxManager) signWithNextNonce(ctx context.Context, rawTx types.TxData) (*types.Transaction, error) {
 	m.nonceLock.Lock()
 	defer m.nonceLock.Unlock()
 
@@ -226,7 +686,14 @@ func (m *TxManager) signWithNextNonce(ctx context.Context, rawTx *types.DynamicF
 		*m.nonce++
 	}
 
-	rawTx.Nonce = *m.nonce
+	switch rawTx := rawTx.(type) {
+	case *types.DynamicFeeTx:
+		rawTx.Nonce = *m.nonce
+	case *types.BlobTx:
+		rawTx.Nonce = *m.nonce
+	default:
+		return nil, fmt.Errorf("unsupported tx type: %T", rawTx)
+	}
 	ctx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
 	defer cancel()
 	tx, err := m.signer(ctx, m.cfg.From, types.NewTx(rawTx))
@@ -240,9 +707,9 @@ func (m *TxManager) signWithNextNonce(ctx context.Context, rawTx *types.DynamicF
 	return tx, err
 }
 
-// resetNonce resets the internal nonce tracking. This is called if any pending send
-// returns an error.
-func (m *TxManager) resetNonce() {
+// ResetNonce resets the internal nonce tracking. This is called if any pending send
+// returns an error, and may be called externally if previously confirmed txs were reorged out.
+func (m *TxManager) ResetNonce() {
 	m.nonceLock.Lock()
 	defer m.nonceLock.Unlock()
 	m.nonce = nil
@@ -288,14 +755,17 @@ func (m *TxManager) sendTx(ctx context.Context, tx *types.Transaction) (*types.R
 			// If we see lots of unrecoverable errors (and no pending transactions) abort sending the transaction.
 			if sendState.ShouldAbortImmediately() {
 				m.l.Warn("Aborting transaction submission")
+				m.forget(tx.Nonce())
 				return nil, errors.New("aborted transaction sending")
 			}
 			tx = publishAndWait(tx, true)
 
 		case <-ctx.Done():
+			// The tx may still be pending, so it's kept in the journal (to be resumed on restart).
 			return nil, ctx.Err()
 
 		case receipt := <-receiptChan:
+			m.forget(tx.Nonce())
 			m.metr.RecordGasBumpCount(sendState.bumpCount)
 			m.metr.TxConfirmed(receipt)
 			return receipt, nil
@@ -334,6 +804,13 @@ func (m *TxManager) publishTx(ctx context.Context, tx *types.Transaction, sendSt
 			return tx, false
 		}
 
+		// Journal the tx before publishing it, so that it can be resumed if we crash.
+		if err := m.journal.Record(tx); err != nil {
+			l.Error("unable to journal transaction", "err", err)
+			m.metr.TxPublished("journal_failed")
+			return tx, false
+		}
+
 		cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
 		err := m.backend.SendTransaction(cCtx, tx)
 		cancel()
@@ -342,6 +819,9 @@ func (m *TxManager) publishTx(ctx context.Context, tx *types.Transaction, sendSt
 		if err == nil {
 			m.metr.TxPublished("")
 			log.Info("Transaction successfully published")
+			if m.publishHook != nil {
+				m.publishHook(tx)
+			}
 			return tx, true
 		}
 
@@ -467,40 +947,46 @@ func (m *TxManager) queryReceipt(ctx context.Context, txHash common.Hash, sendSt
 // `feeLimitMultiplier` multiple of the suggested values.
 func (m *TxManager) increaseGasPrice(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
 	m.l.Info("bumping gas price for tx", "hash", tx.Hash(), "tip", tx.GasTipCap(), "fee", tx.GasFeeCap(), "gaslimit", tx.Gas())
-	tip, basefee, err := m.suggestGasPriceCaps(ctx)
+	tip, basefee, blobBasefee, err := m.suggestGasPriceCaps(ctx)
 	if err != nil {
 		m.l.Warn("failed to get suggested gas tip and basefee", "err", err)
 		return nil, err
 	}
-	bumpedTip, bumpedFee := updateFees(tx.GasTipCap(), tx.GasFeeCap(), tip, basefee, m.l)
+	isBlobTx := tx.Type() == types.BlobTxType
+	bumpedTip, bumpedFee := updateFees(m.fees, tx.GasTipCap(), tx.GasFeeCap(), tip, basefee, isBlobTx, m.l)
 
 	// Make sure increase is at most [FeeLimitMultiplier] the suggested values
 	maxTip := new(big.Int).Mul(tip, big.NewInt(int64(m.cfg.FeeLimitMultiplier)))
 	if bumpedTip.Cmp(maxTip) > 0 {
 		return nil, fmt.Errorf("bumped tip 0x%s is over %dx multiple of the suggested value", bumpedTip.Text(16), m.cfg.FeeLimitMultiplier)
 	}
-	maxFee := calcGasFeeCap(new(big.Int).Mul(basefee, big.NewInt(int64(m.cfg.FeeLimitMultiplier))), maxTip)
+	maxFee := m.fees.GasFeeCap(new(big.Int).Mul(basefee, big.NewInt(int64(m.cfg.FeeLimitMultiplier))), maxTip)
 	if bumpedFee.Cmp(maxFee) > 0 {
 		return nil, fmt.Errorf("bumped fee 0x%s is over %dx multiple of the suggested value", bumpedFee.Text(16), m.cfg.FeeLimitMultiplier)
 	}
-	rawTx := &types.DynamicFeeTx{
-		ChainID:    tx.ChainId(),
-		Nonce:      tx.Nonce(),
-		GasTipCap:  bumpedTip,
-		GasFeeCap:  bumpedFee,
-		To:         tx.To(),
-		Value:      tx.Value(),
-		Data:       tx.Data(),
-		AccessList: tx.AccessList(),
+	// Keep waiting on the current tx rather than exceed the ceiling.
+	if m.cfg.MaxGasFeeCap != nil && bumpedFee.Cmp(m.cfg.MaxGasFeeCap) > 0 {
+		return nil, fmt.Errorf("bumped fee 0x%s: %w", bumpedFee.Text(16), ErrMaxFeeExceeded)
+	}
+	var bumpedBlobFee *big.Int
+	if isBlobTx {
+		if blobBasefee == nil {
+			return nil, ErrBlobsUnsupported
+		}
+		bumpedBlobFee = updateBlobFee(tx.BlobGasFeeCap(), blobBasefee)
+		maxBlobFee := calcBlobFeeCap(new(big.Int).Mul(blobBasefee, big.NewInt(int64(m.cfg.FeeLimitMultiplier))))
+		if bumpedBlobFee.Cmp(maxBlobFee) > 0 {
+			return nil, fmt.Errorf("bumped blob fee 0x%s is over %dx multiple of the suggested value", bumpedBlobFee.Text(16), m.cfg.FeeLimitMultiplier)
+		}
 	}
 
 	// Re-estimate gaslimit in case things have changed or a previous gaslimit estimate was wrong
 	gas, err := m.backend.EstimateGas(ctx, ethereum.CallMsg{
 		From:      m.cfg.From,
-		To:        rawTx.To,
+		To:        tx.To(),
 		GasFeeCap: bumpedTip,
 		GasTipCap: bumpedFee,
-		Data:      rawTx.Data,
+		Data:      tx.Data(),
 	})
 	if err != nil {
 		// If this is a transaction resubmission, we sometimes see this outcome because the
@@ -513,7 +999,35 @@ func (m *TxManager) increaseGasPrice(ctx context.Context, tx *types.Transaction)
 	if tx.Gas() != gas {
 		m.l.Info("re-estimated gas differs", "oldgas", tx.Gas(), "newgas", gas)
 	}
-	rawTx.Gas = gas
+	var rawTx types.TxData
+	if isBlobTx {
+		rawTx = &types.BlobTx{
+			ChainID:    uint256.MustFromBig(tx.ChainId()),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  uint256.MustFromBig(bumpedTip),
+			GasFeeCap:  uint256.MustFromBig(bumpedFee),
+			Gas:        gas,
+			To:         *tx.To(),
+			Value:      uint256.MustFromBig(tx.Value()),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+			BlobFeeCap: uint256.MustFromBig(bumpedBlobFee),
+			BlobHashes: tx.BlobHashes(),
+			Sidecar:    tx.BlobTxSidecar(),
+		}
+	} else {
+		rawTx = &types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  bumpedTip,
+			GasFeeCap:  bumpedFee,
+			Gas:        gas,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
 	defer cancel()
@@ -525,32 +1039,41 @@ func (m *TxManager) increaseGasPrice(ctx context.Context, tx *types.Transaction)
 	return newTx, nil
 }
 
-// suggestGasPriceCaps suggests what the new tip & new basefee should be based on the current L1 conditions
-func (m *TxManager) suggestGasPriceCaps(ctx context.Context) (*big.Int, *big.Int, error) {
+// suggestGasPriceCaps suggests what the new tip, basefee & blob basefee should be based on the current L1 conditions.
+// The blob basefee is nil if the L1 doesn't support blob txs.
+func (m *TxManager) suggestGasPriceCaps(ctx context.Context) (*big.Int, *big.Int, *big.Int, error) {
 	cCtx, cancel := context.WithTimeout(ctx, m.cfg.NetworkTimeout)
 	defer cancel()
-	tip, err := m.backend.SuggestGasTipCap(cCtx)
+	head, err := m.backend.HeaderByNumber(cCtx, nil)
 	if err != nil {
 		m.metr.RPCError()
-		return nil, nil, fmt.Errorf("failed to fetch the suggested gas tip cap: %w", err)
-	} else if tip == nil {
-		return nil, nil, errors.New("the suggested tip was nil")
+		return nil, nil, nil, fmt.Errorf("failed to fetch the suggested basefee: %w", err)
+	} else if head.BaseFee == nil {
+		return nil, nil, nil, errors.New("txmgr does not support pre-london blocks that do not have a basefee")
 	}
 	cCtx, cancel = context.WithTimeout(ctx, m.cfg.NetworkTimeout)
 	defer cancel()
-	head, err := m.backend.HeaderByNumber(cCtx, nil)
+	tip, err := m.fees.SuggestGasTipCap(cCtx, head)
 	if err != nil {
 		m.metr.RPCError()
-		return nil, nil, fmt.Errorf("failed to fetch the suggested basefee: %w", err)
-	} else if head.BaseFee == nil {
-		return nil, nil, errors.New("txmgr does not support pre-london blocks that do not have a basefee")
+		return nil, nil, nil, fmt.Errorf("failed to fetch the suggested gas tip cap: %w", err)
+	} else if tip == nil {
+		return nil, nil, nil, errors.New("the suggested tip was nil")
+	}
+	var blobBasefee *big.Int
+	if head.ExcessBlobGas != nil {
+		blobBasefee = eip4844.CalcBlobFee(*head.ExcessBlobGas)
 	}
-	return tip, head.BaseFee, nil
+	return tip, head.BaseFee, blobBasefee, nil
 }
 
-// calcThresholdValue returns x * priceBumpPercent / 100
-func calcThresholdValue(x *big.Int) *big.Int {
-	threshold := new(big.Int).Mul(priceBumpPercent, x)
+// calcThresholdValue returns x * priceBumpPercent / 100 (or x * blobPriceBumpPercent / 100 for blob txs)
+func calcThresholdValue(x *big.Int, isBlobTx bool) *big.Int {
+	bumpPercent := priceBumpPercent
+	if isBlobTx {
+		bumpPercent = blobPriceBumpPercent
+	}
+	threshold := new(big.Int).Mul(bumpPercent, x)
 	threshold = threshold.Div(threshold, oneHundred)
 	return threshold
 }
@@ -560,25 +1083,25 @@ func calcThresholdValue(x *big.Int) *big.Int {
 //
 //	(a) each satisfies geth's required tx-replacement fee bumps (we use a 10% increase), and
 //	(b) gasTipCap is no less than new tip, and
-//	(c) gasFeeCap is no less than calcGasFee(newBaseFee, newTip)
-func updateFees(oldTip, oldFeeCap, newTip, newBaseFee *big.Int, lgr log.Logger) (*big.Int, *big.Int) {
-	newFeeCap := calcGasFeeCap(newBaseFee, newTip)
+//	(c) gasFeeCap is no less than fees.GasFeeCap(newBaseFee, newTip)
+func updateFees(fees FeeStrategy, oldTip, oldFeeCap, newTip, newBaseFee *big.Int, isBlobTx bool, lgr log.Logger) (*big.Int, *big.Int) {
+	newFeeCap := fees.GasFeeCap(newBaseFee, newTip)
 	lgr = lgr.New("old_tip", oldTip, "old_feecap", oldFeeCap, "new_tip", newTip, "new_feecap", newFeeCap)
-	thresholdTip := calcThresholdValue(oldTip)
-	thresholdFeeCap := calcThresholdValue(oldFeeCap)
+	thresholdTip := calcThresholdValue(oldTip, isBlobTx)
+	thresholdFeeCap := calcThresholdValue(oldFeeCap, isBlobTx)
 	if newTip.Cmp(thresholdTip) >= 0 && newFeeCap.Cmp(thresholdFeeCap) >= 0 {
 		lgr.Debug("Using new tip and feecap")
 		return newTip, newFeeCap
 	} else if newTip.Cmp(thresholdTip) >= 0 && newFeeCap.Cmp(thresholdFeeCap) < 0 {
-		// Tip has gone up, but basefee is flat or down.
-		// TODO(CLI-3714): Do we need to recalculate the FC here?
+		// Tip has gone up, but basefee is flat or down. No need to recalculate the feecap: the threshold
+		// exceeds the new feecap, which already covers the new tip.
 		lgr.Debug("Using new tip and threshold feecap")
 		return newTip, thresholdFeeCap
 	} else if newTip.Cmp(thresholdTip) < 0 && newFeeCap.Cmp(thresholdFeeCap) >= 0 {
 		// Basefee has gone up, but the tip hasn't. Recalculate the feecap because if the tip went up a lot
 		// not enough of the feecap may be dedicated to paying the basefee.
 		lgr.Debug("Using threshold tip and recalculated feecap")
-		return thresholdTip, calcGasFeeCap(newBaseFee, thresholdTip)
+		return thresholdTip, fees.GasFeeCap(newBaseFee, thresholdTip)
 
 	} else {
 		// TODO(CLI-3713): Should we skip the bump in this case?
@@ -587,6 +1110,21 @@ func updateFees(oldTip, oldFeeCap, newTip, newBaseFee *big.Int, lgr log.Logger)
 	}
 }
 
+// updateBlobFee takes an old blob tx's blob fee cap plus a new blob basefee, and returns a blob fee cap
+// that satisfies geth's required blob tx-replacement fee bump, and is no less than calcBlobFeeCap(newBlobBaseFee).
+func updateBlobFee(oldBlobFeeCap, newBlobBaseFee *big.Int) *big.Int {
+	thresholdBlobFeeCap := calcThresholdValue(oldBlobFeeCap, true)
+	if newBlobFeeCap := calcBlobFeeCap(newBlobBaseFee); newBlobFeeCap.Cmp(thresholdBlobFeeCap) > 0 {
+		return newBlobFeeCap
+	}
+	return thresholdBlobFeeCap
+}
+
+// calcBlobFeeCap computes the recommended blob fee cap given the blob basefee: 2*blobBaseFee.
+func calcBlobFeeCap(blobBaseFee *big.Int) *big.Int {
+	return new(big.Int).Mul(blobBaseFee, big.NewInt(2))
+}
+
 // calcGasFeeCap deterministically computes the recommended gas fee cap given
 // the base fee and gasTipCap. The resulting gasFeeCap is equal to:
 //
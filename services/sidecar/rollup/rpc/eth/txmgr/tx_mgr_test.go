@@ -0,0 +1,294 @@
+package txmgr
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr/metrics"
+)
+
+// testBackend is a minimal ETHBackend returning fixed fee market conditions.
+type testBackend struct {
+	excessBlobGas *uint64
+	feeHistory    *ethereum.FeeHistory
+}
+
+func (b *testBackend) BlockNumber(context.Context) (uint64, error) { return 1, nil }
+func (b *testBackend) TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+func (b *testBackend) SendTransaction(context.Context, *types.Transaction) error { return nil }
+func (b *testBackend) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(100), ExcessBlobGas: b.excessBlobGas}, nil
+}
+func (b *testBackend) SuggestGasTipCap(context.Context) (*big.Int, error) { return big.NewInt(10), nil }
+func (b *testBackend) FeeHistory(context.Context, uint64, *big.Int, []float64) (*ethereum.FeeHistory, error) {
+	return b.feeHistory, nil
+}
+func (b *testBackend) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return 0, nil
+}
+func (b *testBackend) PendingNonceAt(context.Context, common.Address) (uint64, error) { return 0, nil }
+func (b *testBackend) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return 21000, nil
+}
+
+func newTestTxManager(t *testing.T, backend ETHBackend) *TxManager {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	var (
+		chainID = big.NewInt(1)
+		signer  = types.LatestSignerForChainID(chainID)
+		cfg     = Config{
+			ChainID:                   chainID,
+			NetworkTimeout:            time.Second,
+			FeeLimitMultiplier:        5,
+			ResubmissionTimeout:       time.Second,
+			ReceiptQueryInterval:      time.Second,
+			TxNotInMempoolTimeout:     time.Second,
+			NumConfirmations:          1,
+			SafeAbortNonceTooLowCount: 3,
+			From:                      crypto.PubkeyToAddress(key.PublicKey),
+		}
+		signerFn = func(_ context.Context, _ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return types.SignTx(tx, signer, key)
+		}
+	)
+	return NewTxManager(log.Root(), cfg, backend, signerFn, &metrics.NoopTxMetrics{})
+}
+
+func TestCraftBlobTx(t *testing.T) {
+	var (
+		excessBlobGas = uint64(0)
+		m             = newTestTxManager(t, &testBackend{excessBlobGas: &excessBlobGas})
+		to            = common.HexToAddress("0x01")
+		candidate     = TxCandidate{TxData: []byte{0x1}, To: &to, Blobs: make([]kzg4844.Blob, 2)}
+	)
+	tx, err := m.craftTx(context.Background(), candidate)
+	require.NoError(t, err)
+	require.Equal(t, uint8(types.BlobTxType), tx.Type())
+	require.Len(t, tx.BlobHashes(), 2)
+	require.NotNil(t, tx.BlobTxSidecar())
+	require.Equal(t, tx.BlobHashes(), tx.BlobTxSidecar().BlobHashes())
+	require.Equal(t, big.NewInt(2), tx.BlobGasFeeCap())
+
+	// Blob txs must be bumped by at least 100%.
+	bumped, err := m.increaseGasPrice(context.Background(), tx)
+	require.NoError(t, err)
+	require.Equal(t, uint8(types.BlobTxType), bumped.Type())
+	require.Equal(t, tx.Nonce(), bumped.Nonce())
+	require.Equal(t, tx.BlobHashes(), bumped.BlobHashes())
+	require.GreaterOrEqual(t, bumped.GasTipCap().Cmp(new(big.Int).Mul(tx.GasTipCap(), big.NewInt(2))), 0)
+	require.GreaterOrEqual(t, bumped.GasFeeCap().Cmp(new(big.Int).Mul(tx.GasFeeCap(), big.NewInt(2))), 0)
+	require.GreaterOrEqual(t, bumped.BlobGasFeeCap().Cmp(new(big.Int).Mul(tx.BlobGasFeeCap(), big.NewInt(2))), 0)
+}
+
+func TestSendBlobTxUnsupported(t *testing.T) {
+	var (
+		m         = newTestTxManager(t, &testBackend{})
+		to        = common.HexToAddress("0x01")
+		candidate = TxCandidate{To: &to, Blobs: make([]kzg4844.Blob, 1)}
+	)
+	_, err := m.Send(context.Background(), candidate)
+	require.ErrorIs(t, err, ErrBlobsUnsupported)
+}
+
+// minedBackend is a testBackend whose published txs are mined immediately (unless filtered out by `isMined`).
+type minedBackend struct {
+	testBackend
+	nonce, pendingNonce uint64
+	onSend              func(tx *types.Transaction)
+	isMined             func(tx *types.Transaction) bool
+
+	mu   sync.Mutex
+	sent []*types.Transaction
+}
+
+func (b *minedBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	if b.onSend != nil {
+		b.onSend(tx)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *minedBackend) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, tx := range b.sent {
+		if tx.Hash() == txHash && (b.isMined == nil || b.isMined(tx)) {
+			return &types.Receipt{TxHash: txHash, BlockNumber: big.NewInt(1)}, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (b *minedBackend) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return b.nonce, nil
+}
+func (b *minedBackend) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return b.pendingNonce, nil
+}
+
+// Returns the distinct nonces of the published txs (which may have been resubmitted).
+func (b *minedBackend) sentNonces() []uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var (
+		nonces []uint64
+		seen   = map[uint64]bool{}
+	)
+	for _, tx := range b.sent {
+		if !seen[tx.Nonce()] {
+			nonces = append(nonces, tx.Nonce())
+			seen[tx.Nonce()] = true
+		}
+	}
+	return nonces
+}
+
+func TestSendJournalsTx(t *testing.T) {
+	var (
+		backend = &minedBackend{}
+		m       = newTestTxManager(t, backend)
+		to      = common.HexToAddress("0x01")
+	)
+	// Txs are journaled before they're published, and removed once confirmed.
+	backend.onSend = func(tx *types.Transaction) {
+		txs, err := m.journal.Load()
+		require.NoError(t, err)
+		require.Len(t, txs, 1)
+		require.Equal(t, tx.Hash(), txs[0].Hash())
+	}
+	_, err := m.Send(context.Background(), TxCandidate{To: &to})
+	require.NoError(t, err)
+	txs, err := m.journal.Load()
+	require.NoError(t, err)
+	require.Empty(t, txs)
+}
+
+func TestRecoverJournaledTxs(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		backend = &minedBackend{nonce: 5, pendingNonce: 7}
+		m       = newTestTxManager(t, backend)
+		to      = common.HexToAddress("0x01")
+	)
+	m.journal = NewFileJournal(filepath.Join(t.TempDir(), "journal"))
+	for _, nonce := range []uint64{3, 5, 6} {
+		tx, err := m.signer(ctx, m.cfg.From, types.NewTx(&types.DynamicFeeTx{
+			ChainID: m.cfg.ChainID, Nonce: nonce, To: &to, Gas: 21000, GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(210),
+		}))
+		require.NoError(t, err)
+		require.NoError(t, m.journal.Record(tx))
+	}
+	// Nonce 3 was already used, so only the txs with nonces 5 and 6 are resumed (until confirmed).
+	require.NoError(t, m.Recover(ctx))
+	require.ElementsMatch(t, []uint64{5, 6}, backend.sentNonces())
+	txs, err := m.journal.Load()
+	require.NoError(t, err)
+	require.Empty(t, txs)
+
+	// New txs don't replace the resumed ones.
+	tx, err := m.craftTx(ctx, TxCandidate{To: &to})
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), tx.Nonce())
+
+	// Nor unjournaled txs pending in the mempool.
+	backend.pendingNonce = 9
+	require.NoError(t, m.Recover(ctx))
+	tx, err = m.craftTx(ctx, TxCandidate{To: &to})
+	require.NoError(t, err)
+	require.Equal(t, uint64(9), tx.Nonce())
+}
+
+func isCancellation(m *TxManager, tx *types.Transaction) bool {
+	return *tx.To() == m.cfg.From && tx.Value().Sign() == 0 && len(tx.Data()) == 0
+}
+
+func TestCancelPending(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		backend = &minedBackend{nonce: 5, pendingNonce: 6}
+		m       = newTestTxManager(t, backend)
+		to      = common.HexToAddress("0x01")
+	)
+	journaled, err := m.signer(ctx, m.cfg.From, types.NewTx(&types.DynamicFeeTx{
+		ChainID: m.cfg.ChainID, Nonce: 6, To: &to, Gas: 21000, GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1000),
+	}))
+	require.NoError(t, err)
+	require.NoError(t, m.journal.Record(journaled))
+
+	// Both the tx pending in the mempool and the journaled one are cancelled.
+	require.NoError(t, m.CancelPending(ctx))
+	require.ElementsMatch(t, []uint64{5, 6}, backend.sentNonces())
+	for _, tx := range backend.sent {
+		require.True(t, isCancellation(m, tx))
+		if tx.Nonce() == 6 {
+			// The journaled tx's fees are bumped enough to replace it.
+			require.GreaterOrEqual(t, tx.GasTipCap().Int64(), int64(110))
+			require.GreaterOrEqual(t, tx.GasFeeCap().Int64(), int64(1100))
+		}
+	}
+	txs, err := m.journal.Load()
+	require.NoError(t, err)
+	require.Empty(t, txs)
+}
+
+func TestCancelPendingWaitsForSends(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		backend = &minedBackend{}
+		m       = newTestTxManager(t, backend)
+		to      = common.HexToAddress("0x01")
+		mined   atomic.Bool
+	)
+	backend.isMined = func(*types.Transaction) bool { return mined.Load() }
+	_, resultChan, err := m.SendAsync(ctx, TxCandidate{To: &to})
+	require.NoError(t, err)
+
+	// The in-flight tx's nonce isn't reused until its send is resolved.
+	done := make(chan error, 1)
+	go func() { done <- m.CancelPending(ctx) }()
+	select {
+	case <-done:
+		t.Fatal("cancelled pending txs while a tx was being sent")
+	case <-time.After(200 * time.Millisecond):
+	}
+	mined.Store(true)
+	require.NoError(t, (<-resultChan).Err)
+	require.NoError(t, <-done)
+}
+
+func TestCancelStuckTx(t *testing.T) {
+	var (
+		backend = &minedBackend{nonce: 0, pendingNonce: 1}
+		m       = newTestTxManager(t, backend)
+		to      = common.HexToAddress("0x01")
+	)
+	// Only the cancellation is mined, so the send times out with its tx still pending.
+	backend.isMined = func(tx *types.Transaction) bool { return isCancellation(m, tx) }
+	m.cfg.TxSendTimeout = 1500 * time.Millisecond
+	_, err := m.Send(context.Background(), TxCandidate{To: &to})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, []uint64{0}, backend.sentNonces())
+	require.True(t, isCancellation(m, backend.sent[len(backend.sent)-1]))
+	txs, err := m.journal.Load()
+	require.NoError(t, err)
+	require.Empty(t, txs)
+}
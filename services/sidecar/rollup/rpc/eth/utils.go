@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"github.com/avast/retry-go/v4"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 
+	"github.com/specularL2/specular/services/sidecar/utils"
 	"github.com/specularL2/specular/services/sidecar/utils/fmt"
 	"github.com/specularL2/specular/services/sidecar/utils/log"
 )
@@ -56,6 +58,38 @@ func SubscribeNewHeadByPolling(
 	})
 }
 
+// Subscribes to all `brokers`, signalling on the returned channel whenever any of them publishes a new header
+// (i.e. one that differs from the last header it published).
+// Signals are coalesced (the channel has capacity 1), so a slow consumer never blocks the brokers.
+func SubscribeNewHeads(ctx context.Context, brokers ...*utils.Broker[*types.Header]) <-chan struct{} {
+	signalCh := make(chan struct{}, 1)
+	for _, broker := range brokers {
+		broker := broker
+		go func() {
+			// Subscribing blocks until the broker is started.
+			headCh := broker.Subscribe()
+			defer broker.Unsubscribe(headCh)
+			var last common.Hash
+			for {
+				select {
+				case header := <-headCh:
+					if header.Hash() == last {
+						continue
+					}
+					last = header.Hash()
+					select {
+					case signalCh <- struct{}{}:
+					default:
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return signalCh
+}
+
 type LazyEthClient struct {
 	*EthClient
 	endpoint  string
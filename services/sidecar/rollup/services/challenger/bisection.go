@@ -0,0 +1,90 @@
+package challenger
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Maximum number of sub-segments a segment is bisected into (see `SymChallenge.sol`).
+const maxBisectionDegree = 2
+
+// A bisection of a challenged segment, i.e. the state hashes at the boundaries of its sub-segments.
+// Mirrors the on-chain representation (see `ChallengeLib.sol`).
+type Bisection struct {
+	Hashes []common.Hash
+	// Step of `Hashes[0]`, relative to the start of the challenged assertion.
+	Start uint64
+	// Length (in steps) of the bisected segment.
+	Length uint64
+}
+
+// Returns the initial bisection of a challenge, spanning all `numSteps` steps.
+func initialBisection(startStateHash, endStateHash common.Hash, numSteps uint64) *Bisection {
+	return &Bisection{Hashes: []common.Hash{startStateHash, endStateHash}, Start: 0, Length: numSteps}
+}
+
+// Returns H(bisection || segmentStart || segmentLength), as computed by `ChallengeLib.computeBisectionHash`.
+func (b *Bisection) Hash() common.Hash {
+	buf := make([]byte, 0, len(b.Hashes)*common.HashLength+2*32)
+	for _, hash := range b.Hashes {
+		buf = append(buf, hash[:]...)
+	}
+	buf = append(buf, math.U256Bytes(new(big.Int).SetUint64(b.Start))...)
+	buf = append(buf, math.U256Bytes(new(big.Int).SetUint64(b.Length))...)
+	return crypto.Keccak256Hash(buf)
+}
+
+func (b *Bisection) degree() uint64 { return uint64(len(b.Hashes) - 1) }
+
+// Returns the start step and length of the `i`th sub-segment (1-indexed, ending at `Hashes[i]`).
+func (b *Bisection) subSegment(i int) (uint64, uint64) {
+	var (
+		first = firstSegmentLength(b.Length, b.degree())
+		other = otherSegmentLength(b.Length, b.degree())
+	)
+	if i == 1 {
+		return b.Start, first
+	}
+	return b.Start + first + other*uint64(i-2), other
+}
+
+// Returns the step of each hash in the bisection.
+func (b *Bisection) steps() []uint64 {
+	steps := []uint64{b.Start}
+	for i := 1; i < len(b.Hashes); i++ {
+		start, length := b.subSegment(i)
+		steps = append(steps, start+length)
+	}
+	return steps
+}
+
+// Returns the steps at which a segment would be bisected.
+func bisectionSteps(start, length uint64) []uint64 {
+	degree := uint64(maxBisectionDegree)
+	if length < degree {
+		degree = length
+	}
+	return (&Bisection{Hashes: make([]common.Hash, degree+1), Start: start, Length: length}).steps()
+}
+
+func firstSegmentLength(length, degree uint64) uint64 { return length/degree + length%degree }
+func otherSegmentLength(length, degree uint64) uint64 { return length / degree }
+
+func toBytes32s(hashes []common.Hash) [][32]byte {
+	out := make([][32]byte, len(hashes))
+	for i, hash := range hashes {
+		out[i] = hash
+	}
+	return out
+}
+
+func toHashes(bytes32s [][32]byte) []common.Hash {
+	out := make([]common.Hash, len(bytes32s))
+	for i, b := range bytes32s {
+		out[i] = b
+	}
+	return out
+}
@@ -0,0 +1,36 @@
+package challenger
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBisectionSteps(t *testing.T) {
+	// Initial bisection, spanning the whole challenge.
+	initial := initialBisection(common.Hash{1}, common.Hash{2}, 7)
+	require.Equal(t, []uint64{0, 7}, initial.steps())
+	start, length := initial.subSegment(1)
+	require.Equal(t, uint64(0), start)
+	require.Equal(t, uint64(7), length)
+
+	// The first sub-segment absorbs the remainder (see `ChallengeLib.firstSegmentLength`).
+	require.Equal(t, []uint64{0, 4, 7}, bisectionSteps(0, 7))
+	bisection := &Bisection{Hashes: make([]common.Hash, 3), Start: 0, Length: 7}
+	start, length = bisection.subSegment(2)
+	require.Equal(t, uint64(4), start)
+	require.Equal(t, uint64(3), length)
+	require.Equal(t, []uint64{4, 6, 7}, bisectionSteps(start, length))
+}
+
+func TestBisectionHash(t *testing.T) {
+	var (
+		a = &Bisection{Hashes: []common.Hash{{1}, {2}}, Start: 0, Length: 7}
+		b = &Bisection{Hashes: []common.Hash{{1}, {2}}, Start: 0, Length: 8}
+		c = &Bisection{Hashes: []common.Hash{{1}, {3}}, Start: 0, Length: 7}
+	)
+	require.NotEqual(t, a.Hash(), b.Hash())
+	require.NotEqual(t, a.Hash(), c.Hash())
+	require.Equal(t, a.Hash(), initialBisection(common.Hash{1}, common.Hash{2}, 7).Hash())
+}
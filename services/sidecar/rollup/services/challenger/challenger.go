@@ -0,0 +1,167 @@
+package challenger
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+	"github.com/specularL2/specular/services/sidecar/utils/log"
+)
+
+var transactTimeout = 10 * time.Minute
+
+// Drives the challenges the validator takes part in (as defender or challenger), until they complete.
+// Challenges are opened by the validator; protocol moves are delegated to the `Responder` of the challenge's protocol.
+type Challenger struct {
+	cfg            Config
+	l1TxMgr        TxManager
+	l1BridgeClient BridgeClient
+	l1State        EthState
+	responders     map[bridge.ChallengeProtocol]Responder
+	newHeads       <-chan struct{} // Signals new L1 heads (nil if not subscribed).
+
+	challenge common.Address           // Current challenge (zero if none).
+	protocol  bridge.ChallengeProtocol // Protocol of the current challenge.
+}
+
+func NewChallenger(
+	cfg Config,
+	l1TxMgr TxManager,
+	l1BridgeClient BridgeClient,
+	l1State EthState,
+	responders map[bridge.ChallengeProtocol]Responder,
+	newHeads <-chan struct{},
+) *Challenger {
+	return &Challenger{
+		cfg:            cfg,
+		l1TxMgr:        l1TxMgr,
+		l1BridgeClient: l1BridgeClient,
+		l1State:        l1State,
+		responders:     responders,
+		newHeads:       newHeads,
+	}
+}
+
+func (c *Challenger) Start(ctx context.Context, eg ErrGroup) error {
+	log.Info("Starting challenger...")
+	eg.Go(func() error { return c.start(ctx) })
+	log.Info("Challenger started")
+	return nil
+}
+
+// Advances challenger step-by-step, on every new L1 head.
+// Falls back to stepping on a fixed interval (e.g. if head syncing stalls).
+func (c *Challenger) start(ctx context.Context) error {
+	var (
+		interval = c.cfg.GetValidationInterval()
+		ticker   = time.NewTicker(interval)
+	)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.newHeads:
+			ticker.Reset(interval)
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Info("Aborting.")
+			return nil
+		}
+		if err := c.step(ctx); err != nil {
+			log.Errorf("Failed to advance challenge: %w", err, "challenge", c.challenge)
+		}
+	}
+}
+
+// Moves in the current challenge if it's our turn, or times it out if the opponent missed their deadline.
+func (c *Challenger) step(ctx context.Context) error {
+	if err := c.updateChallenge(ctx); err != nil {
+		return err
+	}
+	if c.challenge == (common.Address{}) {
+		return nil
+	}
+	responder, err := c.l1BridgeClient.GetChallengeResponder(ctx, c.challenge)
+	if err != nil {
+		return fmt.Errorf("failed to get current responder: %w", err)
+	}
+	if responder == c.cfg.GetAccountAddr() {
+		return c.respond(ctx)
+	}
+	isTimedOut, err := c.l1BridgeClient.IsChallengeTimedOut(ctx, c.challenge)
+	if err != nil {
+		return fmt.Errorf("failed to check for timeout: %w", err)
+	}
+	if !isTimedOut {
+		log.Info("Waiting for opponent to respond.", "challenge", c.challenge, "responder", responder)
+		return nil
+	}
+	return c.timeout(ctx)
+}
+
+// Tracks the challenge our staker is currently in, logging when challenges start and complete.
+func (c *Challenger) updateChallenge(ctx context.Context) error {
+	staker, err := c.l1BridgeClient.GetStaker(ctx, c.cfg.GetAccountAddr())
+	if err != nil {
+		return fmt.Errorf("failed to get staker: %w", err)
+	}
+	if staker.CurrentChallenge == c.challenge {
+		return nil
+	}
+	var protocol bridge.ChallengeProtocol
+	if staker.CurrentChallenge != (common.Address{}) {
+		protocol, err = c.l1BridgeClient.GetChallengeProtocol(ctx, staker.CurrentChallenge)
+		if err != nil {
+			return fmt.Errorf("failed to get challenge protocol: %w", err)
+		}
+	}
+	if c.challenge != (common.Address{}) {
+		// The loser's stake is removed on completion.
+		if staker.IsStaked {
+			log.Info("Won challenge", "challenge", c.challenge)
+		} else {
+			log.Error("Lost challenge; stake was slashed", "challenge", c.challenge)
+		}
+	}
+	if staker.CurrentChallenge != (common.Address{}) {
+		log.Info("Entered challenge", "challenge", staker.CurrentChallenge, "protocol", protocol)
+	}
+	c.challenge, c.protocol = staker.CurrentChallenge, protocol
+	return nil
+}
+
+// Makes our move in the current challenge, provided our deadline hasn't passed.
+func (c *Challenger) respond(ctx context.Context) error {
+	responder, ok := c.responders[c.protocol]
+	if !ok {
+		return fmt.Errorf("unsupported challenge protocol: %s", c.protocol)
+	}
+	deadline, err := c.l1BridgeClient.GetChallengeDeadline(ctx, c.challenge)
+	if err != nil {
+		return fmt.Errorf("failed to get deadline: %w", err)
+	}
+	head := c.l1State.Head().GetNumber()
+	if head > deadline {
+		log.Error("Missed challenge deadline; expecting to be timed out", "challenge", c.challenge, "deadline", deadline)
+		return nil
+	}
+	log.Info("Responding to challenge...", "challenge", c.challenge, "protocol", c.protocol, "blocks_left", deadline-head)
+	if err := responder.Respond(ctx, c.challenge); err != nil {
+		return fmt.Errorf("failed to respond: %w", err)
+	}
+	return nil
+}
+
+func (c *Challenger) timeout(ctx context.Context) error {
+	cCtx, cancel := context.WithTimeout(ctx, transactTimeout)
+	defer cancel()
+	log.Info("Opponent missed their deadline; timing out challenge...", "challenge", c.challenge)
+	receipt, err := c.l1TxMgr.Timeout(cCtx, c.challenge)
+	if err != nil {
+		return fmt.Errorf("failed to time out challenge: %w", err)
+	}
+	logReceipt(receipt, "Timed out challenge", "challenge", c.challenge)
+	return nil
+}
@@ -0,0 +1,152 @@
+package challenger
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
+)
+
+var (
+	testAccountAddr   = common.HexToAddress("0x01")
+	testOpponentAddr  = common.HexToAddress("0x02")
+	testChallengeAddr = common.HexToAddress("0xc0")
+)
+
+type testConfig struct{}
+
+func (testConfig) GetAccountAddr() common.Address       { return testAccountAddr }
+func (testConfig) GetValidationInterval() time.Duration { return time.Second }
+
+// testBridge stands in for both the rollup and the current challenge.
+type testBridge struct {
+	staker      bindings.IRollupStaker
+	protocol    bridge.ChallengeProtocol
+	protocolErr error
+	responder   common.Address
+	deadline    uint64
+	timedOut    bool
+	timeouts    int
+}
+
+func (b *testBridge) GetStaker(context.Context, common.Address) (bindings.IRollupStaker, error) {
+	return b.staker, nil
+}
+func (b *testBridge) GetChallengeResponder(context.Context, common.Address) (common.Address, error) {
+	return b.responder, nil
+}
+func (b *testBridge) IsChallengeTimedOut(context.Context, common.Address) (bool, error) {
+	return b.timedOut, nil
+}
+func (b *testBridge) GetChallengeDeadline(context.Context, common.Address) (uint64, error) {
+	return b.deadline, nil
+}
+func (b *testBridge) GetChallengeProtocol(context.Context, common.Address) (bridge.ChallengeProtocol, error) {
+	return b.protocol, b.protocolErr
+}
+func (b *testBridge) Timeout(context.Context, common.Address) (*ethTypes.Receipt, error) {
+	b.timeouts++
+	return &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful}, nil
+}
+
+type testResponder struct{ moves int }
+
+func (r *testResponder) Respond(context.Context, common.Address) error {
+	r.moves++
+	return nil
+}
+
+func testL1State(t *testing.T, head uint64) *eth.EthState {
+	l1State := eth.NewEthState()
+	require.NoError(t, l1State.OnLatest(context.Background(), &ethTypes.Header{Number: new(big.Int).SetUint64(head)}))
+	return l1State
+}
+
+func TestChallengerLifecycle(t *testing.T) {
+	var (
+		ctx        = context.Background()
+		rollup     = &testBridge{staker: bindings.IRollupStaker{IsStaked: true, AssertionID: big.NewInt(1)}, deadline: 10}
+		responder  = &testResponder{}
+		responders = map[bridge.ChallengeProtocol]Responder{bridge.SymChallengeProtocol: responder}
+		c          = NewChallenger(testConfig{}, rollup, rollup, testL1State(t, 1), responders, nil)
+	)
+	// No challenge.
+	require.NoError(t, c.step(ctx))
+	require.Zero(t, responder.moves)
+
+	// Our turn.
+	rollup.staker.CurrentChallenge = testChallengeAddr
+	rollup.responder = testAccountAddr
+	require.NoError(t, c.step(ctx))
+	require.Equal(t, testChallengeAddr, c.challenge)
+	require.Equal(t, 1, responder.moves)
+
+	// The opponent's turn, within their deadline.
+	rollup.responder = testOpponentAddr
+	require.NoError(t, c.step(ctx))
+	require.Equal(t, 1, responder.moves)
+	require.Zero(t, rollup.timeouts)
+
+	// The opponent missed their deadline.
+	rollup.timedOut = true
+	require.NoError(t, c.step(ctx))
+	require.Equal(t, 1, rollup.timeouts)
+
+	// The challenge completed.
+	rollup.staker.CurrentChallenge = common.Address{}
+	require.NoError(t, c.step(ctx))
+	require.Equal(t, common.Address{}, c.challenge)
+	require.Equal(t, 1, rollup.timeouts)
+}
+
+func TestChallengerUnsupportedProtocol(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		rollup = &testBridge{
+			staker:      bindings.IRollupStaker{IsStaked: true, CurrentChallenge: testChallengeAddr},
+			protocolErr: errors.New("unsupported challenge protocol"),
+			responder:   testAccountAddr,
+			deadline:    10,
+		}
+		responder  = &testResponder{}
+		responders = map[bridge.ChallengeProtocol]Responder{bridge.SymChallengeProtocol: responder}
+		c          = NewChallenger(testConfig{}, rollup, rollup, testL1State(t, 1), responders, nil)
+	)
+	// Challenges of unsupported protocols (i.e. asymmetric ones) aren't entered.
+	require.Error(t, c.step(ctx))
+	require.Equal(t, common.Address{}, c.challenge)
+	require.Zero(t, responder.moves)
+
+	// Nor played without a responder (e.g. symmetric ones, without an L2 proof endpoint).
+	rollup.protocolErr = nil
+	c.responders = map[bridge.ChallengeProtocol]Responder{}
+	require.Error(t, c.step(ctx))
+	require.Equal(t, testChallengeAddr, c.challenge)
+	require.Zero(t, responder.moves)
+}
+
+func TestChallengerMissedDeadline(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		rollup = &testBridge{
+			staker:    bindings.IRollupStaker{IsStaked: true, CurrentChallenge: testChallengeAddr},
+			responder: testAccountAddr,
+			deadline:  10,
+		}
+		responder  = &testResponder{}
+		responders = map[bridge.ChallengeProtocol]Responder{bridge.SymChallengeProtocol: responder}
+		c          = NewChallenger(testConfig{}, rollup, rollup, testL1State(t, 11), responders, nil)
+	)
+	// Moves past the deadline revert, so we don't attempt them.
+	require.NoError(t, c.step(ctx))
+	require.Zero(t, responder.moves)
+}
@@ -0,0 +1,107 @@
+package challenger
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+	"github.com/specularL2/specular/services/sidecar/proof"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
+	"github.com/specularL2/specular/services/sidecar/rollup/types"
+)
+
+type Config interface {
+	GetAccountAddr() common.Address
+	GetValidationInterval() time.Duration
+}
+
+type TxManager interface {
+	Timeout(ctx context.Context, challengeAddr common.Address) (*ethTypes.Receipt, error)
+}
+
+type BridgeClient interface {
+	GetStaker(context.Context, common.Address) (bindings.IRollupStaker, error)
+	GetChallengeResponder(ctx context.Context, challengeAddr common.Address) (common.Address, error)
+	IsChallengeTimedOut(ctx context.Context, challengeAddr common.Address) (bool, error)
+	GetChallengeDeadline(ctx context.Context, challengeAddr common.Address) (uint64, error)
+	GetChallengeProtocol(ctx context.Context, challengeAddr common.Address) (bridge.ChallengeProtocol, error)
+}
+
+type EthState interface {
+	Head() types.BlockID
+}
+
+// Makes protocol-specific moves (e.g. bisections, one-step proofs) in a challenge, when it's our turn.
+type Responder interface {
+	Respond(ctx context.Context, challengeAddr common.Address) error
+}
+
+type SymTxManager interface {
+	InitializeChallengeLength(ctx context.Context, challengeAddr common.Address, numSteps *big.Int) (*ethTypes.Receipt, error)
+	BisectExecution(
+		ctx context.Context,
+		challengeAddr common.Address,
+		bisection [][32]byte,
+		challengedSegmentIndex *big.Int,
+		prevBisection [][32]byte,
+		prevChallengedSegmentStart *big.Int,
+		prevChallengedSegmentLength *big.Int,
+	) (*ethTypes.Receipt, error)
+	VerifyOneStepProof(
+		ctx context.Context,
+		challengeAddr common.Address,
+		oneStepProof []byte,
+		txInclusionProof []byte,
+		vCtx bindings.VerificationContextLibRawContext,
+		challengedStepIndex *big.Int,
+		prevBisection [][32]byte,
+		prevChallengedSegmentStart *big.Int,
+		prevChallengedSegmentLength *big.Int,
+	) (*ethTypes.Receipt, error)
+}
+
+type SymBridgeClient interface {
+	GetAssertion(context.Context, *big.Int) (bindings.IRollupAssertion, error)
+	GetChallengeInfo(ctx context.Context, challengeAddr common.Address) (*bridge.ChallengeInfo, error)
+	GetBisectedEvents(
+		ctx context.Context, challengeAddr common.Address, fromBlock uint64,
+	) ([]*bindings.ISymChallengeBisected, error)
+	GetBisection(ctx context.Context, event *bindings.ISymChallengeBisected) ([][32]byte, error)
+}
+
+// Provides our execution states over L2 block ranges, by step, and one-step proofs of the steps between them.
+// Step 0 is the state before executing block `startBlockNum+1`, and the last step is the state after `endBlockNum`.
+// States at block boundaries (including the first and last) are hashed as the state commitments of the blocks they
+// follow, consistently with the challenged assertions.
+type StateSource interface {
+	NumSteps(ctx context.Context, startBlockNum, endBlockNum uint64) (uint64, error)
+	StateHashes(ctx context.Context, startBlockNum, endBlockNum uint64, steps []uint64) ([]common.Hash, error)
+	// Returns the one-step proof of the step from state `step` to the next.
+	OneStepProof(ctx context.Context, startBlockNum, endBlockNum uint64, step uint64) (*OneStepProof, error)
+}
+
+// A one-step proof, along with the context of the transaction executing the step.
+type OneStepProof struct {
+	Proof []byte
+	Ctx   bindings.VerificationContextLibRawContext
+}
+
+type ProofClient interface {
+	EnsureDialed(ctx context.Context) error
+	HeaderByHash(ctx context.Context, hash common.Hash) (*ethTypes.Header, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*ethTypes.Block, error)
+	CountStates(ctx context.Context, start, end uint64) (uint64, error)
+	GenerateStatesAt(ctx context.Context, start, end uint64, indices []uint64) ([]proof.ExecutionStateRef, error)
+	GenerateProof(ctx context.Context, state proof.ExecutionStateRef) ([]byte, error)
+}
+
+// Computes the state commitments of L2 blocks, as asserted.
+type StateCommitter interface {
+	StateCommitment(ctx context.Context, header *ethTypes.Header) (common.Hash, error)
+}
+
+type ErrGroup interface{ Go(f func() error) }
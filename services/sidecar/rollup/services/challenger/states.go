@@ -0,0 +1,162 @@
+package challenger
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+	"github.com/specularL2/specular/services/sidecar/proof"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+// Serves execution states generated remotely by an EL node (over the `proof` RPC namespace).
+// Only the requested states are generated (by re-executing the transactions containing them),
+// so the full trace of a challenged block range is never held in memory.
+// States at block boundaries are hashed as the state commitments of the blocks they follow (as asserted),
+// so that the start and end states match the challenged assertions; all others are hashed as VM states.
+// The number of states of the last requested block range is cached, as a challenge only ever spans one.
+type ProofStateSource struct {
+	client    ProofClient
+	committer StateCommitter
+
+	startBlockNum uint64
+	endBlockNum   uint64
+	numStates     uint64
+}
+
+func NewProofStateSource(client ProofClient, committer StateCommitter) *ProofStateSource {
+	return &ProofStateSource{client: client, committer: committer}
+}
+
+func (s *ProofStateSource) NumSteps(ctx context.Context, startBlockNum, endBlockNum uint64) (uint64, error) {
+	numStates, err := s.getNumStates(ctx, startBlockNum, endBlockNum)
+	if err != nil {
+		return 0, err
+	}
+	return numStates - 1, nil
+}
+
+func (s *ProofStateSource) StateHashes(
+	ctx context.Context, startBlockNum, endBlockNum uint64, steps []uint64,
+) ([]common.Hash, error) {
+	numStates, err := s.getNumStates(ctx, startBlockNum, endBlockNum)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		if step >= numStates {
+			return nil, fmt.Errorf("step %d out of range (%d steps)", step, numStates-1)
+		}
+	}
+	states, err := s.client.GenerateStatesAt(ctx, startBlockNum+1, endBlockNum+1, steps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate states: %w", err)
+	}
+	if len(states) != len(steps) {
+		return nil, fmt.Errorf("expected %d states, got %d", len(steps), len(states))
+	}
+	hashes := make([]common.Hash, len(states))
+	for i, state := range states {
+		hashes[i], err = s.stateHash(ctx, state, steps[i] == numStates-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// Returns the one-step proof of the step from state `step` to the next, generated by the EL node.
+// Steps starting at a block boundary are proven from the state before the block's first transaction, rather than
+// from its (asserted) state commitment.
+func (s *ProofStateSource) OneStepProof(
+	ctx context.Context, startBlockNum, endBlockNum uint64, step uint64,
+) (*OneStepProof, error) {
+	numStates, err := s.getNumStates(ctx, startBlockNum, endBlockNum)
+	if err != nil {
+		return nil, err
+	}
+	if step >= numStates-1 {
+		return nil, fmt.Errorf("step %d out of range (%d steps)", step, numStates-1)
+	}
+	states, err := s.client.GenerateStatesAt(ctx, startBlockNum+1, endBlockNum+1, []uint64{step})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+	if len(states) != 1 {
+		return nil, fmt.Errorf("expected 1 state, got %d", len(states))
+	}
+	state := states[0]
+	block, err := s.client.BlockByHash(ctx, state.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %s: %w", state.BlockHash, err)
+	}
+	if state.TransactionIdx >= uint64(len(block.Transactions())) {
+		return nil, fmt.Errorf("no transaction %d in block %s", state.TransactionIdx, state.BlockHash)
+	}
+	encodedTx, err := block.Transactions()[state.TransactionIdx].MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	osp, err := s.client.GenerateProof(ctx, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate proof: %w", err)
+	}
+	return &OneStepProof{
+		Proof: osp,
+		Ctx: bindings.VerificationContextLibRawContext{
+			EncodedTx:        encodedTx,
+			L2BlockCoinbase:  block.Coinbase(),
+			L2BlockNumber:    block.Number(),
+			L2BlockTimestamp: new(big.Int).SetUint64(block.Time()),
+		},
+	}, nil
+}
+
+// Returns the hash of the given state: the state commitment of the block it follows if it's at a block boundary
+// (i.e. it's the end state, or precedes the first transaction of a block), and its VM state hash otherwise.
+func (s *ProofStateSource) stateHash(
+	ctx context.Context, state proof.ExecutionStateRef, isEnd bool,
+) (common.Hash, error) {
+	if !isEnd && (state.TransactionIdx != 0 || state.StepIdx != 0) {
+		return state.VMHash, nil
+	}
+	header, err := s.client.HeaderByHash(ctx, state.BlockHash)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get block %s: %w", state.BlockHash, err)
+	}
+	if !isEnd {
+		header, err = s.client.HeaderByHash(ctx, header.ParentHash)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to get parent of block %s: %w", state.BlockHash, err)
+		}
+	}
+	if header.Root != state.VMHash {
+		return common.Hash{}, fmt.Errorf("state root of block %s does not match generated state", header.Hash())
+	}
+	hash, err := s.committer.StateCommitment(ctx, header)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to compute state commitment: %w", err)
+	}
+	return hash, nil
+}
+
+// Returns the number of states executing L2 blocks (startBlockNum, endBlockNum].
+func (s *ProofStateSource) getNumStates(ctx context.Context, startBlockNum, endBlockNum uint64) (uint64, error) {
+	if s.numStates != 0 && s.startBlockNum == startBlockNum && s.endBlockNum == endBlockNum {
+		return s.numStates, nil
+	}
+	if err := s.client.EnsureDialed(ctx); err != nil {
+		return 0, err
+	}
+	numStates, err := s.client.CountStates(ctx, startBlockNum+1, endBlockNum+1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count states: %w", err)
+	}
+	if numStates == 0 {
+		return 0, fmt.Errorf("no states generated for blocks (%d, %d]", startBlockNum, endBlockNum)
+	}
+	s.startBlockNum, s.endBlockNum, s.numStates = startBlockNum, endBlockNum, numStates
+	return numStates, nil
+}
@@ -0,0 +1,110 @@
+package challenger
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/services/sidecar/proof"
+)
+
+// testProofClient generates two states per block (before and after its single transaction's first step),
+// plus the end state. Block `n` has hash `n` and state root `1000+n`, and proofs are the VM hashes they start from.
+type testProofClient struct {
+	counts    [][2]uint64
+	requested []uint64
+}
+
+func testBlockHash(num uint64) common.Hash { return common.BigToHash(new(big.Int).SetUint64(num)) }
+func testStateRoot(num uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(1000 + num))
+}
+
+func (c *testProofClient) EnsureDialed(context.Context) error { return nil }
+func (c *testProofClient) HeaderByHash(_ context.Context, hash common.Hash) (*types.Header, error) {
+	num := hash.Big().Uint64()
+	return &types.Header{ParentHash: testBlockHash(num - 1), Root: testStateRoot(num)}, nil
+}
+func (c *testProofClient) BlockByHash(_ context.Context, hash common.Hash) (*types.Block, error) {
+	header := &types.Header{Number: hash.Big(), Time: 100 + hash.Big().Uint64()}
+	tx := types.NewTx(&types.LegacyTx{Nonce: hash.Big().Uint64()})
+	return types.NewBlockWithHeader(header).WithBody([]*types.Transaction{tx}, nil), nil
+}
+func (c *testProofClient) GenerateProof(_ context.Context, state proof.ExecutionStateRef) ([]byte, error) {
+	return state.VMHash.Bytes(), nil
+}
+func (c *testProofClient) CountStates(_ context.Context, start, end uint64) (uint64, error) {
+	c.counts = append(c.counts, [2]uint64{start, end})
+	return 2*(end-start) + 1, nil
+}
+func (c *testProofClient) GenerateStatesAt(
+	_ context.Context, start, end uint64, indices []uint64,
+) ([]proof.ExecutionStateRef, error) {
+	var states []proof.ExecutionStateRef
+	for _, i := range indices {
+		c.requested = append(c.requested, i)
+		num := start + i/2
+		switch {
+		case num == end:
+			states = append(states, proof.ExecutionStateRef{
+				VMHash: testStateRoot(end - 1), BlockHash: testBlockHash(end - 1), TransactionIdx: 1,
+			})
+		case i%2 == 0:
+			states = append(states, proof.ExecutionStateRef{VMHash: testStateRoot(num - 1), BlockHash: testBlockHash(num)})
+		default:
+			states = append(states, proof.ExecutionStateRef{
+				VMHash: common.HexToHash("0xff"), BlockHash: testBlockHash(num), StepIdx: 1,
+			})
+		}
+	}
+	return states, nil
+}
+
+// testStateCommitter commits to the state root alone.
+type testStateCommitter struct{}
+
+func (testStateCommitter) StateCommitment(_ context.Context, header *types.Header) (common.Hash, error) {
+	return crypto.Keccak256Hash(header.Root[:]), nil
+}
+
+func TestProofStateSource(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = &testProofClient{}
+		states = NewProofStateSource(client, testStateCommitter{})
+	)
+	// Assertions span blocks (10, 13], i.e. blocks [11, 14) are executed.
+	numSteps, err := states.NumSteps(ctx, 10, 13)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), numSteps)
+	hashes, err := states.StateHashes(ctx, 10, 13, []uint64{0, 1, 2, 6})
+	require.NoError(t, err)
+	// Block-boundary states are hashed as the state commitments of the blocks they follow.
+	require.Equal(t, []common.Hash{
+		crypto.Keccak256Hash(testStateRoot(10).Bytes()),
+		common.HexToHash("0xff"),
+		crypto.Keccak256Hash(testStateRoot(11).Bytes()),
+		crypto.Keccak256Hash(testStateRoot(13).Bytes()),
+	}, hashes)
+	// States are counted once, and only the requested ones are generated.
+	require.Equal(t, [][2]uint64{{11, 14}}, client.counts)
+	require.Equal(t, []uint64{0, 1, 2, 6}, client.requested)
+
+	_, err = states.StateHashes(ctx, 10, 13, []uint64{7})
+	require.Error(t, err)
+
+	// Steps are proven in the context of the block and transaction they're part of.
+	osp, err := states.OneStepProof(ctx, 10, 13, 3)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToHash("0xff").Bytes(), osp.Proof)
+	require.Equal(t, big.NewInt(12), osp.Ctx.L2BlockNumber)
+	require.Equal(t, big.NewInt(112), osp.Ctx.L2BlockTimestamp)
+	// The end state is past the last step.
+	_, err = states.OneStepProof(ctx, 10, 13, 6)
+	require.Error(t, err)
+}
@@ -0,0 +1,304 @@
+package challenger
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+	"github.com/specularL2/specular/services/sidecar/utils/log"
+)
+
+// Plays the symmetric challenge protocol (`ISymChallenge`):
+// both players commit to a number of steps, then alternate bisecting the first segment they disagree with,
+// until a single disputed step remains, which is then proven by whoever disagrees with its end state.
+type SymResponder struct {
+	accountAddr    common.Address
+	l1TxMgr        SymTxManager
+	l1BridgeClient SymBridgeClient
+	states         StateSource
+
+	challenges map[common.Address]*symChallenge // Cached per challenge address.
+}
+
+// A symmetric challenge, from our point of view.
+type symChallenge struct {
+	// L2 blocks (startBlockNum, endBlockNum] executed by the challenged assertions.
+	startBlockNum uint64
+	endBlockNum   uint64
+	// State commitments of the (agreed-upon) parent assertion, and of the defender's and challenger's assertions.
+	startStateHash common.Hash
+	endStateHashes [2]common.Hash
+	ourIndex       int    // Our index into `endStateHashes` (0 if we're the defender).
+	numSteps       uint64 // Number of steps we executed.
+	creationBlock  uint64 // L1 block the challenge was created in.
+	// Step the challenge was narrowed down to, once we submitted a one-step proof of it (nil until then).
+	provenStep *uint64
+}
+
+func (c *symChallenge) ourEndStateHash() common.Hash { return c.endStateHashes[c.ourIndex] }
+
+func NewSymResponder(
+	accountAddr common.Address,
+	l1TxMgr SymTxManager,
+	l1BridgeClient SymBridgeClient,
+	states StateSource,
+) *SymResponder {
+	return &SymResponder{
+		accountAddr:    accountAddr,
+		l1TxMgr:        l1TxMgr,
+		l1BridgeClient: l1BridgeClient,
+		states:         states,
+		challenges:     map[common.Address]*symChallenge{},
+	}
+}
+
+// Makes our next move: commits to our number of steps if we haven't yet, and otherwise responds to the latest bisection.
+func (r *SymResponder) Respond(ctx context.Context, challengeAddr common.Address) error {
+	challenge, err := r.getChallenge(ctx, challengeAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get challenge: %w", err)
+	}
+	events, err := r.l1BridgeClient.GetBisectedEvents(ctx, challengeAddr, challenge.creationBlock)
+	if err != nil {
+		return fmt.Errorf("failed to get bisections: %w", err)
+	}
+	// The initial bisection is only emitted once both players committed to a number of steps.
+	if len(events) == 0 {
+		return r.initializeChallengeLength(ctx, challengeAddr, challenge)
+	}
+	prev, err := r.getBisection(ctx, challenge, events)
+	if err != nil {
+		return fmt.Errorf("failed to get current bisection: %w", err)
+	}
+	return r.bisect(ctx, challengeAddr, challenge, prev)
+}
+
+func (r *SymResponder) initializeChallengeLength(
+	ctx context.Context, challengeAddr common.Address, challenge *symChallenge,
+) error {
+	cCtx, cancel := context.WithTimeout(ctx, transactTimeout)
+	defer cancel()
+	log.Info("Initializing challenge length...", "challenge", challengeAddr, "numSteps", challenge.numSteps)
+	receipt, err := r.l1TxMgr.InitializeChallengeLength(cCtx, challengeAddr, new(big.Int).SetUint64(challenge.numSteps))
+	if err != nil {
+		return fmt.Errorf("failed to initialize challenge length: %w", err)
+	}
+	logReceipt(receipt, "Initialized challenge length", "challenge", challengeAddr)
+	return nil
+}
+
+// Bisects the first sub-segment of `prev` we disagree with, or proves it if it's a single step.
+func (r *SymResponder) bisect(
+	ctx context.Context, challengeAddr common.Address, challenge *symChallenge, prev *Bisection,
+) error {
+	ours, err := r.stateHashes(ctx, challenge, prev.steps())
+	if err != nil {
+		return err
+	}
+	// The first hash is always agreed upon.
+	index := 0
+	for i := 1; i < len(prev.Hashes); i++ {
+		if prev.Hashes[i] != ours[i] {
+			index = i
+			break
+		}
+	}
+	if index == 0 {
+		return fmt.Errorf("no disagreement with current bisection")
+	}
+	start, length := prev.subSegment(index)
+	if length <= 1 {
+		return r.proveStep(ctx, challengeAddr, challenge, prev, index, start)
+	}
+	bisection, err := r.stateHashes(ctx, challenge, bisectionSteps(start, length))
+	if err != nil {
+		return err
+	}
+	bisection[0] = prev.Hashes[index-1]
+	cCtx, cancel := context.WithTimeout(ctx, transactTimeout)
+	defer cancel()
+	log.Info("Bisecting execution...", "challenge", challengeAddr, "start", start, "length", length)
+	receipt, err := r.l1TxMgr.BisectExecution(
+		cCtx,
+		challengeAddr,
+		toBytes32s(bisection),
+		big.NewInt(int64(index)),
+		toBytes32s(prev.Hashes),
+		new(big.Int).SetUint64(prev.Start),
+		new(big.Int).SetUint64(prev.Length),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to bisect execution: %w", err)
+	}
+	logReceipt(receipt, "Bisected execution", "challenge", challengeAddr, "start", start, "length", length)
+	return nil
+}
+
+// Submits a one-step proof of `step`, the single-step sub-segment of `prev` at `index` we disagree with.
+// The challenge is won if the proven end state differs from our opponent's. The proof is only submitted once:
+// if it's rejected (e.g. the step isn't supported by the verifier), the challenge is left to time out.
+func (r *SymResponder) proveStep(
+	ctx context.Context, challengeAddr common.Address, challenge *symChallenge, prev *Bisection, index int, step uint64,
+) error {
+	if challenge.provenStep != nil {
+		log.Warn("Already submitted one-step proof; unable to complete challenge.", "challenge", challengeAddr)
+		return nil
+	}
+	log.Info("Narrowed challenge down to a single step", "challenge", challengeAddr, "step", step)
+	osp, err := r.states.OneStepProof(ctx, challenge.startBlockNum, challenge.endBlockNum, step)
+	if err != nil {
+		return fmt.Errorf("failed to generate one-step proof: %w", err)
+	}
+	cCtx, cancel := context.WithTimeout(ctx, transactTimeout)
+	defer cancel()
+	log.Info("Verifying one-step proof...", "challenge", challengeAddr, "step", step)
+	receipt, err := r.l1TxMgr.VerifyOneStepProof(
+		cCtx,
+		challengeAddr,
+		osp.Proof,
+		txInclusionProof(osp.Ctx),
+		osp.Ctx,
+		big.NewInt(int64(index)),
+		toBytes32s(prev.Hashes),
+		new(big.Int).SetUint64(prev.Start),
+		new(big.Int).SetUint64(prev.Length),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to verify one-step proof: %w", err)
+	}
+	challenge.provenStep = &step
+	logReceipt(receipt, "Verified one-step proof", "challenge", challengeAddr, "step", step)
+	return nil
+}
+
+// Returns the inclusion proof of the transaction of a one-step proof: the hash of its context
+// (see `VerificationContextLib.txContextHash`), followed by the DA-specific membership proof, which is empty
+// (the sequencer inbox doesn't support inclusion proofs yet).
+func txInclusionProof(vCtx bindings.VerificationContextLibRawContext) []byte {
+	return crypto.Keccak256(
+		vCtx.L2BlockCoinbase.Bytes(),
+		common.BigToHash(vCtx.L2BlockNumber).Bytes(),
+		common.BigToHash(vCtx.L2BlockTimestamp).Bytes(),
+	)
+}
+
+// Returns the challenge at the given address, loading it on first use.
+func (r *SymResponder) getChallenge(ctx context.Context, challengeAddr common.Address) (*symChallenge, error) {
+	if challenge, ok := r.challenges[challengeAddr]; ok {
+		return challenge, nil
+	}
+	info, err := r.l1BridgeClient.GetChallengeInfo(ctx, challengeAddr)
+	if err != nil {
+		return nil, err
+	}
+	var ourIndex int
+	switch r.accountAddr {
+	case info.Players[0]:
+		ourIndex = 0
+	case info.Players[1]:
+		ourIndex = 1
+	default:
+		return nil, fmt.Errorf("not a player in challenge %s", challengeAddr)
+	}
+	challenge := &symChallenge{ourIndex: ourIndex, creationBlock: info.CreationBlock}
+	var blockNum uint64
+	for i, assertionID := range info.AssertionIDs {
+		assertion, err := r.l1BridgeClient.GetAssertion(ctx, assertionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get assertion: %w", err)
+		}
+		challenge.endStateHashes[i] = assertion.StateCommitment
+		blockNum = assertion.BlockNum.Uint64() // Siblings have the same block number.
+		if i == 0 {
+			parent, err := r.l1BridgeClient.GetAssertion(ctx, assertion.Parent)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get parent assertion: %w", err)
+			}
+			challenge.startBlockNum = parent.BlockNum.Uint64()
+			challenge.startStateHash = parent.StateCommitment
+		}
+	}
+	challenge.endBlockNum = blockNum
+	challenge.numSteps, err = r.states.NumSteps(ctx, challenge.startBlockNum, challenge.endBlockNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get number of steps: %w", err)
+	}
+	r.challenges[challengeAddr] = challenge
+	return challenge, nil
+}
+
+// Reconstructs the current bisection from the latest Bisected event.
+func (r *SymResponder) getBisection(
+	ctx context.Context, challenge *symChallenge, events []*bindings.ISymChallengeBisected,
+) (*Bisection, error) {
+	var (
+		event  = events[len(events)-1]
+		start  = event.ChallengedSegmentStart.Uint64()
+		length = event.ChallengedSegmentLength.Uint64()
+	)
+	// The initial bisection is emitted when initializing the challenge length, spanning the end state with fewest steps.
+	if len(events) == 1 {
+		for _, endStateHash := range challenge.endStateHashes {
+			bisection := initialBisection(challenge.startStateHash, endStateHash, length)
+			if bisection.Hash() == event.ChallengeState {
+				return bisection, nil
+			}
+		}
+		return nil, fmt.Errorf("initial bisection matches neither end state")
+	}
+	hashes, err := r.l1BridgeClient.GetBisection(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	bisection := &Bisection{Hashes: toHashes(hashes), Start: start, Length: length}
+	if bisection.Hash() != event.ChallengeState {
+		return nil, fmt.Errorf("bisection inconsistent with challenge state")
+	}
+	return bisection, nil
+}
+
+// Returns our state hashes at the given steps.
+// The first and last steps are the (agreed-upon) start state and our end state, respectively:
+// the state commitments of the challenged assertions, as block-boundary states are hashed by the `StateSource`.
+func (r *SymResponder) stateHashes(ctx context.Context, challenge *symChallenge, steps []uint64) ([]common.Hash, error) {
+	var (
+		hashes   = make([]common.Hash, len(steps))
+		queried  []uint64
+		indexOfs []int
+	)
+	for i, step := range steps {
+		switch {
+		case step == 0:
+			hashes[i] = challenge.startStateHash
+		case step >= challenge.numSteps:
+			hashes[i] = challenge.ourEndStateHash()
+		default:
+			queried = append(queried, step)
+			indexOfs = append(indexOfs, i)
+		}
+	}
+	if len(queried) == 0 {
+		return hashes, nil
+	}
+	states, err := r.states.StateHashes(ctx, challenge.startBlockNum, challenge.endBlockNum, queried)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state hashes: %w", err)
+	}
+	for i, hash := range states {
+		hashes[indexOfs[i]] = hash
+	}
+	return hashes, nil
+}
+
+func logReceipt(receipt *types.Receipt, msg string, ctx ...interface{}) {
+	if receipt.Status == types.ReceiptStatusFailed {
+		log.Error("Tx successfully published but reverted", "tx_hash", receipt.TxHash)
+		return
+	}
+	log.Info(msg, ctx...)
+}
@@ -0,0 +1,260 @@
+package challenger
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
+)
+
+// testSymChallenge models `SymChallenge.sol` (and the rollup assertions it was opened for), with players taking turns.
+type testSymChallenge struct {
+	players       [2]common.Address // Defender, challenger.
+	creationBlock uint64            // L1 block the challenge was created in (and its events emitted from).
+	turn          int               // Index of the current responder.
+	assertions    []bindings.IRollupAssertion
+	numSteps      uint64
+	bisectionHash common.Hash
+	events        []*bindings.ISymChallengeBisected
+	bisections    map[common.Hash][][32]byte // By tx hash.
+	// The honest player's states and end state commitment, by which one-step proofs are verified.
+	honestStates   testStateSource
+	honestEndState common.Hash
+	winner         *int // Index of the winner, once the challenge completes (nil until then).
+}
+
+var errChallengeReverted = errors.New("reverted")
+
+func (c *testSymChallenge) emit(bisection *Bisection) {
+	c.bisectionHash = bisection.Hash()
+	txHash := common.BigToHash(big.NewInt(int64(len(c.events) + 1)))
+	c.bisections[txHash] = toBytes32s(bisection.Hashes)
+	c.events = append(c.events, &bindings.ISymChallengeBisected{
+		ChallengeState:          bisection.Hash(),
+		ChallengedSegmentStart:  new(big.Int).SetUint64(bisection.Start),
+		ChallengedSegmentLength: new(big.Int).SetUint64(bisection.Length),
+		Raw:                     ethTypes.Log{TxHash: txHash, BlockNumber: c.creationBlock + uint64(len(c.events))},
+	})
+}
+
+// A player's view of the challenge, sending txs from their account.
+type testSymPlayer struct {
+	*testSymChallenge
+	index int
+}
+
+func (p *testSymPlayer) InitializeChallengeLength(
+	_ context.Context, _ common.Address, numSteps *big.Int,
+) (*ethTypes.Receipt, error) {
+	if p.turn != p.index || p.bisectionHash != (common.Hash{}) {
+		return nil, errChallengeReverted
+	}
+	// The defender commits first.
+	if p.index == 0 {
+		p.numSteps = numSteps.Uint64()
+		p.turn = 1
+		return &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful}, nil
+	}
+	// The end state of the player with fewest steps is challenged, by the other player.
+	end := p.assertions[1].StateCommitment
+	if numSteps.Uint64() < p.numSteps {
+		p.numSteps = numSteps.Uint64()
+		end = p.assertions[2].StateCommitment
+		p.turn = 0
+	}
+	p.emit(initialBisection(p.assertions[0].StateCommitment, end, p.numSteps))
+	return &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful}, nil
+}
+
+func (p *testSymPlayer) BisectExecution(
+	_ context.Context,
+	_ common.Address,
+	bisection [][32]byte,
+	challengedSegmentIndex *big.Int,
+	prevBisection [][32]byte,
+	prevChallengedSegmentStart *big.Int,
+	prevChallengedSegmentLength *big.Int,
+) (*ethTypes.Receipt, error) {
+	// `postInitialization`: both players must have committed to a number of steps first.
+	if p.bisectionHash == (common.Hash{}) {
+		return nil, errChallengeReverted
+	}
+	var (
+		prev  = &Bisection{toHashes(prevBisection), prevChallengedSegmentStart.Uint64(), prevChallengedSegmentLength.Uint64()}
+		index = int(challengedSegmentIndex.Uint64())
+	)
+	if p.turn != p.index || prev.Hash() != p.bisectionHash || index < 1 || index >= len(prevBisection) ||
+		bisection[0] != prevBisection[index-1] || bisection[len(bisection)-1] == prevBisection[index] {
+		return nil, errChallengeReverted
+	}
+	start, length := prev.subSegment(index)
+	if length <= 1 || len(bisection) != len(bisectionSteps(start, length)) {
+		return nil, errChallengeReverted
+	}
+	p.turn = 1 - p.index
+	p.emit(&Bisection{toHashes(bisection), start, length})
+	return &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful}, nil
+}
+
+func (p *testSymPlayer) VerifyOneStepProof(
+	_ context.Context,
+	_ common.Address,
+	oneStepProof []byte,
+	_ []byte,
+	_ bindings.VerificationContextLibRawContext,
+	challengedStepIndex *big.Int,
+	prevBisection [][32]byte,
+	prevChallengedSegmentStart *big.Int,
+	prevChallengedSegmentLength *big.Int,
+) (*ethTypes.Receipt, error) {
+	var (
+		prev  = &Bisection{toHashes(prevBisection), prevChallengedSegmentStart.Uint64(), prevChallengedSegmentLength.Uint64()}
+		index = int(challengedStepIndex.Uint64())
+	)
+	if p.turn != p.index || prev.Hash() != p.bisectionHash || index < 1 || index >= len(prevBisection) {
+		return nil, errChallengeReverted
+	}
+	step, length := prev.subSegment(index)
+	// The proof must start from the (agreed-upon) state before the step, which is executed honestly.
+	if length > 1 || common.BytesToHash(oneStepProof) != prevBisection[index-1] {
+		return nil, errChallengeReverted
+	}
+	endState := p.honestEndState
+	if step+1 < uint64(len(p.honestStates)-1) {
+		endState = p.honestStates[step+1]
+	}
+	if endState != prevBisection[index] {
+		p.winner = &p.index
+	}
+	return &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful}, nil
+}
+
+func (c *testSymChallenge) GetAssertion(_ context.Context, id *big.Int) (bindings.IRollupAssertion, error) {
+	return c.assertions[id.Uint64()], nil
+}
+func (c *testSymChallenge) GetChallengeInfo(context.Context, common.Address) (*bridge.ChallengeInfo, error) {
+	return &bridge.ChallengeInfo{
+		Players:       c.players,
+		AssertionIDs:  [2]*big.Int{big.NewInt(1), big.NewInt(2)},
+		CreationBlock: c.creationBlock,
+	}, nil
+}
+func (c *testSymChallenge) GetBisectedEvents(
+	_ context.Context, _ common.Address, fromBlock uint64,
+) ([]*bindings.ISymChallengeBisected, error) {
+	var events []*bindings.ISymChallengeBisected
+	for _, event := range c.events {
+		if event.Raw.BlockNumber >= fromBlock {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+func (c *testSymChallenge) GetBisection(_ context.Context, event *bindings.ISymChallengeBisected) ([][32]byte, error) {
+	return c.bisections[event.Raw.TxHash], nil
+}
+
+// testStateSource serves a fixed sequence of state hashes, and proves steps by the state they start from.
+type testStateSource []common.Hash
+
+func (s testStateSource) NumSteps(context.Context, uint64, uint64) (uint64, error) {
+	return uint64(len(s) - 1), nil
+}
+func (s testStateSource) StateHashes(_ context.Context, _, _ uint64, steps []uint64) ([]common.Hash, error) {
+	hashes := make([]common.Hash, len(steps))
+	for i, step := range steps {
+		hashes[i] = s[step]
+	}
+	return hashes, nil
+}
+func (s testStateSource) OneStepProof(_ context.Context, _, _ uint64, step uint64) (*OneStepProof, error) {
+	return &OneStepProof{
+		Proof: s[step].Bytes(),
+		Ctx:   bindings.VerificationContextLibRawContext{L2BlockNumber: common.Big0, L2BlockTimestamp: common.Big0},
+	}, nil
+}
+
+// Returns states for `numSteps` steps, diverging from the honest ones at step `divergence` (if non-zero).
+func testStates(numSteps int, divergence int) testStateSource {
+	states := make(testStateSource, numSteps+1)
+	for i := range states {
+		states[i] = common.BigToHash(big.NewInt(int64(i + 1)))
+		if divergence > 0 && i >= divergence {
+			states[i][0] = 0xff
+		}
+	}
+	return states
+}
+
+// Plays a challenge between a defender and challenger until it completes, with the player at index `honest` (whose
+// end state commitment is honest) executing steps honestly. Returns the single step proven, and the winner's index.
+func playSymChallenge(t *testing.T, defenderStates, challengerStates testStateSource, honest int) (uint64, int) {
+	var (
+		ctx           = context.Background()
+		challengeAddr = common.HexToAddress("0xc0")
+		players       = [2]common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+		states        = [2]testStateSource{defenderStates, challengerStates}
+		challenge     = &testSymChallenge{players: players, creationBlock: 100, bisections: map[common.Hash][][32]byte{}}
+		responders    [2]*SymResponder
+	)
+	// The parent assertion (committing to the agreed-upon start state), and the defender's and challenger's.
+	challenge.assertions = []bindings.IRollupAssertion{
+		{BlockNum: big.NewInt(10), StateCommitment: defenderStates[0]},
+		{BlockNum: big.NewInt(12), StateCommitment: common.Hash{0xd0}},
+		{BlockNum: big.NewInt(12), StateCommitment: common.Hash{0xc0}},
+	}
+	challenge.assertions[1].Parent = common.Big0
+	challenge.assertions[2].Parent = common.Big0
+	challenge.honestStates = states[honest]
+	challenge.honestEndState = challenge.assertions[1+honest].StateCommitment
+	for i := range responders {
+		player := &testSymPlayer{challenge, i}
+		responders[i] = NewSymResponder(players[i], player, challenge, states[i])
+	}
+	for moves := 0; moves < 100; moves++ {
+		turn := challenge.turn
+		require.NoError(t, responders[turn].Respond(ctx, challengeAddr))
+		proven := responders[turn].challenges[challengeAddr].provenStep
+		if proven == nil {
+			continue
+		}
+		if challenge.winner != nil {
+			return *proven, *challenge.winner
+		}
+		// The proof didn't win, so the prover's opponent times them out.
+		return *proven, 1 - turn
+	}
+	t.Fatal("challenge did not converge")
+	return 0, 0
+}
+
+func TestSymChallengeNarrowsDownToDivergentStep(t *testing.T) {
+	for _, divergence := range []int{1, 2, 7, 12, 20} {
+		proven, winner := playSymChallenge(t, testStates(20, 0), testStates(20, divergence), 0)
+		// The single step proven is the one transitioning into the first divergent state.
+		require.Equal(t, uint64(divergence-1), proven, "divergence %d", divergence)
+		require.Equal(t, 0, winner, "divergence %d", divergence)
+	}
+}
+
+func TestSymChallengeDifferentNumSteps(t *testing.T) {
+	// The challenger claims fewer steps, so the defender challenges the challenger's end state.
+	proven, winner := playSymChallenge(t, testStates(20, 0), testStates(13, 0), 0)
+	require.Equal(t, uint64(12), proven)
+	require.Equal(t, 0, winner)
+}
+
+func TestSymChallengeDishonestDefenderLoses(t *testing.T) {
+	for _, divergence := range []int{1, 7, 20} {
+		proven, winner := playSymChallenge(t, testStates(20, divergence), testStates(20, 0), 1)
+		require.Equal(t, uint64(divergence-1), proven, "divergence %d", divergence)
+		require.Equal(t, 1, winner, "divergence %d", divergence)
+	}
+}
@@ -1,17 +1,18 @@
 package services
 
 import (
-	"crypto/ecdsa"
 	"math/big"
 	"time"
 
 	"github.com/specularL2/specular/services/sidecar/utils/log"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/urfave/cli/v2"
 
+	"github.com/specularL2/specular/services/sidecar/rollup/derivation"
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
+	"github.com/specularL2/specular/services/sidecar/rollup/signer"
+	"github.com/specularL2/specular/services/sidecar/rollup/types"
 	"github.com/specularL2/specular/services/sidecar/utils/fmt"
 )
 
@@ -22,6 +23,7 @@ type SystemConfig struct {
 	L2Config           `toml:"l2,omitempty"`
 	DisseminatorConfig `toml:"disseminator,omitempty"`
 	ValidatorConfig    `toml:"validator,omitempty"`
+	MetricsConfig      `toml:"metrics,omitempty"`
 	Verbosity          log.Lvl `toml:"verbosity,omitempty"`
 }
 
@@ -30,6 +32,7 @@ func (c *SystemConfig) L1() L1Config                     { return c.L1Config }
 func (c *SystemConfig) L2() L2Config                     { return c.L2Config }
 func (c *SystemConfig) Disseminator() DisseminatorConfig { return c.DisseminatorConfig }
 func (c *SystemConfig) Validator() ValidatorConfig       { return c.ValidatorConfig }
+func (c *SystemConfig) Metrics() MetricsConfig           { return c.MetricsConfig }
 
 func (c *SystemConfig) validate() error {
 	if !(c.DisseminatorConfig.IsEnabled || c.ValidatorConfig.IsEnabled) {
@@ -50,17 +53,24 @@ func ParseSystemConfig(cliCtx *cli.Context) (*SystemConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse protocol config: %w", err)
 	}
-	var (
-		l1ChainID = protocolCfg.GetRollup().L1ChainID
-		cfg       = &SystemConfig{
-			ProtocolConfig:     protocolCfg,
-			L1Config:           newL1ConfigFromCLI(cliCtx),
-			L2Config:           newL2ConfigFromCLI(cliCtx),
-			DisseminatorConfig: newDisseminatorConfigFromCLI(cliCtx, l1ChainID),
-			ValidatorConfig:    newValidatorConfigFromCLI(cliCtx, l1ChainID),
-			Verbosity:          log.Lvl(cliCtx.Int(VerbosityFlag.Name)),
-		}
-	)
+	l1ChainID := protocolCfg.GetRollup().L1ChainID
+	disseminatorCfg, err := newDisseminatorConfigFromCLI(cliCtx, l1ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse disseminator config: %w", err)
+	}
+	validatorCfg, err := newValidatorConfigFromCLI(cliCtx, l1ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse validator config: %w", err)
+	}
+	cfg := &SystemConfig{
+		ProtocolConfig:     protocolCfg,
+		L1Config:           newL1ConfigFromCLI(cliCtx),
+		L2Config:           newL2ConfigFromCLI(cliCtx),
+		DisseminatorConfig: disseminatorCfg,
+		ValidatorConfig:    validatorCfg,
+		MetricsConfig:      newMetricsConfigFromCLI(cliCtx),
+		Verbosity:          log.Lvl(cliCtx.Int(VerbosityFlag.Name)),
+	}
 	// Validate.
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("failed to validate config: %w", err)
@@ -88,37 +98,50 @@ func (c ProtocolConfig) GetSeqWindowSize() uint64              { return c.Rollup
 func (c ProtocolConfig) GetSequencerInboxAddr() common.Address { return c.Rollup.BatchInboxAddress }
 func (c ProtocolConfig) GetL1ChainID() uint64                  { return c.Rollup.L1ChainID.Uint64() }
 func (c ProtocolConfig) GetL2ChainID() uint64                  { return c.Rollup.L2ChainID.Uint64() }
-func (c ProtocolConfig) GetL1OracleAddr() common.Address {
-	// TODO: import from package or config
-	return common.HexToAddress("0x2A00000000000000000000000000000000000010")
-}
+func (c ProtocolConfig) GetL1OracleAddr() common.Address       { return types.L1OracleAddr }
 
 // L1 configuration
 type L1Config struct {
 	Endpoint           string `toml:"endpoint,omitempty"` // L1 API endpoint
 	SubmissionEndpoint string `toml:"submission_endpoint,omitempty"`
+	BeaconEndpoint     string `toml:"beacon_endpoint,omitempty"` // L1 beacon API endpoint (serving blobs)
 }
 
 func newL1ConfigFromCLI(cliCtx *cli.Context) L1Config {
 	return L1Config{
 		Endpoint:           cliCtx.String(l1EndpointFlag.Name),
 		SubmissionEndpoint: cliCtx.String(l1SubmissionEndpointFlag.Name),
+		BeaconEndpoint:     cliCtx.String(l1BeaconEndpointFlag.Name),
 	}
 }
 
-func (c L1Config) GetEndpoint() string { return c.Endpoint }
+func (c L1Config) GetEndpoint() string       { return c.Endpoint }
+func (c L1Config) GetBeaconEndpoint() string { return c.BeaconEndpoint }
 
 // L2 configuration
 type L2Config struct {
-	Endpoint string `toml:"endpoint,omitempty"` // L2 API endpoint
-	ChainID  uint64 `toml:"chainid,omitempty"`  // L2 chain ID
+	Endpoint      string        `toml:"endpoint,omitempty"`       // L2 API endpoint
+	ProofEndpoint string        `toml:"proof_endpoint,omitempty"` // L2 API endpoint serving the `proof` namespace
+	ChainID       uint64        `toml:"chainid,omitempty"`        // L2 chain ID
+	PollInterval  time.Duration `toml:"poll_interval,omitempty"`  // Time between polls for new L2 heads
 }
 
 func newL2ConfigFromCLI(cliCtx *cli.Context) L2Config {
-	return L2Config{Endpoint: cliCtx.String(l2EndpointFlag.Name)}
+	return L2Config{
+		Endpoint:      cliCtx.String(l2EndpointFlag.Name),
+		ProofEndpoint: cliCtx.String(l2ProofEndpointFlag.Name),
+		PollInterval:  time.Duration(cliCtx.Uint(l2PollIntervalFlag.Name)) * time.Second,
+	}
 }
 
-func (c L2Config) GetEndpoint() string { return c.Endpoint }
+func (c L2Config) GetEndpoint() string            { return c.Endpoint }
+func (c L2Config) GetProofEndpoint() string       { return c.ProofEndpoint }
+func (c L2Config) GetPollInterval() time.Duration { return c.PollInterval }
+
+const (
+	CalldataDAMode = "calldata" // Batches are posted as calldata.
+	BlobDAMode     = "blob"     // Batches are posted as blobs (EIP-4844), falling back to calldata if unsupported.
+)
 
 // Sequencer node configuration
 type DisseminatorConfig struct {
@@ -126,10 +149,8 @@ type DisseminatorConfig struct {
 	IsEnabled bool `toml:"enabled,omitempty"`
 	// The address of this sequencer
 	AccountAddr common.Address `toml:"account_addr,omitempty"`
-	// The private key for AccountAddr
-	PrivateKey *ecdsa.PrivateKey
-	// The Clef Endpoint used for signing txs
-	ClefEndpoint string `toml:"clef_endpoint,omitempty"`
+	// The signer of txs from AccountAddr
+	SignerCfg signer.Config `toml:"signer,omitempty"`
 	// Time between batch dissemination (DA) steps
 	DisseminationInterval time.Duration `toml:"dissemination_interval,omitempty"`
 	// The safety margin for batch tx submission (in # of L1 blocks)
@@ -142,20 +163,34 @@ type DisseminatorConfig struct {
 	TargetBatchSize uint64 `toml:"target_l1_tx_size,omitempty"`
 	// The maximum size of a batch tx submitted to L1 (bytes).
 	MaxBatchSize uint64 `toml:"max_l1_tx_size,omitempty"`
+	// The batch encoding format version (0: raw RLP, 1: compressed).
+	BatchEncoderVersion uint64 `toml:"batch_encoder_version,omitempty"`
+	// The compression algorithm used by compressed batch encoders (zlib, brotli or zstd).
+	CompressionAlgo string `toml:"compression_algo,omitempty"`
+	// How batches are posted to L1 (calldata or blob).
+	DAMode string `toml:"da_mode,omitempty"`
+	// The maximum number of batch txs in-flight at once (1 disables pipelining).
+	MaxPendingTxs uint64 `toml:"max_pending_txs,omitempty"`
+	// Path of the file used to checkpoint dissemination progress (in-memory only if empty).
+	CheckpointPath string `toml:"checkpoint_path,omitempty"`
 	// Transaction manager configuration
 	TxMgrCfg txmgr.Config `toml:"txmgr,omitempty"`
 }
 
 func (c DisseminatorConfig) GetIsEnabled() bool                      { return c.IsEnabled }
 func (c DisseminatorConfig) GetAccountAddr() common.Address          { return c.AccountAddr }
-func (c DisseminatorConfig) GetPrivateKey() *ecdsa.PrivateKey        { return c.PrivateKey }
-func (c DisseminatorConfig) GetClefEndpoint() string                 { return c.ClefEndpoint }
+func (c DisseminatorConfig) GetSignerCfg() signer.Config             { return c.SignerCfg }
 func (c DisseminatorConfig) GetDisseminationInterval() time.Duration { return c.DisseminationInterval }
 func (c DisseminatorConfig) GetSubSafetyMargin() uint64              { return c.SubSafetyMargin }
 func (c DisseminatorConfig) GetMaxSafeLag() uint64                   { return c.MaxSafeLag }
 func (c DisseminatorConfig) GetMaxSafeLagDelta() uint64              { return c.MaxSafeLagDelta }
 func (c DisseminatorConfig) GetTargetBatchSize() uint64              { return c.TargetBatchSize }
 func (c DisseminatorConfig) GetMaxBatchSize() uint64                 { return c.MaxBatchSize }
+func (c DisseminatorConfig) GetBatchEncoderVersion() uint64          { return c.BatchEncoderVersion }
+func (c DisseminatorConfig) GetCompressionAlgo() string              { return c.CompressionAlgo }
+func (c DisseminatorConfig) GetUseBlobs() bool                       { return c.DAMode == BlobDAMode }
+func (c DisseminatorConfig) GetMaxPendingTxs() uint64                { return c.MaxPendingTxs }
+func (c DisseminatorConfig) GetCheckpointPath() string               { return c.CheckpointPath }
 func (c DisseminatorConfig) GetTxMgrCfg() txmgr.Config               { return c.TxMgrCfg }
 
 // Validates the configuration.
@@ -163,11 +198,8 @@ func (c DisseminatorConfig) validate() error {
 	if !c.IsEnabled {
 		return nil
 	}
-	if c.PrivateKey == nil && c.ClefEndpoint == "" {
-		return fmt.Errorf("missing both private key and clef endpoint (require at least one)")
-	}
-	if c.PrivateKey != nil && c.AccountAddr != crypto.PubkeyToAddress(c.PrivateKey.PublicKey) {
-		return fmt.Errorf("private key does not correspond to account address")
+	if err := c.SignerCfg.Validate(); err != nil {
+		return fmt.Errorf("invalid signer config: %w", err)
 	}
 	// Enforce sensible values.
 	if c.TargetBatchSize < 128 {
@@ -180,31 +212,59 @@ func (c DisseminatorConfig) validate() error {
 	if c.MaxBatchSize < c.TargetBatchSize {
 		return fmt.Errorf("max batch size must be at least target batch size")
 	}
+	switch c.BatchEncoderVersion {
+	case uint64(derivation.V0):
+	case uint64(derivation.V1):
+		if _, err := derivation.ParseCompressionAlgo(c.CompressionAlgo); err != nil {
+			return err
+		}
+		if c.MaxBatchSize <= derivation.MaxCompressionOverhead {
+			return fmt.Errorf("max batch size must exceed %dB with V1 encoding", derivation.MaxCompressionOverhead)
+		}
+	default:
+		return fmt.Errorf("unsupported batch encoder version: %d", c.BatchEncoderVersion)
+	}
+	switch c.DAMode {
+	case CalldataDAMode:
+	case BlobDAMode:
+		if c.MaxBatchSize > derivation.MaxBlobBatchSize {
+			return fmt.Errorf("max batch size must be at most %dB in blob mode", derivation.MaxBlobBatchSize)
+		}
+	default:
+		return fmt.Errorf("unsupported DA mode: %s", c.DAMode)
+	}
+	if c.MaxPendingTxs == 0 {
+		return fmt.Errorf("max pending txs must be at least 1")
+	}
 	return c.TxMgrCfg.Validate()
 }
 
-func newDisseminatorConfigFromCLI(cliCtx *cli.Context, l1ChainID *big.Int) DisseminatorConfig {
+func newDisseminatorConfigFromCLI(cliCtx *cli.Context, l1ChainID *big.Int) (DisseminatorConfig, error) {
 	if !cliCtx.Bool(disseminatorEnableFlag.Name) {
-		return DisseminatorConfig{IsEnabled: false}
+		return DisseminatorConfig{IsEnabled: false}, nil
+	}
+	signerCfg, err := signer.NewConfigFromCLI(cliCtx, disseminatorSignerNamespace)
+	if err != nil {
+		return DisseminatorConfig{}, fmt.Errorf("failed to parse signer config: %w", err)
 	}
-	var (
-		privateKey = toPrivateKey(cliCtx.String(disseminatorPrivateKeyFlag.Name))
-		address    = crypto.PubkeyToAddress(privateKey.PublicKey)
-		txMgrCfg   = txmgr.NewConfigFromCLI(cliCtx, disseminatorTxMgrNamespace, l1ChainID, address)
-	)
+	txMgrCfg := txmgr.NewConfigFromCLI(cliCtx, disseminatorTxMgrNamespace, l1ChainID, signerCfg.Address)
 	return DisseminatorConfig{
 		IsEnabled:             cliCtx.Bool(disseminatorEnableFlag.Name),
-		AccountAddr:           address,
-		PrivateKey:            privateKey,
-		ClefEndpoint:          cliCtx.String(disseminatorClefEndpointFlag.Name),
+		AccountAddr:           signerCfg.Address,
+		SignerCfg:             signerCfg,
 		DisseminationInterval: time.Duration(cliCtx.Uint(disseminatorIntervalFlag.Name)) * time.Second,
 		SubSafetyMargin:       cliCtx.Uint64(disseminatorSubSafetyMarginFlag.Name),
 		MaxSafeLag:            cliCtx.Uint64(disseminatorMaxSafeLagFlag.Name),
 		MaxSafeLagDelta:       cliCtx.Uint64(disseminatorMaxSafeLagDeltaFlag.Name),
 		TargetBatchSize:       cliCtx.Uint64(disseminatorTargetBatchSizeFlag.Name),
 		MaxBatchSize:          cliCtx.Uint64(disseminatorMaxBatchSizeFlag.Name),
+		BatchEncoderVersion:   cliCtx.Uint64(disseminatorBatchEncoderVersionFlag.Name),
+		CompressionAlgo:       cliCtx.String(disseminatorCompressionAlgoFlag.Name),
+		DAMode:                cliCtx.String(disseminatorDAModeFlag.Name),
+		MaxPendingTxs:         cliCtx.Uint64(disseminatorMaxPendingTxsFlag.Name),
+		CheckpointPath:        cliCtx.String(disseminatorCheckpointPathFlag.Name),
 		TxMgrCfg:              txMgrCfg,
-	}
+	}, nil
 }
 
 type ValidatorConfig struct {
@@ -212,63 +272,72 @@ type ValidatorConfig struct {
 	IsEnabled bool `toml:"enabled,omitempty"`
 	// The address of this validator
 	AccountAddr common.Address `toml:"account_addr,omitempty"`
-	// The private key for AccountAddr
-	PrivateKey *ecdsa.PrivateKey
-	// The Clef Endpoint used for signing txs
-	ClefEndpoint string `toml:"clef_endpoint,omitempty"`
+	// The signer of txs from AccountAddr
+	SignerCfg signer.Config `toml:"signer,omitempty"`
 	// Time between validation steps
 	ValidationInterval time.Duration `toml:"validation_interval,omitempty"`
+	// Version of the state commitments of created and checked assertions (must match the genesis assertion's)
+	StateCommitmentVersion uint64 `toml:"state_commitment_version,omitempty"`
 	// Transaction manager configuration
 	TxMgrCfg txmgr.Config `toml:"txmgr,omitempty"`
 }
 
 func (c ValidatorConfig) GetIsEnabled() bool                   { return c.IsEnabled }
 func (c ValidatorConfig) GetAccountAddr() common.Address       { return c.AccountAddr }
-func (c ValidatorConfig) GetPrivateKey() *ecdsa.PrivateKey     { return c.PrivateKey }
-func (c ValidatorConfig) GetClefEndpoint() string              { return c.ClefEndpoint }
+func (c ValidatorConfig) GetSignerCfg() signer.Config          { return c.SignerCfg }
 func (c ValidatorConfig) GetValidationInterval() time.Duration { return c.ValidationInterval }
 func (c ValidatorConfig) GetTxMgrCfg() txmgr.Config            { return c.TxMgrCfg }
+func (c ValidatorConfig) GetStateCommitmentVersion() uint64    { return c.StateCommitmentVersion }
 
 // Validates the configuration.
 func (c ValidatorConfig) validate() error {
 	if !c.IsEnabled {
 		return nil
 	}
-	if c.PrivateKey == nil && c.ClefEndpoint == "" {
-		return fmt.Errorf("missing both private key and clef endpoint (require at least one)")
+	if err := c.SignerCfg.Validate(); err != nil {
+		return fmt.Errorf("invalid signer config: %w", err)
 	}
-	if c.PrivateKey != nil && c.AccountAddr != crypto.PubkeyToAddress(c.PrivateKey.PublicKey) {
-		return fmt.Errorf("private key does not correspond to account address")
+	// TODO: allow V1 once `L1Portal` can prove withdrawals against V1 commitments (it recomputes V0 ones).
+	if c.StateCommitmentVersion > 0 {
+		return fmt.Errorf("unsupported state commitment version: %d", c.StateCommitmentVersion)
 	}
 	return c.TxMgrCfg.Validate()
 }
 
-func newValidatorConfigFromCLI(cliCtx *cli.Context, l1ChainID *big.Int) ValidatorConfig {
+func newValidatorConfigFromCLI(cliCtx *cli.Context, l1ChainID *big.Int) (ValidatorConfig, error) {
 	if !cliCtx.Bool(validatorEnableFlag.Name) {
-		return ValidatorConfig{IsEnabled: false}
+		return ValidatorConfig{IsEnabled: false}, nil
 	}
-	var (
-		privateKey = toPrivateKey(cliCtx.String(validatorPrivateKeyFlag.Name))
-		address    = crypto.PubkeyToAddress(privateKey.PublicKey)
-		txMgrCfg   = txmgr.NewConfigFromCLI(cliCtx, validatorTxMgrNamespace, l1ChainID, address)
-	)
-	return ValidatorConfig{
-		IsEnabled:          cliCtx.Bool(validatorEnableFlag.Name),
-		AccountAddr:        address,
-		PrivateKey:         privateKey,
-		ClefEndpoint:       cliCtx.String(validatorClefEndpointFlag.Name),
-		ValidationInterval: time.Duration(cliCtx.Uint(validatorValidationIntervalFlag.Name)) * time.Second,
-		TxMgrCfg:           txMgrCfg,
+	signerCfg, err := signer.NewConfigFromCLI(cliCtx, validatorSignerNamespace)
+	if err != nil {
+		return ValidatorConfig{}, fmt.Errorf("failed to parse signer config: %w", err)
 	}
+	txMgrCfg := txmgr.NewConfigFromCLI(cliCtx, validatorTxMgrNamespace, l1ChainID, signerCfg.Address)
+	return ValidatorConfig{
+		IsEnabled:              cliCtx.Bool(validatorEnableFlag.Name),
+		AccountAddr:            signerCfg.Address,
+		SignerCfg:              signerCfg,
+		ValidationInterval:     time.Duration(cliCtx.Uint(validatorValidationIntervalFlag.Name)) * time.Second,
+		StateCommitmentVersion: cliCtx.Uint64(validatorStateCommitmentVersionFlag.Name),
+		TxMgrCfg:               txMgrCfg,
+	}, nil
 }
 
-func toPrivateKey(keyStr string) *ecdsa.PrivateKey {
-	if keyStr == "" {
-		return nil
-	}
-	secretKey, err := crypto.HexToECDSA(keyStr[2:])
-	if err != nil {
-		panic("failed to parse secret key: " + err.Error())
+// Metrics server configuration
+type MetricsConfig struct {
+	IsEnabled bool   `toml:"enabled,omitempty"` // Enables the metrics server
+	Addr      string `toml:"addr,omitempty"`    // Address the metrics server listens on
+	Port      uint64 `toml:"port,omitempty"`    // Port the metrics server listens on
+}
+
+func newMetricsConfigFromCLI(cliCtx *cli.Context) MetricsConfig {
+	return MetricsConfig{
+		IsEnabled: cliCtx.Bool(metricsEnableFlag.Name),
+		Addr:      cliCtx.String(metricsAddrFlag.Name),
+		Port:      cliCtx.Uint64(metricsPortFlag.Name),
 	}
-	return secretKey
 }
+
+func (c MetricsConfig) GetIsEnabled() bool { return c.IsEnabled }
+func (c MetricsConfig) GetAddr() string    { return c.Addr }
+func (c MetricsConfig) GetPort() uint64    { return c.Port }
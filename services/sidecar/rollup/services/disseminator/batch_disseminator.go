@@ -1,26 +1,53 @@
 package disseminator
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
 	"math/big"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+
 	"github.com/specularL2/specular/services/sidecar/rollup/derivation"
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
 	"github.com/specularL2/specular/services/sidecar/rollup/types"
 	"github.com/specularL2/specular/services/sidecar/utils/fmt"
 	"github.com/specularL2/specular/services/sidecar/utils/log"
 )
 
-// Disseminates batches of L2 blocks via L1.
+var transactTimeout = 10 * time.Minute
+
+// Disseminates batches of L2 blocks via L1 (as made available by the DA provider).
 type BatchDisseminator struct {
 	cfg          Config
 	batchBuilder BatchBuilder
 	l1TxMgr      TxManager
+	daProvider   DAProvider
 	l1State      *eth.EthState // Expected to generally be kept in sync with L1 chain.
+	l1Client     L1Client
 	l2Client     L2Client
+	checkpoints  CheckpointStore
+	newHeads     <-chan struct{} // Signals new L1/L2 heads (nil if not subscribed).
+
+	lastFinalized    types.BlockID    // Last L2 block in a batch sequenced in a finalized L1 block.
+	lastDisseminated types.BlockID    // Last L2 block in a sequenced batch.
+	sequenced        []SequencedBatch // Batches sequenced in L1 blocks that aren't finalized yet, in order.
+	pending          []*pendingBatch  // Batches submitted to L1 but not yet sequenced, in nonce order.
+	requeued         []InFlightBatch  // Batches to (re)submit before building new ones, in order.
+	// Whether txs of abandoned batches may still be pending on L1 (and must be cancelled before sending more).
+	abandoned bool
+}
+
+// A batch whose L1 tx is being sent in the background.
+type pendingBatch struct {
+	InFlightBatch
+	resultChan <-chan txmgr.SendResult
+	cancel     context.CancelFunc
 }
 
 type recoverableSystemStateError struct{ msg string }
@@ -37,10 +64,24 @@ func NewBatchDisseminator(
 	cfg Config,
 	batchBuilder BatchBuilder,
 	l1TxMgr TxManager,
+	daProvider DAProvider,
 	l1State *eth.EthState,
+	l1Client L1Client,
 	l2Client L2Client,
+	checkpoints CheckpointStore,
+	newHeads <-chan struct{},
 ) *BatchDisseminator {
-	return &BatchDisseminator{cfg, batchBuilder, l1TxMgr, l1State, l2Client}
+	return &BatchDisseminator{
+		cfg:          cfg,
+		batchBuilder: batchBuilder,
+		l1TxMgr:      l1TxMgr,
+		daProvider:   daProvider,
+		l1State:      l1State,
+		l1Client:     l1Client,
+		l2Client:     l2Client,
+		checkpoints:  checkpoints,
+		newHeads:     newHeads,
+	}
 }
 
 func (s *BatchDisseminator) Start(ctx context.Context, eg ErrGroup) error {
@@ -53,35 +94,50 @@ func (s *BatchDisseminator) Start(ctx context.Context, eg ErrGroup) error {
 	return nil
 }
 
+// Steps on every new L1/L2 head, falling back to stepping on a fixed interval (e.g. if head syncing stalls).
 func (d *BatchDisseminator) start(ctx context.Context) error {
-	// Start with latest safe state.
-	if err := d.rollback(ctx); err != nil {
+	// Start from the last checkpoint (or the latest safe state).
+	if err := d.restore(ctx); err != nil {
 		return err
 	}
-	var ticker = time.NewTicker(d.cfg.GetDisseminationInterval())
+	var (
+		interval = d.cfg.GetDisseminationInterval()
+		ticker   = time.NewTicker(interval)
+	)
 	defer ticker.Stop()
 	for {
 		select {
+		case <-d.newHeads:
+			ticker.Reset(interval)
 		case <-ticker.C:
-			if err := d.step(ctx); err != nil {
-				log.Errorf("Failed to step: %w", err)
-				if errors.As(err, &recoverableSystemStateError{}) {
-					log.Info("Rollback from recoverable error", "error", err)
-					d.rollback(ctx)
-					// return fmt.Errorf("aborting: %w", err)
-				}
-			}
 		case <-ctx.Done():
 			log.Info("Aborting.")
 			return nil
 		}
+		if err := d.step(ctx); err != nil {
+			log.Errorf("Failed to step: %w", err)
+			if errors.As(err, &recoverableSystemStateError{}) {
+				log.Info("Rollback from recoverable error", "error", err)
+				d.rollback(ctx)
+				// return fmt.Errorf("aborting: %w", err)
+			}
+		}
 	}
 }
 
 // Attempts to (incrementally) build a batch and disseminate it via L1.
 func (d *BatchDisseminator) step(ctx context.Context) error {
+	if err := d.checkSequenced(ctx); err != nil {
+		return fmt.Errorf("failed to check sequenced batches: %w", err)
+	}
 	start, end, safe, err := d.pendingL2BlockRange(ctx)
 	if err != nil {
+		if errors.As(err, &L2ReorgDetectedError{}) {
+			log.Error("Reorg detected, reverting to safe state.", "error", err)
+			if err := d.rollback(ctx); err != nil {
+				return err
+			}
+		}
 		return fmt.Errorf("failed to get l2 block number: %w", err)
 	}
 	if err := d.appendToBuilder(ctx, start, end); err != nil {
@@ -99,17 +155,225 @@ func (d *BatchDisseminator) step(ctx context.Context) error {
 	return nil
 }
 
-// Rolls back the disseminator state to the last safe L2 header.
+// Rolls back the disseminator state to the last safe L2 header, abandoning any unfinalized batches.
 func (d *BatchDisseminator) rollback(ctx context.Context) error {
 	head, err := d.l2Client.HeaderByTag(ctx, eth.Safe)
 	if err != nil {
 		return fmt.Errorf("failed to get last safe header: %w", err)
 	}
 	log.Info("Rolling back disseminator to checkpoint", "l2Block#", head.Number)
-	d.batchBuilder.Reset(types.NewBlockIDFromHeader(head))
+	safe := types.NewBlockIDFromHeader(head)
+	d.reset(safe)
+	d.sequenced = nil
+	d.lastFinalized = safe
+	return d.saveCheckpoint()
+}
+
+// Restores the disseminator state from the last checkpoint, requeuing any in-flight batches that weren't sequenced.
+// Falls back to the last safe L2 header if there's no checkpoint, or the checkpoint is behind the safe head.
+func (d *BatchDisseminator) restore(ctx context.Context) error {
+	// Txs of in-flight batches (and any others sent since the checkpoint was saved) may still be pending on L1,
+	// so all pending txs are cancelled before sending any, rather than being resumed by the tx manager.
+	d.abandoned = true
+	cp, err := d.checkpoints.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	if cp == nil {
+		return d.rollback(ctx)
+	}
+	log.Info(
+		"Restoring disseminator from checkpoint",
+		"last_disseminated", cp.LastDisseminated, "#in_flight", len(cp.InFlight), "#requeued", len(cp.Requeued),
+	)
+	d.lastFinalized, d.lastDisseminated, d.sequenced = cp.LastFinalized, cp.LastDisseminated, cp.Sequenced
+	// In-flight batches are resolved in order, so each is only resubmitted once all prior batches are sequenced.
+	d.requeued = append(cp.InFlight, cp.Requeued...)
+	if err := d.resolveRequeued(ctx); err != nil {
+		return fmt.Errorf("failed to resume in-flight batches: %w", err)
+	}
+	if len(d.requeued) > 0 {
+		log.Info("Requeuing in-flight batches", "#batches", len(d.requeued), "last_disseminated", d.lastDisseminated)
+	}
+	lastEnqueued := d.lastDisseminated
+	if len(d.requeued) > 0 {
+		lastEnqueued = d.requeued[len(d.requeued)-1].LastEnqueued
+	}
+	safe, err := d.l2Client.HeaderByTag(ctx, eth.Safe)
+	if err != nil {
+		return fmt.Errorf("failed to get last safe header: %w", err)
+	}
+	if safe.Number.Uint64() >= lastEnqueued.GetNumber() {
+		return d.rollback(ctx)
+	}
+	// Only resume from the checkpoint if it's still canonical.
+	block, err := d.l2Client.BlockByNumber(ctx, new(big.Int).SetUint64(lastEnqueued.GetNumber()))
+	if err != nil {
+		return fmt.Errorf("failed to get checkpointed block: %w", err)
+	}
+	if block.Hash() != lastEnqueued.GetHash() {
+		log.Warn("Checkpointed block is no longer canonical", "checkpoint", lastEnqueued, "hash", block.Hash())
+		return d.rollback(ctx)
+	}
+	d.batchBuilder.Reset(lastEnqueued)
+	return d.saveCheckpoint()
+}
+
+// Checks that all sequenced batches are still included in the canonical L1 chain, and stops tracking them once
+// their inclusion blocks are finalized.
+// If a batch was reorged out, it's requeued for resubmission, along with all later batches (to preserve order).
+func (d *BatchDisseminator) checkSequenced(ctx context.Context) error {
+	if len(d.sequenced) == 0 {
+		return nil
+	}
+	for i := range d.sequenced {
+		batch := &d.sequenced[i]
+		receipt, err := d.inclusionReceipt(ctx, batch.InFlightBatch)
+		if err != nil {
+			return err
+		}
+		if receipt == nil {
+			log.Warn("Batch was reorged out of L1, resubmitting", "l1Block", batch.L1Block, "last_enqueued", batch.LastEnqueued)
+			d.requeue(i)
+			return d.saveCheckpoint()
+		}
+		if receipt.BlockHash != batch.L1Block.GetHash() {
+			log.Info("Batch was re-included in L1", "tx_hash", receipt.TxHash, "l1Block#", receipt.BlockNumber)
+			batch.L1Block = types.NewBlockID(receipt.BlockNumber.Uint64(), receipt.BlockHash)
+		}
+	}
+	finalized := d.l1State.Finalized().GetNumber()
+	numFinalized := 0
+	for ; numFinalized < len(d.sequenced) && d.sequenced[numFinalized].L1Block.GetNumber() <= finalized; numFinalized++ {
+		d.lastFinalized = d.sequenced[numFinalized].LastEnqueued
+	}
+	if numFinalized > 0 {
+		log.Info("Batches finalized on L1", "#batches", numFinalized, "last_finalized", d.lastFinalized)
+		d.sequenced = d.sequenced[numFinalized:]
+	}
+	return d.saveCheckpoint()
+}
+
+// Moves the leading requeued batches that were sequenced after all (e.g. before their txs could be replaced) to
+// the sequenced batches, stopping at the first one that wasn't.
+func (d *BatchDisseminator) resolveRequeued(ctx context.Context) error {
+	for len(d.requeued) > 0 {
+		batch := d.requeued[0]
+		receipt, err := d.inclusionReceipt(ctx, batch)
+		if err != nil {
+			return err
+		}
+		if receipt == nil {
+			return nil
+		}
+		log.Info("Requeued batch was already sequenced", "tx_hash", receipt.TxHash, "l1Block#", receipt.BlockNumber)
+		d.sequenced = append(d.sequenced, newSequencedBatch(batch, receipt))
+		d.lastDisseminated = batch.LastEnqueued
+		d.requeued = d.requeued[1:]
+	}
 	return nil
 }
 
+// Cancels the txs of abandoned batches that may still be pending on L1, blocking until their nonces are used.
+// Otherwise, they could be sequenced out of order, or alongside their resubmissions.
+// Requeued batches whose txs were sequenced regardless aren't resubmitted.
+func (d *BatchDisseminator) cancelAbandoned(ctx context.Context) error {
+	if !d.abandoned {
+		return nil
+	}
+	log.Info("Cancelling txs of abandoned batches", "#requeued", len(d.requeued))
+	// Cancellations mustn't be recorded as txs of in-flight batches (see `FileCheckpointStore.AddPendingTx`).
+	if err := d.saveCheckpoint(); err != nil {
+		return fmt.Errorf("failed to checkpoint abandoned batches: %w", err)
+	}
+	cCtx, cancel := context.WithTimeout(ctx, transactTimeout)
+	defer cancel()
+	if err := d.l1TxMgr.CancelPending(cCtx); err != nil {
+		return fmt.Errorf("failed to cancel abandoned batch txs: %w", err)
+	}
+	d.abandoned = false
+	if err := d.resolveRequeued(ctx); err != nil {
+		return fmt.Errorf("failed to resolve requeued batches: %w", err)
+	}
+	return d.saveCheckpoint()
+}
+
+// Returns the receipt of a successful (canonical) tx carrying the batch, or nil if there is none.
+func (d *BatchDisseminator) inclusionReceipt(ctx context.Context, batch InFlightBatch) (*ethTypes.Receipt, error) {
+	for _, txHash := range batch.PendingTxHashes {
+		receipt, err := d.l1Client.TransactionReceipt(ctx, txHash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get receipt (tx_hash=%s): %w", txHash, err)
+		}
+		if receipt.Status == ethTypes.ReceiptStatusSuccessful {
+			return receipt, nil
+		}
+	}
+	return nil, nil
+}
+
+// Requeues the sequenced batch at index `i` for resubmission, along with all later (sequenced or pending) batches.
+func (d *BatchDisseminator) requeue(i int) {
+	var requeued []InFlightBatch
+	for _, batch := range d.sequenced[i:] {
+		requeued = append(requeued, batch.InFlightBatch)
+	}
+	for _, batch := range d.pending {
+		requeued = append(requeued, batch.InFlightBatch)
+	}
+	d.abortPending()
+	d.requeued = append(requeued, d.requeued...)
+	d.sequenced = d.sequenced[:i]
+	if i > 0 {
+		d.lastDisseminated = d.sequenced[i-1].LastEnqueued
+	} else {
+		d.lastDisseminated = d.lastFinalized
+	}
+	// The reorged txs may be back in the L1 mempool (along with the pending ones), and their nonces must be reused.
+	d.abandoned = true
+}
+
+// Resets the batch builder to start after `lastDisseminated`, abandoning any pending or requeued batches.
+func (d *BatchDisseminator) reset(lastDisseminated types.BlockID) {
+	if len(d.pending) > 0 {
+		d.abandoned = true
+	}
+	d.abortPending()
+	d.requeued = nil
+	d.batchBuilder.Reset(lastDisseminated)
+	d.lastDisseminated = lastDisseminated
+}
+
+// Checkpoints the disseminator state.
+// Tx hashes recorded for pending batches (by the publish hook) since the last save are retained.
+func (d *BatchDisseminator) saveCheckpoint() error {
+	prev, err := d.checkpoints.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	cp := &Checkpoint{
+		LastFinalized:    d.lastFinalized,
+		LastDisseminated: d.lastDisseminated,
+		Sequenced:        d.sequenced,
+		Requeued:         d.requeued,
+	}
+	for _, pending := range d.pending {
+		batch := pending.InFlightBatch
+		if prev != nil {
+			for _, prevBatch := range prev.InFlight {
+				if prevBatch.Nonce == batch.Nonce && bytes.Equal(prevBatch.Data, batch.Data) {
+					batch.PendingTxHashes = prevBatch.PendingTxHashes
+				}
+			}
+		}
+		cp.InFlight = append(cp.InFlight, batch)
+	}
+	return d.checkpoints.Save(cp)
+}
+
 // Appends L2 blocks to batch builder.
 func (d *BatchDisseminator) appendToBuilder(ctx context.Context, start uint64, end uint64) error {
 	if start > end {
@@ -134,36 +398,49 @@ func (d *BatchDisseminator) appendToBuilder(ctx context.Context, start uint64, e
 }
 
 // Determines first and last unsafe block numbers.
-// Typically, we start from the last appended block number + 1, and end at the current unsafe head.
+// The range starts after the last enqueued block: as restored from the checkpoint (or the safe head) on start, or as
+// enqueued since. That block must still be canonical for the range to extend it.
 func (d *BatchDisseminator) pendingL2BlockRange(ctx context.Context) (uint64, uint64, uint64, error) {
-	var (
-		lastEnqueued = d.batchBuilder.LastEnqueued()
-		start        = lastEnqueued.GetNumber() + 1 // TODO: fix assumption
-	)
+	lastEnqueued := d.batchBuilder.LastEnqueued()
+	if lastEnqueued == types.EmptyBlockID {
+		return 0, 0, 0, fmt.Errorf("disseminator state wasn't restored")
+	}
 	safe, err := d.l2Client.HeaderByTag(ctx, eth.Safe)
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to get l2 safe header: %w", err)
 	}
 	log.Info("Retrieved safe head", "number", safe.Number, "hash", safe.Hash())
 	safeBlockNum := safe.Number.Uint64()
-	if lastEnqueued == types.EmptyBlockID {
-		// First time running; use safe (assumes local chain fork-choice is in sync...)
-		start = safeBlockNum + 1
-	} else if safeBlockNum > lastEnqueued.GetNumber() {
+	if safeBlockNum > lastEnqueued.GetNumber() {
 		// This should currently not be possible (single sequencer).
 		return 0, 0, 0, recoverableSystemStateError{
 			msg: fmt.Sprintf("safe header exceeds last appended header (safe=%d, last=%d)", safeBlockNum, lastEnqueued.GetNumber()),
 		}
 	}
+	header, err := d.l2Client.HeaderByNumber(ctx, new(big.Int).SetUint64(lastEnqueued.GetNumber()))
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to get last enqueued header: %w", err)
+	}
+	if header.Hash() != lastEnqueued.GetHash() {
+		return 0, 0, 0, L2ReorgDetectedError{
+			fmt.Errorf("last enqueued block is no longer canonical (last=%s, canonical=%s)", lastEnqueued, header.Hash()),
+		}
+	}
 	end, err := d.l2Client.BlockNumber(ctx)
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to get most recent l2 block number: %w", err)
 	}
-	return start, end, safeBlockNum, nil
+	return lastEnqueued.GetNumber() + 1, end, safeBlockNum, nil
 }
 
 // Disseminates batches until batch builder runs out (or signal from `ctx`).
+// Up to `MaxPendingTxs` batches are kept in-flight at once; while the pipeline is full, this blocks until
+// the oldest pending batch is sequenced and N confirmations received.
+// Note: this does not guarantee safety (re-org resistance) but should make re-orgs less likely.
 func (d *BatchDisseminator) disseminateBatches(ctx context.Context, currentLag uint64) error {
+	if err := d.cancelAbandoned(ctx); err != nil {
+		return err
+	}
 	for {
 		// Non-blocking ctx check.
 		select {
@@ -171,31 +448,159 @@ func (d *BatchDisseminator) disseminateBatches(ctx context.Context, currentLag u
 			log.Info("Done disseminating batches")
 			return nil
 		default:
-			if err := d.disseminateBatch(ctx, currentLag); err != nil {
-				if errors.Is(err, io.EOF) {
-					log.Info("No pending batches to sequence")
-					return nil
-				}
-				return fmt.Errorf("failed to sequence batch: %w", err)
+		}
+		if err := d.confirmPending(ctx, false); err != nil {
+			return err
+		}
+		if uint64(len(d.pending)) >= d.cfg.GetMaxPendingTxs() {
+			if err := d.confirmPending(ctx, true); err != nil {
+				return err
 			}
+			continue
+		}
+		if err := d.disseminateBatch(ctx, currentLag); err != nil {
+			if errors.Is(err, io.EOF) {
+				log.Info("No pending batches to sequence", "#in_flight", len(d.pending))
+				return nil
+			}
+			return fmt.Errorf("failed to sequence batch: %w", err)
 		}
 	}
 }
 
-// Fetches a batch from batch builder and disseminates it via L1.
-// Blocking call until batch is sequenced and N confirmations received.
-// Note: this does not guarantee safety (re-org resistance) but should make re-orgs less likely.
+// Submits the next requeued batch to L1 if any, or otherwise fetches a batch from batch builder and submits it,
+// without waiting for it to be sequenced.
 func (d *BatchDisseminator) disseminateBatch(ctx context.Context, currentLag uint64) error {
+	if len(d.requeued) > 0 {
+		if err := d.submit(ctx, d.requeued[0]); err != nil {
+			return err
+		}
+		d.requeued = d.requeued[1:]
+		if err := d.saveCheckpoint(); err != nil {
+			return fmt.Errorf("failed to checkpoint in-flight batch: %w", err)
+		}
+		return nil
+	}
 	// Construct tx data.
 	data, err := d.batchBuilder.Build(d.l1State.Head(), currentLag)
 	if err != nil {
 		return fmt.Errorf("failed to build batch: %w", err)
 	}
-	receipt, err := d.l1TxMgr.AppendTxBatch(ctx, data)
+	lastBuilt := d.batchBuilder.LastBuiltBlock()
+	// Make the batch available via the DA provider, getting back the data to append to L1.
+	data, err = d.daProvider.Store(ctx, data)
 	if err != nil {
-		return fmt.Errorf("failed to send batch transaction: %w", err)
+		return fmt.Errorf("failed to store batch: %w", err)
+	}
+	if err := d.submit(ctx, InFlightBatch{LastEnqueued: lastBuilt, Data: data}); err != nil {
+		return err
 	}
-	log.Info("Sequenced batch to L1", "size", len(data), "tx_hash", receipt.TxHash, "l1Block#", receipt.BlockNumber)
 	d.batchBuilder.Advance()
+	if err := d.saveCheckpoint(); err != nil {
+		return fmt.Errorf("failed to checkpoint in-flight batch: %w", err)
+	}
+	return nil
+}
+
+// Sends the batch to L1 in the background, adding it to the pending batches.
+func (d *BatchDisseminator) submit(ctx context.Context, batch InFlightBatch) error {
+	sendCtx, cancel := context.WithCancel(ctx)
+	tx, resultChan, err := d.appendTxBatch(sendCtx, batch.Data)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to send batch transaction: %w", err)
+	}
+	log.Info("Submitted batch to L1", "size", len(batch.Data), "tx_hash", tx.Hash(), "nonce", tx.Nonce())
+	batch.Nonce = tx.Nonce()
+	// Hashes of txs previously sent for the batch (if requeued) are kept, since they may still be included.
+	batch.PendingTxHashes = append(append([]common.Hash(nil), batch.PendingTxHashes...), tx.Hash())
+	d.pending = append(d.pending, &pendingBatch{batch, resultChan, cancel})
 	return nil
 }
+
+// Processes the results of pending batches in submission order, stopping at the first one that's still pending.
+// If `wait` is true, blocks until the oldest pending batch completes.
+// Sequenced batches are tracked until their L1 inclusion blocks are finalized (see `checkSequenced`).
+// If a batch fails, all later batches are abandoned (as they would be sequenced out of order),
+// and the batch builder is reset to resubmit from the last disseminated batch.
+func (d *BatchDisseminator) confirmPending(ctx context.Context, wait bool) error {
+	for len(d.pending) > 0 {
+		var (
+			oldest = d.pending[0]
+			result txmgr.SendResult
+		)
+		if wait {
+			select {
+			case result = <-oldest.resultChan:
+			case <-ctx.Done():
+				return nil
+			}
+		} else {
+			select {
+			case result = <-oldest.resultChan:
+			default:
+				return nil
+			}
+		}
+		oldest.cancel()
+		d.pending = d.pending[1:]
+		receipt, err := checkResult(result)
+		if err != nil {
+			log.Warn("Batch submission failed, resubmitting from last disseminated batch", "error", err, "last_disseminated", d.lastDisseminated)
+			d.reset(d.lastDisseminated)
+			if err := d.saveCheckpoint(); err != nil {
+				log.Errorf("Failed to checkpoint after aborting pending batches: %w", err)
+			}
+			return fmt.Errorf("failed to send batch transaction: %w", err)
+		}
+		log.Info("Sequenced batch to L1", "size", len(oldest.Data), "tx_hash", receipt.TxHash, "l1Block#", receipt.BlockNumber)
+		d.sequenced = append(d.sequenced, newSequencedBatch(oldest.InFlightBatch, receipt))
+		d.lastDisseminated = oldest.LastEnqueued
+		if err := d.saveCheckpoint(); err != nil {
+			return fmt.Errorf("failed to checkpoint disseminated batch: %w", err)
+		}
+		wait = false
+	}
+	return nil
+}
+
+// Cancels all pending batch submissions, waiting for them to stop.
+// Their txs may still be pending on L1 (see `cancelAbandoned`).
+func (d *BatchDisseminator) abortPending() {
+	for _, batch := range d.pending {
+		batch.cancel()
+	}
+	for _, batch := range d.pending {
+		<-batch.resultChan
+	}
+	d.pending = nil
+}
+
+// Appends a batch to L1, carrying its data in blobs if configured to (and supported by L1), or in calldata otherwise.
+// Returns once the tx is signed; its result is delivered on the returned channel.
+func (d *BatchDisseminator) appendTxBatch(ctx context.Context, data []byte) (*ethTypes.Transaction, <-chan txmgr.SendResult, error) {
+	if !d.cfg.GetUseBlobs() {
+		return d.l1TxMgr.AppendTxBatchAsync(ctx, data)
+	}
+	blobs, err := derivation.EncodeBlobs(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode batch into blobs: %w", err)
+	}
+	tx, resultChan, err := d.l1TxMgr.AppendTxBatchWithBlobsAsync(ctx, []byte{derivation.BlobBatchMarker}, blobs)
+	if errors.Is(err, txmgr.ErrBlobsUnsupported) {
+		log.Warn("L1 does not support blobs, falling back to calldata", "size", len(data))
+		return d.l1TxMgr.AppendTxBatchAsync(ctx, data)
+	}
+	return tx, resultChan, err
+}
+
+// Returns the receipt of a successfully sequenced batch, or an error if the batch tx failed or reverted.
+func checkResult(result txmgr.SendResult) (*ethTypes.Receipt, error) {
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	if result.Receipt.Status != ethTypes.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("batch tx reverted (tx_hash=%s)", result.Receipt.TxHash)
+	}
+	return result.Receipt, nil
+}
@@ -0,0 +1,548 @@
+package disseminator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/services/sidecar/rollup/da"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr/metrics"
+	"github.com/specularL2/specular/services/sidecar/rollup/types"
+)
+
+type testConfig struct{ maxPendingTxs uint64 }
+
+func (c testConfig) GetDisseminationInterval() time.Duration { return time.Second }
+func (c testConfig) GetUseBlobs() bool                       { return false }
+func (c testConfig) GetMaxPendingTxs() uint64                { return c.maxPendingTxs }
+
+// testBuilder serves one single-block batch per block, from `blocks`.
+type testBuilder struct {
+	blocks []types.BlockID
+	next   int // Index of the next block to build.
+	resets []types.BlockID
+}
+
+func (b *testBuilder) Enqueue(*ethTypes.Block) error { return nil }
+func (b *testBuilder) LastEnqueued() types.BlockID   { return b.blocks[len(b.blocks)-1] }
+func (b *testBuilder) LastBuiltBlock() types.BlockID {
+	return b.blocks[b.next]
+}
+func (b *testBuilder) Build(types.BlockID, uint64) ([]byte, error) {
+	if b.next >= len(b.blocks) {
+		return nil, io.EOF
+	}
+	return []byte{0x0, byte(b.blocks[b.next].GetNumber())}, nil
+}
+func (b *testBuilder) Advance() { b.next++ }
+func (b *testBuilder) Reset(lastEnqueued types.BlockID) {
+	b.resets = append(b.resets, lastEnqueued)
+	for i, id := range b.blocks {
+		if id == lastEnqueued {
+			b.next = i + 1
+		}
+	}
+}
+
+// testTxManager assigns sequential nonces. Each tx completes (with the preset outcome for its nonce) once a later
+// tx is sent or `flush` is called, or fails once its context is cancelled.
+type testTxManager struct {
+	failures map[uint64]error
+	txs      []*testTx
+	sent     [][]byte
+	hashes   []common.Hash
+	cancels  int
+}
+
+type testTx struct {
+	nonce      uint64
+	resultChan chan txmgr.SendResult
+	once       sync.Once
+}
+
+func (m *testTxManager) AppendTxBatchAsync(
+	ctx context.Context, batch []byte,
+) (*ethTypes.Transaction, <-chan txmgr.SendResult, error) {
+	m.flush()
+	var (
+		nonce  = uint64(len(m.txs))
+		tx     = ethTypes.NewTx(&ethTypes.DynamicFeeTx{Nonce: nonce, Data: batch})
+		testTx = &testTx{nonce: nonce, resultChan: make(chan txmgr.SendResult, 1)}
+	)
+	go func() {
+		<-ctx.Done()
+		testTx.once.Do(func() { testTx.resultChan <- txmgr.SendResult{Err: ctx.Err()} })
+	}()
+	m.txs = append(m.txs, testTx)
+	m.sent = append(m.sent, batch)
+	m.hashes = append(m.hashes, tx.Hash())
+	return tx, testTx.resultChan, nil
+}
+
+func (m *testTxManager) CancelPending(context.Context) error {
+	m.cancels++
+	return nil
+}
+
+// Completes all sent txs.
+func (m *testTxManager) flush() {
+	for _, tx := range m.txs {
+		tx := tx
+		tx.once.Do(func() {
+			if err := m.failures[tx.nonce]; err != nil {
+				tx.resultChan <- txmgr.SendResult{Err: err}
+				return
+			}
+			receipt := &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful, BlockNumber: big.NewInt(1)}
+			tx.resultChan <- txmgr.SendResult{Receipt: receipt}
+		})
+	}
+}
+
+func (m *testTxManager) AppendTxBatchWithBlobsAsync(
+	context.Context, []byte, []kzg4844.Blob,
+) (*ethTypes.Transaction, <-chan txmgr.SendResult, error) {
+	return nil, nil, txmgr.ErrBlobsUnsupported
+}
+
+// testL1Client serves receipts for txs included in the (canonical) L1 chain.
+type testL1Client struct {
+	receipts map[common.Hash]*ethTypes.Receipt
+}
+
+func (c *testL1Client) TransactionReceipt(_ context.Context, txHash common.Hash) (*ethTypes.Receipt, error) {
+	if receipt, ok := c.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+// Includes the txs in the given L1 block.
+func (c *testL1Client) include(l1Block uint64, txHashes ...common.Hash) {
+	for _, txHash := range txHashes {
+		c.receipts[txHash] = &ethTypes.Receipt{
+			Status:      ethTypes.ReceiptStatusSuccessful,
+			TxHash:      txHash,
+			BlockNumber: new(big.Int).SetUint64(l1Block),
+			BlockHash:   common.BigToHash(new(big.Int).SetUint64(l1Block)),
+		}
+	}
+}
+
+// testL2Client serves the given blocks, with its safe head at genesis.
+type testL2Client struct{ blocks []*ethTypes.Block }
+
+func (c *testL2Client) EnsureDialed(context.Context) error { return nil }
+func (c *testL2Client) BlockNumber(context.Context) (uint64, error) {
+	return uint64(len(c.blocks)), nil
+}
+func (c *testL2Client) BlockByNumber(_ context.Context, number *big.Int) (*ethTypes.Block, error) {
+	return c.blocks[number.Uint64()-1], nil
+}
+func (c *testL2Client) HeaderByNumber(_ context.Context, number *big.Int) (*ethTypes.Header, error) {
+	if number.Sign() == 0 {
+		return c.HeaderByTag(context.Background(), eth.Safe)
+	}
+	return c.blocks[number.Uint64()-1].Header(), nil
+}
+func (c *testL2Client) HeaderByTag(context.Context, eth.BlockTag) (*ethTypes.Header, error) {
+	return &ethTypes.Header{Number: big.NewInt(0)}, nil
+}
+
+func newTestDisseminator(maxPendingTxs uint64, txMgr TxManager, numBlocks int) (*BatchDisseminator, *testBuilder) {
+	var (
+		builder  = &testBuilder{}
+		l2Client = &testL2Client{}
+	)
+	for i := 1; i <= numBlocks; i++ {
+		block := ethTypes.NewBlockWithHeader(&ethTypes.Header{Number: big.NewInt(int64(i))})
+		l2Client.blocks = append(l2Client.blocks, block)
+		builder.blocks = append(builder.blocks, types.NewBlockID(uint64(i), block.Hash()))
+	}
+	d := NewBatchDisseminator(
+		testConfig{maxPendingTxs},
+		builder,
+		txMgr,
+		da.NewL1DAProvider(),
+		eth.NewEthState(),
+		&testL1Client{receipts: map[common.Hash]*ethTypes.Receipt{}},
+		l2Client,
+		NewFileCheckpointStore(""),
+		nil,
+	)
+	return d, builder
+}
+
+func TestDisseminateBatchesPipelined(t *testing.T) {
+	var (
+		txMgr     = &testTxManager{}
+		d, blocks = newTestDisseminator(2, txMgr, 3)
+	)
+	require.NoError(t, d.disseminateBatches(context.Background(), 0))
+	require.Len(t, txMgr.sent, 3)
+	// The last batch is left in-flight, to be confirmed by a later step.
+	require.Len(t, d.pending, 1)
+	require.Equal(t, blocks.blocks[1], d.lastDisseminated)
+
+	txMgr.flush()
+	require.NoError(t, d.confirmPending(context.Background(), true))
+	require.Empty(t, d.pending)
+	require.Equal(t, blocks.blocks[2], d.lastDisseminated)
+	cp, err := d.checkpoints.Load()
+	require.NoError(t, err)
+	require.Equal(t, blocks.blocks[2], cp.LastDisseminated)
+	require.False(t, cp.HasInFlightBatch())
+}
+
+func TestDisseminateBatchesPipelinedFailure(t *testing.T) {
+	var (
+		errSend   = errors.New("send failed")
+		txMgr     = &testTxManager{failures: map[uint64]error{1: errSend}}
+		d, blocks = newTestDisseminator(2, txMgr, 4)
+	)
+	// The second batch fails, so the third (already in-flight) is abandoned, and the builder is reset after the first.
+	err := d.disseminateBatches(context.Background(), 0)
+	require.ErrorIs(t, err, errSend)
+	require.Len(t, txMgr.sent, 3)
+	require.Empty(t, d.pending)
+	require.Equal(t, blocks.blocks[0], d.lastDisseminated)
+	require.Equal(t, []types.BlockID{blocks.blocks[0]}, blocks.resets)
+	cp, err := d.checkpoints.Load()
+	require.NoError(t, err)
+	require.Equal(t, blocks.blocks[0], cp.LastDisseminated)
+	require.False(t, cp.HasInFlightBatch())
+
+	// Batches are resubmitted in order from the last disseminated one, once the abandoned one is cancelled.
+	require.Equal(t, 0, txMgr.cancels)
+	require.NoError(t, d.disseminateBatches(context.Background(), 0))
+	require.Equal(t, 1, txMgr.cancels)
+	txMgr.flush()
+	require.NoError(t, d.confirmPending(context.Background(), true))
+	require.Equal(t, [][]byte{{0x0, 2}, {0x0, 3}, {0x0, 4}}, txMgr.sent[3:])
+	require.Equal(t, blocks.blocks[3], d.lastDisseminated)
+}
+
+func TestDisseminatorL1Reorg(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		txMgr     = &testTxManager{}
+		d, blocks = newTestDisseminator(2, txMgr, 3)
+		l1Client  = d.l1Client.(*testL1Client)
+	)
+	require.NoError(t, d.disseminateBatches(ctx, 0))
+	txMgr.flush()
+	require.NoError(t, d.confirmPending(ctx, true))
+	require.Len(t, d.sequenced, 3)
+	l1Client.include(5, txMgr.hashes...)
+	require.NoError(t, d.checkSequenced(ctx))
+	require.Len(t, d.sequenced, 3)
+
+	// The second batch is reorged out, so it's requeued along with the third.
+	delete(l1Client.receipts, txMgr.hashes[1])
+	require.NoError(t, d.checkSequenced(ctx))
+	require.Len(t, d.sequenced, 1)
+	require.Len(t, d.requeued, 2)
+	require.Equal(t, blocks.blocks[0], d.lastDisseminated)
+	cp, err := d.checkpoints.Load()
+	require.NoError(t, err)
+	require.Len(t, cp.Sequenced, 1)
+	require.Len(t, cp.Requeued, 2)
+
+	// Requeued batches are resubmitted in order (without rebuilding them), once the reorged txs are cancelled.
+	require.NoError(t, d.disseminateBatches(ctx, 0))
+	require.Equal(t, 1, txMgr.cancels)
+	txMgr.flush()
+	require.NoError(t, d.confirmPending(ctx, true))
+	require.Equal(t, [][]byte{{0x0, 2}, {0x0, 3}}, txMgr.sent[3:])
+	require.Empty(t, blocks.resets)
+	require.Len(t, d.sequenced, 3)
+	require.Equal(t, blocks.blocks[2], d.lastDisseminated)
+
+	// Batches are no longer tracked once their inclusion blocks are finalized.
+	l1Client.include(6, txMgr.hashes[3:]...)
+	require.NoError(t, d.l1State.OnFinalized(ctx, &ethTypes.Header{Number: big.NewInt(5)}))
+	require.NoError(t, d.checkSequenced(ctx))
+	require.Len(t, d.sequenced, 2)
+	require.Equal(t, blocks.blocks[0], d.lastFinalized)
+	require.NoError(t, d.l1State.OnFinalized(ctx, &ethTypes.Header{Number: big.NewInt(6)}))
+	require.NoError(t, d.checkSequenced(ctx))
+	require.Empty(t, d.sequenced)
+	require.Equal(t, blocks.blocks[2], d.lastFinalized)
+}
+
+// mempoolL1 keeps published txs in a mempool (where they can be replaced at bumped fees) until they're mined.
+type mempoolL1 struct {
+	// Whether a block is mined just before each tx is received, so that pending txs are included before any
+	// replacement of them (e.g. a cancellation) arrives. Otherwise, blocks are only mined by `mine`.
+	minePending bool
+
+	mu       sync.Mutex
+	pool     map[uint64]*ethTypes.Transaction // By nonce.
+	mined    []*ethTypes.Transaction          // By nonce.
+	receipts map[common.Hash]*ethTypes.Receipt
+	head     uint64
+}
+
+func newMempoolL1() *mempoolL1 {
+	return &mempoolL1{pool: map[uint64]*ethTypes.Transaction{}, receipts: map[common.Hash]*ethTypes.Receipt{}}
+}
+
+func (l *mempoolL1) mine() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mineLocked()
+}
+
+func (l *mempoolL1) mineLocked() {
+	l.head++
+	for tx, ok := l.pool[uint64(len(l.mined))]; ok; tx, ok = l.pool[uint64(len(l.mined))] {
+		delete(l.pool, tx.Nonce())
+		l.mined = append(l.mined, tx)
+		l.receipts[tx.Hash()] = &ethTypes.Receipt{
+			Status:      ethTypes.ReceiptStatusSuccessful,
+			TxHash:      tx.Hash(),
+			BlockNumber: new(big.Int).SetUint64(l.head),
+			BlockHash:   common.BigToHash(new(big.Int).SetUint64(l.head)),
+		}
+	}
+}
+
+// Reorgs out the txs mined from the given nonce on, returning them to the mempool unless `drop` is set.
+func (l *mempoolL1) reorg(nonce uint64, drop bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, tx := range l.mined[nonce:] {
+		delete(l.receipts, tx.Hash())
+		if !drop {
+			l.pool[tx.Nonce()] = tx
+		}
+	}
+	l.mined = l.mined[:nonce]
+}
+
+// Returns the data of the mined txs (in nonce order), with cancellations being empty.
+func (l *mempoolL1) minedData() [][]byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var data [][]byte
+	for _, tx := range l.mined {
+		data = append(data, tx.Data())
+	}
+	return data
+}
+
+func (l *mempoolL1) SendTransaction(_ context.Context, tx *ethTypes.Transaction) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.minePending {
+		l.mineLocked()
+	}
+	if tx.Nonce() < uint64(len(l.mined)) {
+		return core.ErrNonceTooLow
+	}
+	if prev, ok := l.pool[tx.Nonce()]; ok {
+		if prev.Hash() == tx.Hash() {
+			return txpool.ErrAlreadyKnown
+		}
+		minTip := new(big.Int).Div(new(big.Int).Mul(prev.GasTipCap(), big.NewInt(110)), big.NewInt(100))
+		minFeeCap := new(big.Int).Div(new(big.Int).Mul(prev.GasFeeCap(), big.NewInt(110)), big.NewInt(100))
+		if tx.GasTipCap().Cmp(minTip) < 0 || tx.GasFeeCap().Cmp(minFeeCap) < 0 {
+			return txpool.ErrReplaceUnderpriced
+		}
+	}
+	l.pool[tx.Nonce()] = tx
+	return nil
+}
+
+func (l *mempoolL1) TransactionReceipt(_ context.Context, txHash common.Hash) (*ethTypes.Receipt, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if receipt, ok := l.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (l *mempoolL1) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return uint64(len(l.mined)), nil
+}
+
+func (l *mempoolL1) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	nonce := uint64(len(l.mined))
+	for ; l.pool[nonce] != nil; nonce++ {
+	}
+	return nonce, nil
+}
+
+func (l *mempoolL1) BlockNumber(context.Context) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.head, nil
+}
+
+func (l *mempoolL1) HeaderByNumber(context.Context, *big.Int) (*ethTypes.Header, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return &ethTypes.Header{Number: new(big.Int).SetUint64(l.head), BaseFee: big.NewInt(100)}, nil
+}
+
+func (l *mempoolL1) SuggestGasTipCap(context.Context) (*big.Int, error) { return big.NewInt(10), nil }
+func (l *mempoolL1) FeeHistory(context.Context, uint64, *big.Int, []float64) (*ethereum.FeeHistory, error) {
+	return nil, errors.New("unsupported")
+}
+func (l *mempoolL1) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) { return 21000, nil }
+
+// Appends batches with a `txmgr.TxManager`, as `bridge.TxManager` does (without ABI-encoding them).
+type batchTxManager struct{ *txmgr.TxManager }
+
+// Records published txs in the checkpoints, as the sidecar does.
+func (m batchTxManager) checkpointTo(checkpoints *FileCheckpointStore) {
+	m.SetPublishHook(func(tx *ethTypes.Transaction) { _ = checkpoints.AddPendingTx(tx) })
+}
+
+func (m batchTxManager) AppendTxBatchAsync(
+	ctx context.Context, batch []byte,
+) (*ethTypes.Transaction, <-chan txmgr.SendResult, error) {
+	inbox := common.HexToAddress("0x1b0c")
+	return m.SendAsync(ctx, txmgr.TxCandidate{TxData: batch, To: &inbox})
+}
+
+func (m batchTxManager) AppendTxBatchWithBlobsAsync(
+	context.Context, []byte, []kzg4844.Blob,
+) (*ethTypes.Transaction, <-chan txmgr.SendResult, error) {
+	return nil, nil, txmgr.ErrBlobsUnsupported
+}
+
+func newMempoolTxManager(t *testing.T, l1 *mempoolL1) batchTxManager {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	var (
+		chainID = big.NewInt(1)
+		signer  = ethTypes.LatestSignerForChainID(chainID)
+		cfg     = txmgr.Config{
+			ChainID:                   chainID,
+			NetworkTimeout:            time.Second,
+			FeeLimitMultiplier:        5,
+			ResubmissionTimeout:       20 * time.Millisecond,
+			ReceiptQueryInterval:      5 * time.Millisecond,
+			TxNotInMempoolTimeout:     time.Minute,
+			NumConfirmations:          1,
+			SafeAbortNonceTooLowCount: 3,
+			FeeStrategy:               txmgr.NodeFeeStrategy,
+			From:                      crypto.PubkeyToAddress(key.PublicKey),
+		}
+		signerFn = func(_ context.Context, _ common.Address, tx *ethTypes.Transaction) (*ethTypes.Transaction, error) {
+			return ethTypes.SignTx(tx, signer, key)
+		}
+	)
+	return batchTxManager{txmgr.NewTxManager(log.Root(), cfg, l1, signerFn, &metrics.NoopTxMetrics{})}
+}
+
+// Confirms all pending batches (as mined by `l1`).
+func confirmAll(t *testing.T, d *BatchDisseminator, l1 *mempoolL1) {
+	for len(d.pending) > 0 {
+		// Txs are sent asynchronously, so they may not have been received yet.
+		l1.mine()
+		require.NoError(t, d.confirmPending(context.Background(), false))
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDisseminatorCancelsAbandonedTxs(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		l1        = newMempoolL1()
+		d, blocks = newTestDisseminator(4, newMempoolTxManager(t, l1), 3)
+		batches   = [][]byte{{0x0, 1}, {0x0, 2}, {0x0, 3}}
+	)
+	d.l1Client = l1
+	require.NoError(t, d.disseminateBatches(ctx, 0))
+	confirmAll(t, d, l1)
+	require.Equal(t, batches, l1.minedData())
+
+	// The last two batches are reorged out, but their txs are back in the mempool, and get re-included (before
+	// they can be cancelled). They aren't resubmitted.
+	l1.minePending = true
+	l1.reorg(1, false)
+	require.NoError(t, d.checkSequenced(ctx))
+	require.Len(t, d.requeued, 2)
+	require.NoError(t, d.disseminateBatches(ctx, 0))
+	require.Empty(t, d.requeued)
+	require.Empty(t, d.pending)
+	require.Len(t, d.sequenced, 3)
+	require.Equal(t, blocks.blocks[2], d.lastDisseminated)
+	require.Equal(t, batches, l1.minedData())
+
+	// If the reorged txs are dropped from the mempool instead, the batches are resubmitted with the same nonces.
+	l1.minePending = false
+	l1.reorg(1, true)
+	require.NoError(t, d.checkSequenced(ctx))
+	require.NoError(t, d.disseminateBatches(ctx, 0))
+	confirmAll(t, d, l1)
+	require.Equal(t, blocks.blocks[2], d.lastDisseminated)
+	require.Equal(t, batches, l1.minedData())
+}
+
+func TestDisseminatorRestoreCancelsInFlightTxs(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		l1        = newMempoolL1()
+		txMgr     = newMempoolTxManager(t, l1)
+		d, blocks = newTestDisseminator(3, txMgr, 2)
+	)
+	d.l1Client = l1
+	txMgr.checkpointTo(d.checkpoints.(*FileCheckpointStore))
+	require.NoError(t, d.disseminateBatches(ctx, 0))
+	// The disseminator stops with both batches in-flight (and their txs, possibly fee-bumped, still in the mempool).
+	d.abortPending()
+
+	restarted, _ := newTestDisseminator(3, txMgr, 2)
+	restarted.l1Client, restarted.checkpoints = l1, d.checkpoints
+	require.NoError(t, restarted.restore(ctx))
+	require.Len(t, restarted.requeued, 2)
+	// The in-flight txs are mined before they can be cancelled, so their batches aren't resubmitted.
+	l1.minePending = true
+	require.NoError(t, restarted.disseminateBatches(ctx, 0))
+	require.Empty(t, restarted.requeued)
+	require.Empty(t, restarted.pending)
+	require.Len(t, restarted.sequenced, 2)
+	require.Equal(t, blocks.blocks[1], restarted.lastDisseminated)
+	require.Equal(t, [][]byte{{0x0, 1}, {0x0, 2}}, l1.minedData())
+}
+
+func TestPendingL2BlockRangeChecksLastEnqueued(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		d, blocks = newTestDisseminator(1, nil, 2)
+	)
+	start, end, _, err := d.pendingL2BlockRange(ctx)
+	require.NoError(t, err)
+	require.Equal(t, blocks.blocks[1].GetNumber()+1, start)
+	require.Equal(t, uint64(2), end)
+
+	// The last enqueued block was re-orged out of the L2 chain.
+	l2Client := d.l2Client.(*testL2Client)
+	l2Client.blocks[1] = ethTypes.NewBlockWithHeader(&ethTypes.Header{Number: big.NewInt(2), Extra: []byte{1}})
+	_, _, _, err = d.pendingL2BlockRange(ctx)
+	require.ErrorAs(t, err, &L2ReorgDetectedError{})
+}
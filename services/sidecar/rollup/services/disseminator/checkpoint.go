@@ -0,0 +1,160 @@
+package disseminator
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/specularL2/specular/services/sidecar/rollup/types"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+// Disseminator progress, persisted so that a restarted disseminator can resume where it left off.
+type Checkpoint struct {
+	// Last L2 block included in a batch sequenced in a finalized L1 block.
+	LastFinalized types.BlockID `json:"last_finalized"`
+	// Last L2 block included in a sequenced batch.
+	LastDisseminated types.BlockID `json:"last_disseminated"`
+	// Batches sequenced in L1 blocks that aren't finalized yet, in order.
+	Sequenced []SequencedBatch `json:"sequenced,omitempty"`
+	// Batches currently being submitted to L1, in submission (nonce) order.
+	InFlight []InFlightBatch `json:"in_flight,omitempty"`
+	// Batches to be resubmitted after the in-flight batches (e.g. due to an L1 reorg), in order.
+	Requeued []InFlightBatch `json:"requeued,omitempty"`
+}
+
+// A batch submitted to L1, but not yet confirmed.
+type InFlightBatch struct {
+	// Last L2 block included in the batch.
+	LastEnqueued types.BlockID `json:"last_enqueued"`
+	// Data appended to L1.
+	Data hexutil.Bytes `json:"data"`
+	// Nonce of the L1 tx(s) carrying the batch.
+	Nonce uint64 `json:"nonce"`
+	// Hashes of all L1 txs published for the batch (including fee-bumped replacements).
+	PendingTxHashes []common.Hash `json:"pending_tx_hashes,omitempty"`
+}
+
+// A batch sequenced on L1.
+type SequencedBatch struct {
+	InFlightBatch
+	// L1 block including the batch.
+	L1Block types.BlockID `json:"l1_block"`
+}
+
+func newSequencedBatch(batch InFlightBatch, receipt *ethTypes.Receipt) SequencedBatch {
+	return SequencedBatch{batch, types.NewBlockID(receipt.BlockNumber.Uint64(), receipt.BlockHash)}
+}
+
+func (c *Checkpoint) HasInFlightBatch() bool { return len(c.InFlight) > 0 }
+
+// Stores the checkpoint as a JSON file, written atomically.
+// If the path is empty, the checkpoint is only kept in memory.
+type FileCheckpointStore struct {
+	path string
+	cp   *Checkpoint
+	mu   sync.Mutex
+}
+
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+// Returns the last saved checkpoint, or nil if there is none.
+func (s *FileCheckpointStore) Load() (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cp == nil && s.path != "" {
+		data, err := os.ReadFile(s.path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+		}
+		var cp Checkpoint
+		if err := json.Unmarshal(data, &cp); err != nil {
+			return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+		}
+		s.cp = &cp
+	}
+	if s.cp == nil {
+		return nil, nil
+	}
+	return s.cp.copy(), nil
+}
+
+func (s *FileCheckpointStore) Save(cp *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save(cp.copy())
+}
+
+// Records a tx published for the in-flight batch with the same nonce (requeued batches aren't being submitted).
+// No-op if there is no such batch, or the tx is already recorded.
+func (s *FileCheckpointStore) AddPendingTx(tx *ethTypes.Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cp == nil {
+		return nil
+	}
+	cp := s.cp.copy()
+	for i := range cp.InFlight {
+		batch := &cp.InFlight[i]
+		if batch.Nonce != tx.Nonce() {
+			continue
+		}
+		for _, hash := range batch.PendingTxHashes {
+			if hash == tx.Hash() {
+				return nil
+			}
+		}
+		batch.PendingTxHashes = append(batch.PendingTxHashes, tx.Hash())
+		return s.save(cp)
+	}
+	return nil
+}
+
+func (s *FileCheckpointStore) save(cp *Checkpoint) error {
+	if s.path != "" {
+		data, err := json.Marshal(cp)
+		if err != nil {
+			return fmt.Errorf("failed to encode checkpoint: %w", err)
+		}
+		tmpPath := s.path + ".tmp"
+		if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+			return fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+		if err := os.Rename(tmpPath, s.path); err != nil {
+			return fmt.Errorf("failed to replace checkpoint: %w", err)
+		}
+	}
+	s.cp = cp
+	return nil
+}
+
+// Returns a deep copy, so that stored checkpoints aren't mutated by callers.
+func (c *Checkpoint) copy() *Checkpoint {
+	cp := &Checkpoint{LastFinalized: c.LastFinalized, LastDisseminated: c.LastDisseminated}
+	for _, batch := range c.Sequenced {
+		batch.InFlightBatch = batch.copy()
+		cp.Sequenced = append(cp.Sequenced, batch)
+	}
+	for _, batch := range c.InFlight {
+		cp.InFlight = append(cp.InFlight, batch.copy())
+	}
+	for _, batch := range c.Requeued {
+		cp.Requeued = append(cp.Requeued, batch.copy())
+	}
+	return cp
+}
+
+func (b InFlightBatch) copy() InFlightBatch {
+	b.PendingTxHashes = append([]common.Hash(nil), b.PendingTxHashes...)
+	return b
+}
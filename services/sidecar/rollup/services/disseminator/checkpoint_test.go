@@ -0,0 +1,52 @@
+package disseminator
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/services/sidecar/rollup/types"
+)
+
+func TestFileCheckpointStore(t *testing.T) {
+	var (
+		path  = filepath.Join(t.TempDir(), "checkpoint.json")
+		store = NewFileCheckpointStore(path)
+		id    = types.NewBlockID(10, common.HexToHash("0xaa"))
+		tx    = func(nonce uint64, gasPrice int64) *ethTypes.Transaction {
+			return ethTypes.NewTx(&ethTypes.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(gasPrice)})
+		}
+	)
+	cp, err := store.Load()
+	require.NoError(t, err)
+	require.Nil(t, cp)
+
+	// Pending txs are only recorded for in-flight batches with a matching nonce.
+	require.NoError(t, store.Save(&Checkpoint{LastDisseminated: id}))
+	require.NoError(t, store.AddPendingTx(tx(1, 1)))
+	require.NoError(t, store.Save(&Checkpoint{
+		LastDisseminated: id,
+		InFlight: []InFlightBatch{
+			{LastEnqueued: types.NewBlockID(11, common.HexToHash("0xbb")), Data: []byte{0x0, 0x1}, Nonce: 1},
+			{LastEnqueued: types.NewBlockID(12, common.HexToHash("0xcc")), Data: []byte{0x0, 0x2}, Nonce: 2},
+		},
+	}))
+	require.NoError(t, store.AddPendingTx(tx(2, 1)))
+	require.NoError(t, store.AddPendingTx(tx(2, 2)))
+	require.NoError(t, store.AddPendingTx(tx(2, 2)))
+	require.NoError(t, store.AddPendingTx(tx(3, 1)))
+
+	// A new store (e.g. after a restart) reads the same checkpoint back.
+	cp, err = NewFileCheckpointStore(path).Load()
+	require.NoError(t, err)
+	require.Equal(t, id, cp.LastDisseminated)
+	require.True(t, cp.HasInFlightBatch())
+	require.Len(t, cp.InFlight, 2)
+	require.Equal(t, []byte{0x0, 0x1}, []byte(cp.InFlight[0].Data))
+	require.Empty(t, cp.InFlight[0].PendingTxHashes)
+	require.Equal(t, []common.Hash{tx(2, 1).Hash(), tx(2, 2).Hash()}, cp.InFlight[1].PendingTxHashes)
+}
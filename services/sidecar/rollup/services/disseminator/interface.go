@@ -6,13 +6,20 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
 	"github.com/specularL2/specular/services/sidecar/rollup/types"
 )
 
-type Config interface{ GetDisseminationInterval() time.Duration }
+type Config interface {
+	GetDisseminationInterval() time.Duration
+	GetUseBlobs() bool
+	GetMaxPendingTxs() uint64
+}
 
 type ForkChoiceState = engine.ForkchoiceStateV1
 type ForkChoiceResponse = engine.ForkChoiceResponse
@@ -21,19 +28,39 @@ type BuildPayloadResponse = engine.ForkChoiceResponse
 type BatchBuilder interface {
 	Enqueue(block *ethTypes.Block) error
 	LastEnqueued() types.BlockID
+	LastBuiltBlock() types.BlockID
 	Build(l1Head types.BlockID, currentLag uint64) ([]byte, error)
 	Advance()
 	Reset(lastEnqueued types.BlockID)
 }
 
 type TxManager interface {
-	AppendTxBatch(ctx context.Context, batch []byte) (*ethTypes.Receipt, error)
+	AppendTxBatchAsync(ctx context.Context, batch []byte) (*ethTypes.Transaction, <-chan txmgr.SendResult, error)
+	AppendTxBatchWithBlobsAsync(
+		ctx context.Context, batch []byte, blobs []kzg4844.Blob,
+	) (*ethTypes.Transaction, <-chan txmgr.SendResult, error)
+	// Replaces all of the account's pending txs with cancellations, blocking until they're resolved.
+	CancelPending(ctx context.Context) error
+}
+
+type DAProvider interface {
+	Store(ctx context.Context, batch []byte) ([]byte, error)
+}
+
+type CheckpointStore interface {
+	Load() (*Checkpoint, error)
+	Save(cp *Checkpoint) error
+}
+
+type L1Client interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error)
 }
 
 type L2Client interface {
 	EnsureDialed(ctx context.Context) error
 	BlockNumber(ctx context.Context) (uint64, error)
 	BlockByNumber(ctx context.Context, number *big.Int) (*ethTypes.Block, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error)
 	HeaderByTag(ctx context.Context, tag eth.BlockTag) (*ethTypes.Header, error)
 }
 
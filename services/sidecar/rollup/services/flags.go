@@ -5,6 +5,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
+	"github.com/specularL2/specular/services/sidecar/rollup/signer"
 )
 
 // Returns all supported flags.
@@ -13,9 +14,12 @@ func CLIFlags() []cli.Flag {
 		generalFlags,
 		protocolFlags,
 		disseminatorCLIFlags,
+		signer.CLIFlags(disseminatorSignerNamespace),
 		txmgr.CLIFlags(disseminatorTxMgrNamespace, txmgr.DefaultDisseminatorFlagValues),
 		validatorCLIFlags,
+		signer.CLIFlags(validatorSignerNamespace),
 		txmgr.CLIFlags(validatorTxMgrNamespace, txmgr.DefaultValidatorFlagValues),
+		metricsCLIFlags,
 	)
 }
 
@@ -32,6 +36,9 @@ const (
 	// txmgr flag namespaces
 	disseminatorTxMgrNamespace = "disseminator.txmgr"
 	validatorTxMgrNamespace    = "validator.txmgr"
+	// signer flag namespaces
+	disseminatorSignerNamespace = "disseminator"
+	validatorSignerNamespace    = "validator"
 )
 
 // These are all the command line flags we support.
@@ -54,12 +61,27 @@ var (
 		Usage:    "The L1 API submission endpoint",
 		Required: false,
 	}
+	l1BeaconEndpointFlag = &cli.StringFlag{
+		Name:     "l1.beacon-endpoint",
+		Usage:    "The L1 beacon API endpoint, serving the blobs batches may be carried in",
+		Required: false,
+	}
 	// L2 config flags
 	l2EndpointFlag = &cli.StringFlag{
 		Name:     "l2.endpoint",
 		Usage:    "The L2 API endpoint",
 		Required: true,
 	}
+	l2ProofEndpointFlag = &cli.StringFlag{
+		Name:     "l2.proof-endpoint",
+		Usage:    "The L2 API endpoint serving the proof namespace (required to play symmetric challenges)",
+		Required: false,
+	}
+	l2PollIntervalFlag = &cli.UintFlag{
+		Name:  "l2.poll-interval",
+		Usage: "Time between polls for new L2 heads (in seconds)",
+		Value: 2,
+	}
 	// Chain config protocol flags.
 	protocolRollupCfgPathFlag = &cli.StringFlag{
 		Name:     "protocol.rollup-cfg-path",
@@ -71,14 +93,6 @@ var (
 		Name:  "disseminator",
 		Usage: "Whether this node is a disseminator",
 	}
-	disseminatorPrivateKeyFlag = &cli.StringFlag{
-		Name:  "disseminator.private-key",
-		Usage: "The private key for rollup_cfg['system_config']['batcherAddr']]",
-	}
-	disseminatorClefEndpointFlag = &cli.StringFlag{
-		Name:  "disseminator.clef-endpoint",
-		Usage: "The endpoint of the Clef instance that should be used as a disseminator signer",
-	}
 	disseminatorIntervalFlag = &cli.UintFlag{
 		Name:  "disseminator.interval",
 		Usage: "Time between batch dissemination steps (seconds)",
@@ -96,6 +110,30 @@ var (
 		Name:  "disseminator.max-batch-size",
 		Usage: "The maximun size of a batch tx submitted to L1 (bytes)",
 	}
+	disseminatorBatchEncoderVersionFlag = &cli.Uint64Flag{
+		Name:  "disseminator.batch-encoder-version",
+		Usage: "The batch encoding format version (0: raw RLP, 1: compressed)",
+		Value: 0,
+	}
+	disseminatorCompressionAlgoFlag = &cli.StringFlag{
+		Name:  "disseminator.compression-algo",
+		Usage: "The compression algorithm for compressed batches (zlib, brotli or zstd)",
+		Value: "zlib",
+	}
+	disseminatorDAModeFlag = &cli.StringFlag{
+		Name:  "disseminator.da-mode",
+		Usage: "How batches are posted to L1 (calldata or blob)",
+		Value: CalldataDAMode,
+	}
+	disseminatorMaxPendingTxsFlag = &cli.Uint64Flag{
+		Name:  "disseminator.max-pending-txs",
+		Usage: "The maximum number of batch txs in-flight at once (1 disables pipelining)",
+		Value: 1,
+	}
+	disseminatorCheckpointPathFlag = &cli.StringFlag{
+		Name:  "disseminator.checkpoint-path",
+		Usage: "Path of the file used to checkpoint dissemination progress across restarts (disabled if empty)",
+	}
 	disseminatorMaxSafeLagFlag = &cli.Uint64Flag{
 		Name:  "disseminator.max-safe-lag",
 		Usage: "The maximum, in l2 blocks, that is safe for the disseminator to lag the sequencer",
@@ -109,39 +147,54 @@ var (
 		Name:  "validator",
 		Usage: "Whether this node is a validator",
 	}
-	validatorPrivateKeyFlag = &cli.StringFlag{
-		Name:  "validator.private-key",
-		Usage: "The private key for the validator",
-	}
-	validatorClefEndpointFlag = &cli.StringFlag{
-		Name:  "validator.clef-endpoint",
-		Usage: "The endpoint of the Clef instance that should be used as a validator signer",
-	}
 	validatorValidationIntervalFlag = &cli.UintFlag{
 		Name:  "validator.validation-interval",
 		Usage: "Time between validation steps (seconds)",
 		Value: 10,
 	}
+	validatorStateCommitmentVersionFlag = &cli.Uint64Flag{
+		Name:  "validator.state-commitment-version",
+		Usage: "The assertion state commitment version (0: L2 block; 1: L2 block, L1 anchor and withdrawals root, not yet supported by L1Portal)",
+		Value: 0,
+	}
+	// Metrics config flags
+	metricsEnableFlag = &cli.BoolFlag{
+		Name:  "metrics.enable",
+		Usage: "Whether to serve prometheus metrics",
+	}
+	metricsAddrFlag = &cli.StringFlag{
+		Name:  "metrics.addr",
+		Usage: "The address the metrics server listens on",
+		Value: "0.0.0.0",
+	}
+	metricsPortFlag = &cli.Uint64Flag{
+		Name:  "metrics.port",
+		Usage: "The port the metrics server listens on",
+		Value: 7300,
+	}
 )
 
 var (
-	generalFlags         = []cli.Flag{VerbosityFlag, l1EndpointFlag, l1SubmissionEndpointFlag, l2EndpointFlag}
+	generalFlags         = []cli.Flag{VerbosityFlag, l1EndpointFlag, l1SubmissionEndpointFlag, l1BeaconEndpointFlag, l2EndpointFlag, l2ProofEndpointFlag, l2PollIntervalFlag}
 	protocolFlags        = []cli.Flag{protocolRollupCfgPathFlag}
 	disseminatorCLIFlags = []cli.Flag{
 		disseminatorEnableFlag,
-		disseminatorPrivateKeyFlag,
-		disseminatorClefEndpointFlag,
 		disseminatorIntervalFlag,
 		disseminatorSubSafetyMarginFlag,
 		disseminatorTargetBatchSizeFlag,
 		disseminatorMaxBatchSizeFlag,
+		disseminatorBatchEncoderVersionFlag,
+		disseminatorCompressionAlgoFlag,
+		disseminatorDAModeFlag,
+		disseminatorMaxPendingTxsFlag,
+		disseminatorCheckpointPathFlag,
 		disseminatorMaxSafeLagFlag,
 		disseminatorMaxSafeLagDeltaFlag,
 	}
 	validatorCLIFlags = []cli.Flag{
 		validatorEnableFlag,
-		validatorPrivateKeyFlag,
-		validatorClefEndpointFlag,
 		validatorValidationIntervalFlag,
+		validatorStateCommitmentVersionFlag,
 	}
+	metricsCLIFlags = []cli.Flag{metricsEnableFlag, metricsAddrFlag, metricsPortFlag}
 )
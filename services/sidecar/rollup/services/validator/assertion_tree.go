@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+)
+
+type assertionStatus int
+
+const (
+	// The assertion's block hasn't been validated locally yet.
+	assertionUnchecked assertionStatus = iota
+	// The assertion's state commitment matches the local L2 chain.
+	assertionCorrect
+	// The assertion's state commitment conflicts with the local L2 chain.
+	assertionIncorrect
+)
+
+// An assertion created on L1, by any validator.
+type assertionNode struct {
+	id              uint64
+	parentID        uint64
+	asserter        common.Address
+	blockNum        uint64
+	stateCommitment Bytes32
+	proposalTime    uint64 // L1 block the assertion was created in.
+	deadline        uint64 // L1 block its challenge period ends at.
+	status          assertionStatus
+	children        []uint64 // In creation order.
+}
+
+func (n *assertionNode) attrs() assertionAttributes {
+	return assertionAttributes{l2BlockNum: n.blockNum, l2StateCommitment: n.stateCommitment}
+}
+
+// Local index of the assertions created on L1, rooted at the last confirmed assertion.
+// Assertions not descending from the root (i.e. created before it was confirmed) are ignored.
+type assertionTree struct {
+	rootID             uint64
+	nodes              map[uint64]*assertionNode
+	lastIndexedL1Block uint64
+}
+
+// Returns a tree rooted at the given (confirmed) assertion, which is assumed to be correct.
+func newAssertionTree(rootID uint64, root bindings.IRollupAssertion) *assertionTree {
+	node := newAssertionNode(rootID, common.Address{}, root)
+	node.status = assertionCorrect
+	return &assertionTree{rootID: rootID, nodes: map[uint64]*assertionNode{rootID: node}}
+}
+
+func newAssertionNode(id uint64, asserter common.Address, assertion bindings.IRollupAssertion) *assertionNode {
+	return &assertionNode{
+		id:              id,
+		parentID:        assertion.Parent.Uint64(),
+		asserter:        asserter,
+		blockNum:        assertion.BlockNum.Uint64(),
+		stateCommitment: assertion.StateCommitment,
+		proposalTime:    assertion.ProposalTime.Uint64(),
+		deadline:        assertion.Deadline.Uint64(),
+	}
+}
+
+// Inserts an assertion under its parent. Returns false if the parent isn't in the tree, or the assertion already is.
+func (t *assertionTree) insert(id *big.Int, asserter common.Address, assertion bindings.IRollupAssertion) bool {
+	node := newAssertionNode(id.Uint64(), asserter, assertion)
+	if _, ok := t.nodes[node.id]; ok {
+		return false
+	}
+	parent, ok := t.nodes[node.parentID]
+	if !ok {
+		return false
+	}
+	parent.children = append(parent.children, node.id)
+	t.nodes[node.id] = node
+	return true
+}
+
+func (t *assertionTree) get(id uint64) (*assertionNode, bool) {
+	node, ok := t.nodes[id]
+	return node, ok
+}
+
+// Returns the children of the given assertion, in creation order.
+func (t *assertionTree) children(id uint64) []*assertionNode {
+	parent, ok := t.nodes[id]
+	if !ok {
+		return nil
+	}
+	children := make([]*assertionNode, 0, len(parent.children))
+	for _, childID := range parent.children {
+		children = append(children, t.nodes[childID])
+	}
+	return children
+}
+
+// Returns the unchecked assertions for blocks up to (and including) `blockNum`, in ID order.
+func (t *assertionTree) unchecked(blockNum uint64) []*assertionNode {
+	var nodes []*assertionNode
+	for _, node := range t.nodes {
+		if node.status == assertionUnchecked && node.blockNum <= blockNum {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+	return nodes
+}
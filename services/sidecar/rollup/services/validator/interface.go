@@ -17,9 +17,11 @@ import (
 type Config interface {
 	GetAccountAddr() common.Address
 	GetValidationInterval() time.Duration
+	GetStateCommitmentVersion() uint64
 }
 
 type TxManager interface {
+	Recover(ctx context.Context) error
 	Stake(ctx context.Context, stakeAmount *big.Int) (*ethTypes.Receipt, error)
 	CreateAssertion(
 		ctx context.Context,
@@ -28,6 +30,8 @@ type TxManager interface {
 		l1BlockHash common.Hash,
 		l1BlockNum *big.Int,
 	) (*ethTypes.Receipt, error)
+	AdvanceStake(ctx context.Context, assertionID *big.Int) (*ethTypes.Receipt, error)
+	ChallengeAssertion(ctx context.Context, players [2]common.Address, assertionIDs [2]*big.Int) (*ethTypes.Receipt, error)
 	ConfirmFirstUnresolvedAssertion(ctx context.Context) (*ethTypes.Receipt, error)
 	RejectFirstUnresolvedAssertion(context.Context, common.Address) (*ethTypes.Receipt, error)
 	RemoveStake(context.Context, common.Address) (*ethTypes.Receipt, error)
@@ -37,6 +41,9 @@ type BridgeClient interface {
 	GetRequiredStakeAmount(context.Context) (*big.Int, error)
 	GetStaker(context.Context, common.Address) (bindings.IRollupStaker, error)
 	GetAssertion(context.Context, *big.Int) (bindings.IRollupAssertion, error)
+	GetLastConfirmedAssertionID(context.Context) (*big.Int, error)
+	IsStakedOnAssertion(ctx context.Context, assertionID *big.Int, address common.Address) (bool, error)
+	GetAssertionCreatedEvents(ctx context.Context, start, end uint64) ([]*bindings.IRollupAssertionCreated, error)
 	RequireFirstUnresolvedAssertionIsConfirmable(context.Context) (*bridge.UnsatisfiedCondition, error)
 	RequireFirstUnresolvedAssertionIsRejectable(context.Context, common.Address) (*bridge.UnsatisfiedCondition, error)
 }
@@ -48,10 +55,27 @@ type EthState interface {
 }
 
 type L2Client interface {
+	L2StorageClient
 	EnsureDialed(ctx context.Context) error
 	BlockNumber(ctx context.Context) (uint64, error)
 	BlockByNumber(ctx context.Context, number *big.Int) (*ethTypes.Block, error)
 	HeaderByTag(ctx context.Context, tag eth.BlockTag) (*ethTypes.Header, error)
 }
 
+type L2StorageClient interface {
+	StorageAtHash(ctx context.Context, account common.Address, key common.Hash, blockHash common.Hash) ([]byte, error)
+	StorageRoot(ctx context.Context, account common.Address, blockHash common.Hash) (common.Hash, error)
+}
+
+// Verifies the batches appended to L1 in a given block against the local L2 chain.
+type BatchVerifier interface {
+	VerifyL1Block(ctx context.Context, l1BlockNum uint64) error
+}
+
+type Metricer interface {
+	RecordAssertionCreated(l2BlockNum uint64)
+	RecordAssertionConfirmed()
+	RecordAssertionRejected()
+}
+
 type ErrGroup interface{ Go(f func() error) }
@@ -1,11 +1,18 @@
 package validator
 
 import (
+	"context"
+	"encoding/binary"
 	"errors"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 
+	"github.com/specularL2/specular/bindings-go/bindings"
+	rollupTypes "github.com/specularL2/specular/services/sidecar/rollup/types"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
 	"github.com/specularL2/specular/services/sidecar/utils/log"
 )
 
@@ -14,11 +21,19 @@ const V0VersionSize = 32
 const V0BlockHashOffset = 32
 const V0StateRootOffset = 64
 
+const V1BufSize = 192
+const V1BlockHashOffset = 32
+const V1StateRootOffset = 64
+const V1L1BlockHashOffset = 96
+const V1L1BlockNumOffset = 128
+const V1WithdrawalsRootOffset = 160
+
 var (
 	ErrInvalidStateCommitment        = errors.New("invalid state commitment")
 	ErrInvalidStateCommitmentVersion = errors.New("invalid state commitment version")
 
 	StateCommitmentVersionV0 = Bytes32{}
+	StateCommitmentVersionV1 = Bytes32{31: 1}
 )
 
 type Bytes32 = [32]byte
@@ -49,6 +64,32 @@ func (o *StateCommitmentV0) Marshal() []byte {
 	return buf[:]
 }
 
+// StateCommitmentV1 extends V0 with the L1 block the L2 block was derived from (as last set in the L1Oracle),
+// and the storage root of the L2Portal (committing to all initiated withdrawals).
+type StateCommitmentV1 struct {
+	l2BlockHash     common.Hash
+	l2StateRoot     common.Hash
+	l1BlockHash     common.Hash
+	l1BlockNum      uint64
+	withdrawalsRoot common.Hash
+}
+
+func (o *StateCommitmentV1) Version() Bytes32 {
+	return StateCommitmentVersionV1
+}
+
+func (o *StateCommitmentV1) Marshal() []byte {
+	var buf [V1BufSize]byte
+	version := o.Version()
+	copy(buf[:V0VersionSize], version[:])
+	copy(buf[V1BlockHashOffset:V1StateRootOffset], o.l2BlockHash[:])
+	copy(buf[V1StateRootOffset:V1L1BlockHashOffset], o.l2StateRoot[:])
+	copy(buf[V1L1BlockHashOffset:V1L1BlockNumOffset], o.l1BlockHash[:])
+	binary.BigEndian.PutUint64(buf[V1WithdrawalsRootOffset-8:V1WithdrawalsRootOffset], o.l1BlockNum)
+	copy(buf[V1WithdrawalsRootOffset:], o.withdrawalsRoot[:])
+	return buf[:]
+}
+
 // StateCommitment returns the keccak256 hash of the marshaled L2 state commitment
 func StateCommitment(stateCommitment VersionedStateCommitment) common.Hash {
 	marshaled := stateCommitment.Marshal()
@@ -65,6 +106,8 @@ func UnmarshalStateCommitment(data []byte) (VersionedStateCommitment, error) {
 	switch ver {
 	case StateCommitmentVersionV0:
 		return unmarshalStateCommitmentV0(data)
+	case StateCommitmentVersionV1:
+		return unmarshalStateCommitmentV1(data)
 	default:
 		return nil, ErrInvalidStateCommitmentVersion
 	}
@@ -80,3 +123,105 @@ func unmarshalStateCommitmentV0(data []byte) (*StateCommitmentV0, error) {
 	copy(l2State.l2StateRoot[:], data[V0StateRootOffset:])
 	return &l2State, nil
 }
+
+func unmarshalStateCommitmentV1(data []byte) (*StateCommitmentV1, error) {
+	if len(data) != V1BufSize {
+		return nil, ErrInvalidStateCommitment
+	}
+	// The L1 block number is a uint256, whose upper bytes must be zero.
+	for _, b := range data[V1L1BlockNumOffset : V1WithdrawalsRootOffset-8] {
+		if b != 0 {
+			return nil, ErrInvalidStateCommitment
+		}
+	}
+	var l2State StateCommitmentV1
+	// data[:32] is the version
+	copy(l2State.l2BlockHash[:], data[V1BlockHashOffset:V1StateRootOffset])
+	copy(l2State.l2StateRoot[:], data[V1StateRootOffset:V1L1BlockHashOffset])
+	copy(l2State.l1BlockHash[:], data[V1L1BlockHashOffset:V1L1BlockNumOffset])
+	l2State.l1BlockNum = binary.BigEndian.Uint64(data[V1WithdrawalsRootOffset-8 : V1WithdrawalsRootOffset])
+	copy(l2State.withdrawalsRoot[:], data[V1WithdrawalsRootOffset:])
+	return &l2State, nil
+}
+
+// StateCommitter computes the state commitments of L2 blocks (as asserted), in a given version.
+type StateCommitter struct {
+	version  uint64
+	l2Client L2StorageClient // Reads the state committed to by V1 commitments.
+}
+
+func NewStateCommitter(version uint64, l2Client L2StorageClient) *StateCommitter {
+	return &StateCommitter{version: version, l2Client: l2Client}
+}
+
+// Returns the state commitment of the given L2 block.
+func (c *StateCommitter) Commit(ctx context.Context, header *types.Header) (VersionedStateCommitment, error) {
+	switch c.version {
+	case 0:
+		return &StateCommitmentV0{header.Hash(), header.Root}, nil
+	case 1:
+		l1BlockNum, l1BlockHash, err := c.getL1Anchor(ctx, header.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get L1 anchor: %w", err)
+		}
+		withdrawalsRoot, err := c.l2Client.StorageRoot(ctx, rollupTypes.L2PortalAddr, header.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get withdrawals root: %w", err)
+		}
+		return &StateCommitmentV1{header.Hash(), header.Root, l1BlockHash, l1BlockNum, withdrawalsRoot}, nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrInvalidStateCommitmentVersion, c.version)
+	}
+}
+
+// Returns the L1 block the given L2 block was derived from, as set in the L1Oracle at that block.
+// Storage is read at the L2 block hash, so the result is consistent with the block even across L2 re-orgs.
+func (c *StateCommitter) getL1Anchor(ctx context.Context, l2BlockHash common.Hash) (uint64, common.Hash, error) {
+	slots, err := getL1OracleSlots()
+	if err != nil {
+		return 0, common.Hash{}, err
+	}
+	number, err := c.l2Client.StorageAtHash(ctx, rollupTypes.L1OracleAddr, slots.number, l2BlockHash)
+	if err != nil {
+		return 0, common.Hash{}, fmt.Errorf("failed to get L1Oracle number: %w", err)
+	}
+	hash, err := c.l2Client.StorageAtHash(ctx, rollupTypes.L1OracleAddr, slots.hash, l2BlockHash)
+	if err != nil {
+		return 0, common.Hash{}, fmt.Errorf("failed to get L1Oracle hash: %w", err)
+	}
+	return new(big.Int).SetBytes(number).Uint64(), common.BytesToHash(hash), nil
+}
+
+// Returns the hash of the state commitment of the given L2 block (see `StateCommitment`).
+func (c *StateCommitter) StateCommitment(ctx context.Context, header *types.Header) (common.Hash, error) {
+	stateCommitment, err := c.Commit(ctx, header)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return StateCommitment(stateCommitment), nil
+}
+
+// Storage slots of the L1 block number and hash in the L1Oracle.
+type l1OracleSlots struct {
+	number common.Hash
+	hash   common.Hash
+}
+
+func getL1OracleSlots() (l1OracleSlots, error) {
+	layout, err := bindings.GetStorageLayout("L1Oracle")
+	if err != nil {
+		return l1OracleSlots{}, fmt.Errorf("failed to get L1Oracle storage layout: %w", err)
+	}
+	numberEntry, err := layout.GetStorageLayoutEntry("number")
+	if err != nil {
+		return l1OracleSlots{}, fmt.Errorf("failed to get L1Oracle number slot: %w", err)
+	}
+	hashEntry, err := layout.GetStorageLayoutEntry("hash")
+	if err != nil {
+		return l1OracleSlots{}, fmt.Errorf("failed to get L1Oracle hash slot: %w", err)
+	}
+	return l1OracleSlots{
+		number: common.BigToHash(new(big.Int).SetUint64(uint64(numberEntry.Slot))),
+		hash:   common.BigToHash(new(big.Int).SetUint64(uint64(hashEntry.Slot))),
+	}, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/specularL2/specular/services/sidecar/rollup/derivation"
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
 	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
 	"github.com/specularL2/specular/services/sidecar/utils/fmt"
@@ -17,9 +18,20 @@ import (
 
 var transactTimeout = 10 * time.Minute
 
+// Maximum number of L1 blocks to filter for events in a single request.
+const maxFilterBlockRange = 2000
+
+// Number of most recent L1 block hashes available to contracts (see `BLOCKHASH`).
+const maxL1BlockHashAge = 256
+
+// Number of L1 blocks an L1 anchor must remain checkable for after being passed to `CreateAssertion`,
+// to allow for the tx to be included.
+const l1AnchorSafetyMargin = maxL1BlockHashAge / 2
+
 type (
 	unexpectedSystemStateError struct{ msg string }
 	l2ReorgDetectedError       struct{ err error }
+	l1AnchorTooOldError        struct{ err error }
 )
 
 func (e unexpectedSystemStateError) Error() string {
@@ -27,6 +39,7 @@ func (e unexpectedSystemStateError) Error() string {
 }
 
 func (e l2ReorgDetectedError) Error() string { return e.err.Error() }
+func (e l1AnchorTooOldError) Error() string  { return e.err.Error() }
 
 type Validator struct {
 	cfg            Config
@@ -34,12 +47,20 @@ type Validator struct {
 	l1BridgeClient BridgeClient
 	l1State        EthState
 	l2Client       L2Client
+	committer      *StateCommitter
+	batches        BatchVerifier // Nil if batches aren't verified.
+	metrics        Metricer
+	newHeads       <-chan struct{} // Signals new L1/L2 heads (nil if not subscribed).
+
+	lastVerifiedL1Block uint64 // Last L1 block whose batches were verified.
 
 	lastCreatedAssertionAttrs assertionAttributes
+	assertions                *assertionTree // Assertions created by all validators.
 }
 
 type assertionAttributes struct {
 	l2BlockNum        uint64
+	l2BlockHash       common.Hash // Zero if unknown (i.e. for assertions created by others).
 	l2StateCommitment Bytes32
 }
 
@@ -49,8 +70,21 @@ func NewValidator(
 	l1BridgeClient BridgeClient,
 	l1State EthState,
 	l2Client L2Client,
+	batches BatchVerifier,
+	metrics Metricer,
+	newHeads <-chan struct{},
 ) *Validator {
-	return &Validator{cfg: cfg, l1TxMgr: l1TxMgr, l1BridgeClient: l1BridgeClient, l1State: l1State, l2Client: l2Client}
+	return &Validator{
+		cfg:            cfg,
+		l1TxMgr:        l1TxMgr,
+		l1BridgeClient: l1BridgeClient,
+		l1State:        l1State,
+		l2Client:       l2Client,
+		committer:      NewStateCommitter(cfg.GetStateCommitmentVersion(), l2Client),
+		batches:        batches,
+		metrics:        metrics,
+		newHeads:       newHeads,
+	}
 }
 
 func (v *Validator) Start(ctx context.Context, eg ErrGroup) error {
@@ -58,15 +92,24 @@ func (v *Validator) Start(ctx context.Context, eg ErrGroup) error {
 	if err := v.l2Client.EnsureDialed(ctx); err != nil {
 		return fmt.Errorf("failed to create L2 client: %w", err)
 	}
+	// Resume any txs left pending by a previous run (of the validator or challenger) before new ones are sent.
+	rCtx, cancel := context.WithTimeout(ctx, transactTimeout)
+	defer cancel()
+	if err := v.l1TxMgr.Recover(rCtx); err != nil {
+		return fmt.Errorf("failed to recover journaled txs: %w", err)
+	}
 	eg.Go(func() error { return v.start(ctx) })
 	log.Info("Validator started")
 	return nil
 }
 
-// Advances validator step-by-step.
+// Advances validator step-by-step, on every new L1/L2 head.
+// Falls back to stepping on a fixed interval (e.g. if head syncing stalls).
 func (v *Validator) start(ctx context.Context) error {
-	// TODO: Maybe we should change this to be event-based and listen for head advances on the chain
-	var ticker = time.NewTicker(v.cfg.GetValidationInterval())
+	var (
+		interval = v.cfg.GetValidationInterval()
+		ticker   = time.NewTicker(interval)
+	)
 	defer ticker.Stop()
 	// TODO: do this in the L2 consensus client, not here.
 	if err := v.validateGenesis(ctx); err != nil {
@@ -80,66 +123,141 @@ func (v *Validator) start(ctx context.Context) error {
 	}
 	for {
 		select {
+		case <-v.newHeads:
+			ticker.Reset(interval)
 		case <-ticker.C:
-			if err := v.step(ctx); err != nil {
-				log.Errorf("Failed to advance: %w", err)
-				if errors.As(err, &unexpectedSystemStateError{}) {
-					return fmt.Errorf("aborting: %w", err)
-				} else if errors.As(err, &l2ReorgDetectedError{}) {
-					log.Error("Detected L2 re-org, rolling back local state...")
-					if err := v.rollback(ctx); err != nil {
-						return fmt.Errorf("failed to rollback: %w", err)
-					}
-					log.Info("Rollback successful.", "last l2#", v.lastCreatedAssertionAttrs.l2BlockNum)
-				}
-			}
 		case <-ctx.Done():
 			log.Info("Aborting.")
 			return nil
 		}
+		if err := v.step(ctx); err != nil {
+			log.Errorf("Failed to advance: %w", err)
+			if errors.As(err, &unexpectedSystemStateError{}) {
+				return fmt.Errorf("aborting: %w", err)
+			} else if errors.As(err, &l2ReorgDetectedError{}) {
+				log.Error("Detected L2 re-org, rolling back local state...")
+				if err := v.rollback(ctx); err != nil {
+					return fmt.Errorf("failed to rollback: %w", err)
+				}
+				log.Info("Rollback successful.", "last l2#", v.lastCreatedAssertionAttrs.l2BlockNum)
+			} else if errors.As(err, &l1AnchorTooOldError{}) {
+				log.Warn("L1 anchor expired before the assertion was created; retrying without it.")
+			}
+		}
 	}
 }
 
-// Attempts to create a new assertion and confirm an existing assertion.
+// Attempts to create a new assertion, challenge an incorrect assertion and confirm an existing assertion.
 func (v *Validator) step(ctx context.Context) error {
+	if err := v.verifyBatches(ctx); err != nil {
+		return fmt.Errorf("failed to verify batches: %w", err)
+	}
 	// Try to create a new assertion.
 	// TODO: do this only if configured to be an active validator.
 	if err := v.tryCreateAssertion(ctx); err != nil {
 		return fmt.Errorf("failed to create assertion: %w", err)
 	}
+	if err := v.tryChallengeAssertion(ctx); err != nil {
+		return fmt.Errorf("failed to challenge assertion: %w", err)
+	}
 	if err := v.resolveFirstUnresolvedAssertion(ctx); err != nil {
 		return fmt.Errorf("failed to resolve assertion: %w", err)
 	}
 	return nil
 }
 
+// Verifies the batches appended to L1 up to the safe head against the local L2 chain.
+// A mismatch means the local L2 chain doesn't follow L1, so any assertion made from it can't be trusted.
+// Other failures (e.g. the L2 chain lagging behind L1) are retried on the next step.
+func (v *Validator) verifyBatches(ctx context.Context) error {
+	if v.batches == nil {
+		return nil
+	}
+	safe := v.l1State.Safe().GetNumber()
+	for ; v.lastVerifiedL1Block < safe; v.lastVerifiedL1Block++ {
+		if err := v.batches.VerifyL1Block(ctx, v.lastVerifiedL1Block+1); err != nil {
+			if errors.As(err, &derivation.DerivedBlockMismatchError{}) {
+				return unexpectedSystemStateError{"local l2 chain diverges from l1: " + err.Error()}
+			}
+			log.Warn("Failed to verify batches; retrying next step.", "l1#", v.lastVerifiedL1Block+1, "err", err)
+			return nil
+		}
+	}
+	return nil
+}
+
 // If enough time has passed and txs have been sequenced to L1, create a new assertion.
-// Add it to the queue for confirmation.
+// If another validator already created a correct assertion for the same block, advance stake onto it instead.
 func (v *Validator) tryCreateAssertion(ctx context.Context) error {
 	assertionAttrs, err := v.getNextAssertionAttrs(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get next assertion attrs: %w", err)
 	}
-	// TODO: remove single-validator assumption -- other validators may have inserted new assertions.
-	if assertionAttrs.l2BlockNum <= v.lastCreatedAssertionAttrs.l2BlockNum {
+	if err := v.indexAssertions(ctx); err != nil {
+		return fmt.Errorf("failed to index assertions: %w", err)
+	}
+	if err := v.checkAssertions(ctx, assertionAttrs.l2BlockNum); err != nil {
+		return fmt.Errorf("failed to check assertions: %w", err)
+	}
+	staker, err := v.l1BridgeClient.GetStaker(ctx, v.cfg.GetAccountAddr())
+	if err != nil {
+		return fmt.Errorf("failed to get staker: %w", err)
+	}
+	// The rollup contract only accepts new children of an assertion for the block of its first child,
+	// so existing children of our staked assertion constrain the next one.
+	children := v.assertions.children(staker.AssertionID.Uint64())
+	for _, child := range children {
+		if child.status == assertionCorrect {
+			return v.advanceStake(ctx, child)
+		}
+	}
+	if len(children) > 0 {
+		first := children[0]
+		// Rivals beyond the safe head can't be checked yet. Wait for it to catch up, but only for half of the first
+		// rival's challenge period, so that a rival asserting far ahead can't stall us until it's confirmed.
+		if first.blockNum > assertionAttrs.l2BlockNum {
+			if head := v.l1State.Head().GetNumber(); head < first.proposalTime+(first.deadline-first.proposalTime)/2 {
+				log.Info("Waiting for safe head to reach rival assertions.", "curr", assertionAttrs.l2BlockNum, "rival", first.blockNum)
+				return nil
+			}
+			log.Warn("Rival assertions still beyond safe head; asserting from unsafe head.", "rival", first.blockNum)
+		}
+		if assertionAttrs, err = v.getAssertionAttrs(ctx, first.blockNum); err != nil {
+			return fmt.Errorf("failed to get assertion attrs: %w", err)
+		}
+		for _, child := range children {
+			if child.stateCommitment == assertionAttrs.l2StateCommitment {
+				return v.advanceStake(ctx, child)
+			}
+		}
+		// All existing children are incorrect; create a correct sibling.
+	} else if assertionAttrs.l2BlockNum <= v.lastCreatedAssertionAttrs.l2BlockNum {
 		log.Info("No new blocks to create assertion for yet.", "curr", assertionAttrs.l2BlockNum, "last", v.lastCreatedAssertionAttrs.l2BlockNum)
 		return nil
 	}
 	cCtx, cancel := context.WithTimeout(ctx, transactTimeout)
 	defer cancel()
-	log.Info("Creating assertion...", "l2Block#", assertionAttrs.l2BlockNum)
-	// TODO: get latest L1 block number/hash from L2 CL client.
-	// This should probably happen atomically with getting the safe L2 tip.
-	// If error unwraps a `MismatchingL1BlockHashes`, return an `l2ReorgDetectedError`.
-	// This is easier once we have error bindings.
+	// The assertion is only valid if the L1 block its L2 block was derived from is still canonical.
+	l1BlockNum, l1BlockHash, err := v.getCheckableL1Anchor(ctx, assertionAttrs.l2BlockHash)
+	if err != nil {
+		return fmt.Errorf("failed to get L1 anchor: %w", err)
+	}
+	log.Info("Creating assertion...", "l2Block#", assertionAttrs.l2BlockNum, "l1Block#", l1BlockNum)
 	receipt, err := v.l1TxMgr.CreateAssertion(
 		cCtx,
 		assertionAttrs.l2StateCommitment,
 		big.NewInt(0).SetUint64(assertionAttrs.l2BlockNum),
-		common.Hash{},
-		common.Big0,
+		l1BlockHash,
+		new(big.Int).SetUint64(l1BlockNum),
 	)
 	if err != nil {
+		if bridge.IsRollupError(err, bridge.MismatchingL1BlockhashesErr) {
+			// The anchor's hash may have become unavailable on L1 before the tx was included.
+			if head := v.l1State.Head().GetNumber(); l1BlockNum+maxL1BlockHashAge <= head {
+				return l1AnchorTooOldError{fmt.Errorf("L1 block #%d is too old to be checked: %w", l1BlockNum, err)}
+			}
+			return l2ReorgDetectedError{fmt.Errorf("L1 block #%d (%s) was re-orged out: %w", l1BlockNum, l1BlockHash, err)}
+		}
 		return err
 	}
 	if receipt.Status == types.ReceiptStatusFailed {
@@ -147,11 +265,168 @@ func (v *Validator) tryCreateAssertion(ctx context.Context) error {
 	} else {
 		log.Info("Tx successfully published", "tx_hash", receipt.TxHash)
 		log.Info("Created assertion", "l2Block#", assertionAttrs.l2BlockNum)
+		v.metrics.RecordAssertionCreated(assertionAttrs.l2BlockNum)
 		v.lastCreatedAssertionAttrs = assertionAttrs
 	}
 	return nil
 }
 
+// Moves our stake onto an existing (correct) child of our staked assertion.
+func (v *Validator) advanceStake(ctx context.Context, assertion *assertionNode) error {
+	cCtx, cancel := context.WithTimeout(ctx, transactTimeout)
+	defer cancel()
+	log.Info("Advancing stake...", "assertionID", assertion.id, "l2Block#", assertion.blockNum)
+	receipt, err := v.l1TxMgr.AdvanceStake(cCtx, new(big.Int).SetUint64(assertion.id))
+	if err != nil {
+		return fmt.Errorf("failed to advance stake: %w", err)
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		log.Error("Tx successfully published but reverted", "tx_hash", receipt.TxHash)
+		return nil
+	}
+	log.Info("Advanced stake", "assertionID", assertion.id, "l2Block#", assertion.blockNum)
+	v.lastCreatedAssertionAttrs = assertion.attrs()
+	return nil
+}
+
+// Indexes the assertions created on L1 since the last indexed L1 block, up to the current safe L1 head.
+// Assertions are never un-indexed, so those in unsafe L1 blocks (which may be re-orged out) are left for later.
+func (v *Validator) indexAssertions(ctx context.Context) error {
+	head := v.l1State.Safe().GetNumber()
+	for start := v.assertions.lastIndexedL1Block + 1; start <= head; start += maxFilterBlockRange {
+		end := start + maxFilterBlockRange - 1
+		if end > head {
+			end = head
+		}
+		events, err := v.l1BridgeClient.GetAssertionCreatedEvents(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			assertion, err := v.l1BridgeClient.GetAssertion(ctx, event.AssertionID)
+			if err != nil {
+				return fmt.Errorf("failed to get assertion: %w", err)
+			}
+			if v.assertions.insert(event.AssertionID, event.AsserterAddr, assertion) {
+				log.Info("Indexed assertion", "assertionID", event.AssertionID, "asserter", event.AsserterAddr, "l2Block#", assertion.BlockNum)
+			}
+		}
+		v.assertions.lastIndexedL1Block = end
+	}
+	return nil
+}
+
+// Checks the state commitments of indexed assertions for blocks up to `safeBlockNum` against the local L2 chain.
+func (v *Validator) checkAssertions(ctx context.Context, safeBlockNum uint64) error {
+	for _, assertion := range v.assertions.unchecked(safeBlockNum) {
+		attrs, err := v.getAssertionAttrs(ctx, assertion.blockNum)
+		if err != nil {
+			return fmt.Errorf("failed to get assertion attrs: %w", err)
+		}
+		if attrs.l2StateCommitment == assertion.stateCommitment {
+			assertion.status = assertionCorrect
+			continue
+		}
+		assertion.status = assertionIncorrect
+		log.Warn(
+			"Detected conflicting assertion",
+			"assertionID", assertion.id,
+			"asserter", assertion.asserter,
+			"l2Block#", assertion.blockNum,
+			"expected", hex.EncodeToString(attrs.l2StateCommitment[:]),
+			"actual", hex.EncodeToString(assertion.stateCommitment[:]),
+		)
+	}
+	return nil
+}
+
+// Challenges an incorrect sibling of our staked assertion (or of any of its unconfirmed ancestors),
+// if neither we nor its asserter are already in a challenge. The challenge itself is driven by the challenger service.
+func (v *Validator) tryChallengeAssertion(ctx context.Context) error {
+	accountAddr := v.cfg.GetAccountAddr()
+	staker, err := v.l1BridgeClient.GetStaker(ctx, accountAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get staker: %w", err)
+	}
+	if staker.CurrentChallenge != (common.Address{}) {
+		return nil
+	}
+	for id := staker.AssertionID.Uint64(); id > v.assertions.rootID; {
+		parentID, err := v.getParentID(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, rival := range v.assertions.children(parentID) {
+			if rival.status != assertionIncorrect || rival.asserter == accountAddr {
+				continue
+			}
+			isChallengeable, err := v.isChallengeable(ctx, rival)
+			if err != nil {
+				return err
+			}
+			if isChallengeable {
+				return v.challengeAssertion(ctx, id, rival)
+			}
+		}
+		id = parentID
+	}
+	return nil
+}
+
+// Returns the parent of the given assertion, which may not be indexed yet (e.g. if we just created it).
+func (v *Validator) getParentID(ctx context.Context, id uint64) (uint64, error) {
+	if node, ok := v.assertions.get(id); ok {
+		return node.parentID, nil
+	}
+	assertion, err := v.l1BridgeClient.GetAssertion(ctx, new(big.Int).SetUint64(id))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get assertion: %w", err)
+	}
+	return assertion.Parent.Uint64(), nil
+}
+
+// Returns true if the rival's asserter is still staked on it, and isn't already in a challenge.
+func (v *Validator) isChallengeable(ctx context.Context, rival *assertionNode) (bool, error) {
+	isStaked, err := v.l1BridgeClient.IsStakedOnAssertion(ctx, new(big.Int).SetUint64(rival.id), rival.asserter)
+	if err != nil {
+		return false, fmt.Errorf("failed to check rival stake: %w", err)
+	}
+	if !isStaked {
+		return false, nil
+	}
+	staker, err := v.l1BridgeClient.GetStaker(ctx, rival.asserter)
+	if err != nil {
+		return false, fmt.Errorf("failed to get rival staker: %w", err)
+	}
+	return staker.CurrentChallenge == (common.Address{}), nil
+}
+
+// Opens a challenge between our (correct) assertion and a rival (incorrect) sibling.
+// The defender is the player whose assertion was created first.
+func (v *Validator) challengeAssertion(ctx context.Context, ours uint64, rival *assertionNode) error {
+	var (
+		players      = [2]common.Address{v.cfg.GetAccountAddr(), rival.asserter}
+		assertionIDs = [2]*big.Int{new(big.Int).SetUint64(ours), new(big.Int).SetUint64(rival.id)}
+	)
+	if rival.id < ours {
+		players[0], players[1] = players[1], players[0]
+		assertionIDs[0], assertionIDs[1] = assertionIDs[1], assertionIDs[0]
+	}
+	cCtx, cancel := context.WithTimeout(ctx, transactTimeout)
+	defer cancel()
+	log.Info("Challenging assertion...", "assertionID", rival.id, "asserter", rival.asserter, "l2Block#", rival.blockNum)
+	receipt, err := v.l1TxMgr.ChallengeAssertion(cCtx, players, assertionIDs)
+	if err != nil {
+		return err
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		log.Error("Tx successfully published but reverted", "tx_hash", receipt.TxHash)
+	} else {
+		log.Info("Challenged assertion", "assertionID", rival.id, "asserter", rival.asserter)
+	}
+	return nil
+}
+
 // If the first unresolved assertion is eligible for confirmation, trigger its confirmation. Otherwise, wait.
 func (v *Validator) resolveFirstUnresolvedAssertion(ctx context.Context) error {
 	unsatCondition, err := v.l1BridgeClient.RequireFirstUnresolvedAssertionIsConfirmable(ctx)
@@ -167,6 +442,7 @@ func (v *Validator) resolveFirstUnresolvedAssertion(ctx context.Context) error {
 			return fmt.Errorf("failed to confirm assertion: %w", err)
 		}
 		log.Info("Confirmed assertion")
+		v.metrics.RecordAssertionConfirmed()
 		return nil
 	}
 	// An assertion is not confirmable.
@@ -197,6 +473,7 @@ func (v *Validator) resolveFirstUnresolvedAssertion(ctx context.Context) error {
 			log.Warn("Failed to reject rejectable assertion.")
 			return err
 		}
+		v.metrics.RecordAssertionRejected()
 	}
 	// It is not confirmable, and it is not rejectable.
 	log.Info("Cannot reject unresolved assertion", "unsat", unsatCondition)
@@ -213,7 +490,31 @@ func (v *Validator) rollback(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to get assertion: %w", err)
 	}
-	v.lastCreatedAssertionAttrs = assertionAttributes{assertion.BlockNum.Uint64(), assertion.StateCommitment}
+	v.lastCreatedAssertionAttrs = assertionAttributes{
+		l2BlockNum:        assertion.BlockNum.Uint64(),
+		l2StateCommitment: assertion.StateCommitment,
+	}
+	// Re-index assertions from the older of the last confirmed and staked assertions.
+	rootID, err := v.l1BridgeClient.GetLastConfirmedAssertionID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get last confirmed assertion ID: %w", err)
+	}
+	root := assertion
+	if rootID.Cmp(staker.AssertionID) < 0 {
+		if root, err = v.l1BridgeClient.GetAssertion(ctx, rootID); err != nil {
+			return fmt.Errorf("failed to get last confirmed assertion: %w", err)
+		}
+	} else {
+		rootID = staker.AssertionID
+	}
+	v.assertions = newAssertionTree(rootID.Uint64(), root)
+	// Children are created no earlier than their parent.
+	// Batches are (re-)verified from the same L1 block, since they may have re-orged with the L2 chain.
+	v.lastVerifiedL1Block = 0
+	if proposalTime := root.ProposalTime.Uint64(); proposalTime > 0 {
+		v.assertions.lastIndexedL1Block = proposalTime - 1
+		v.lastVerifiedL1Block = proposalTime - 1
+	}
 	return nil
 }
 
@@ -223,7 +524,42 @@ func (v *Validator) getNextAssertionAttrs(ctx context.Context) (assertionAttribu
 	if err != nil {
 		return assertionAttributes{}, fmt.Errorf("failed to get latest safe header: %w", err)
 	}
-	return assertionAttributes{header.Number.Uint64(), StateCommitment(&StateCommitmentV0{header.Hash(), header.Root})}, nil
+	return v.newAssertionAttrs(ctx, header)
+}
+
+// Gets the attributes of an assertion for the given L2 block.
+func (v *Validator) getAssertionAttrs(ctx context.Context, blockNum uint64) (assertionAttributes, error) {
+	block, err := v.l2Client.BlockByNumber(ctx, new(big.Int).SetUint64(blockNum))
+	if err != nil {
+		return assertionAttributes{}, fmt.Errorf("failed to get L2 block: %w", err)
+	}
+	return v.newAssertionAttrs(ctx, block.Header())
+}
+
+func (v *Validator) newAssertionAttrs(ctx context.Context, header *types.Header) (assertionAttributes, error) {
+	stateCommitment, err := v.committer.Commit(ctx, header)
+	if err != nil {
+		return assertionAttributes{}, err
+	}
+	return assertionAttributes{header.Number.Uint64(), header.Hash(), StateCommitment(stateCommitment)}, nil
+}
+
+// Returns the L1 anchor of the given L2 block to pass to `CreateAssertion`, which checks it against L1 block hashes.
+// Only the last 256 L1 block hashes are available on L1, so anchors that may be older by the time the tx is included
+// (or unknown ones) are left out (zero).
+func (v *Validator) getCheckableL1Anchor(ctx context.Context, l2BlockHash common.Hash) (uint64, common.Hash, error) {
+	if l2BlockHash == (common.Hash{}) {
+		return 0, common.Hash{}, nil
+	}
+	l1BlockNum, l1BlockHash, err := v.committer.getL1Anchor(ctx, l2BlockHash)
+	if err != nil {
+		return 0, common.Hash{}, err
+	}
+	if head := v.l1State.Head().GetNumber(); l1BlockNum+maxL1BlockHashAge <= head+l1AnchorSafetyMargin {
+		log.Info("L1 anchor too old to be checked on L1", "l1Block#", l1BlockNum, "l1Head#", head)
+		return 0, common.Hash{}, nil
+	}
+	return l1BlockNum, l1BlockHash, nil
 }
 
 func (v *Validator) ensureStaked(ctx context.Context) error {
@@ -253,10 +589,12 @@ func (v *Validator) validateGenesis(ctx context.Context) error {
 		return fmt.Errorf("failed to get genesis assertion: %w", err)
 	}
 	// Check that the genesis assertion is correct.
-	genesisBlock, err := v.l2Client.BlockByNumber(ctx, common.Big0)
+	genesisBlock, err := v.l2Client.BlockByNumber(ctx, assertion.BlockNum)
 	if err != nil {
 		return fmt.Errorf("failed to get L2 genesis block: %w", err)
 	}
+	// The genesis assertion is always initialized with a V0 state commitment (see `Rollup.initializeGenesis`),
+	// whichever version later assertions are created with.
 	var (
 		genesisHeader          = genesisBlock.Header()
 		genesisStateCommitment = StateCommitment(&StateCommitmentV0{genesisHeader.Hash(), genesisHeader.Root})
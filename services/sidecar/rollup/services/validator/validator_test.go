@@ -0,0 +1,459 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/specularL2/specular/bindings-go/bindings"
+	"github.com/specularL2/specular/services/sidecar/rollup/derivation"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/bridge"
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth"
+	rollupTypes "github.com/specularL2/specular/services/sidecar/rollup/types"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+var (
+	testValidatorAddr = common.HexToAddress("0x01")
+	testRivalAddr     = common.HexToAddress("0x02")
+)
+
+const testChallengePeriod = 10 // In L1 blocks.
+
+type testConfig struct{ stateCommitmentVersion uint64 }
+
+func (testConfig) GetAccountAddr() common.Address       { return testValidatorAddr }
+func (testConfig) GetValidationInterval() time.Duration { return time.Second }
+func (c testConfig) GetStateCommitmentVersion() uint64  { return c.stateCommitmentVersion }
+
+// testRollup is a minimal in-memory stand-in for the rollup contract (implementing both TxManager and BridgeClient).
+// Assertions are created in L1 block `l1Block`.
+type testRollup struct {
+	l1Block    uint64
+	assertions []bindings.IRollupAssertion
+	asserters  []common.Address
+	stakers    map[common.Address]uint64
+	advanced   []uint64
+	challenges [][2]*big.Int
+	l1Anchors  []rollupTypes.BlockID // L1 blocks passed to `CreateAssertion`.
+	createErr  error
+	onCreate   func() // Called on `CreateAssertion`, before failing with `createErr`.
+}
+
+func newTestRollup() *testRollup {
+	genesis := bindings.IRollupAssertion{
+		BlockNum:        common.Big0,
+		Parent:          common.Big0,
+		ProposalTime:    common.Big0,
+		Deadline:        common.Big0,
+		StateCommitment: testStateCommitment(0),
+	}
+	return &testRollup{
+		l1Block:    1,
+		assertions: []bindings.IRollupAssertion{genesis},
+		asserters:  []common.Address{{}},
+		stakers:    map[common.Address]uint64{testValidatorAddr: 0, testRivalAddr: 0},
+	}
+}
+
+func (r *testRollup) create(asserter common.Address, stateCommitment Bytes32, blockNum uint64) {
+	r.assertions = append(r.assertions, bindings.IRollupAssertion{
+		StateCommitment: stateCommitment,
+		BlockNum:        new(big.Int).SetUint64(blockNum),
+		Parent:          new(big.Int).SetUint64(r.stakers[asserter]),
+		ProposalTime:    new(big.Int).SetUint64(r.l1Block),
+		Deadline:        new(big.Int).SetUint64(r.l1Block + testChallengePeriod),
+	})
+	r.asserters = append(r.asserters, asserter)
+	r.stakers[asserter] = uint64(len(r.assertions) - 1)
+}
+
+func (r *testRollup) Recover(context.Context) error { return nil }
+func (r *testRollup) Stake(context.Context, *big.Int) (*ethTypes.Receipt, error) {
+	return &ethTypes.Receipt{}, nil
+}
+func (r *testRollup) CreateAssertion(
+	_ context.Context, stateCommitment common.Hash, blockNum *big.Int, l1BlockHash common.Hash, l1BlockNum *big.Int,
+) (*ethTypes.Receipt, error) {
+	if r.onCreate != nil {
+		r.onCreate()
+	}
+	if r.createErr != nil {
+		return nil, r.createErr
+	}
+	r.l1Anchors = append(r.l1Anchors, rollupTypes.NewBlockID(l1BlockNum.Uint64(), l1BlockHash))
+	r.create(testValidatorAddr, stateCommitment, blockNum.Uint64())
+	return &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful}, nil
+}
+func (r *testRollup) AdvanceStake(_ context.Context, assertionID *big.Int) (*ethTypes.Receipt, error) {
+	r.stakers[testValidatorAddr] = assertionID.Uint64()
+	r.advanced = append(r.advanced, assertionID.Uint64())
+	return &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful}, nil
+}
+func (r *testRollup) ChallengeAssertion(
+	_ context.Context, _ [2]common.Address, assertionIDs [2]*big.Int,
+) (*ethTypes.Receipt, error) {
+	r.challenges = append(r.challenges, assertionIDs)
+	return &ethTypes.Receipt{Status: ethTypes.ReceiptStatusSuccessful}, nil
+}
+func (r *testRollup) ConfirmFirstUnresolvedAssertion(context.Context) (*ethTypes.Receipt, error) {
+	return &ethTypes.Receipt{}, nil
+}
+func (r *testRollup) RejectFirstUnresolvedAssertion(context.Context, common.Address) (*ethTypes.Receipt, error) {
+	return &ethTypes.Receipt{}, nil
+}
+func (r *testRollup) RemoveStake(context.Context, common.Address) (*ethTypes.Receipt, error) {
+	return &ethTypes.Receipt{}, nil
+}
+
+func (r *testRollup) GetRequiredStakeAmount(context.Context) (*big.Int, error) {
+	return common.Big0, nil
+}
+func (r *testRollup) GetStaker(_ context.Context, addr common.Address) (bindings.IRollupStaker, error) {
+	id, ok := r.stakers[addr]
+	return bindings.IRollupStaker{IsStaked: ok, AssertionID: new(big.Int).SetUint64(id)}, nil
+}
+func (r *testRollup) IsStakedOnAssertion(_ context.Context, assertionID *big.Int, addr common.Address) (bool, error) {
+	for id := r.stakers[addr]; ; id = r.assertions[id].Parent.Uint64() {
+		if id == assertionID.Uint64() {
+			return true, nil
+		}
+		if id == 0 {
+			return false, nil
+		}
+	}
+}
+func (r *testRollup) GetAssertion(_ context.Context, id *big.Int) (bindings.IRollupAssertion, error) {
+	return r.assertions[id.Uint64()], nil
+}
+func (r *testRollup) GetLastConfirmedAssertionID(context.Context) (*big.Int, error) {
+	return common.Big0, nil
+}
+func (r *testRollup) GetAssertionCreatedEvents(
+	_ context.Context, start, end uint64,
+) ([]*bindings.IRollupAssertionCreated, error) {
+	var events []*bindings.IRollupAssertionCreated
+	for id := 1; id < len(r.assertions); id++ {
+		if proposalTime := r.assertions[id].ProposalTime.Uint64(); proposalTime < start || proposalTime > end {
+			continue
+		}
+		events = append(events, &bindings.IRollupAssertionCreated{
+			AssertionID:  big.NewInt(int64(id)),
+			AsserterAddr: r.asserters[id],
+			VmHash:       r.assertions[id].StateCommitment,
+		})
+	}
+	return events, nil
+}
+func (r *testRollup) RequireFirstUnresolvedAssertionIsConfirmable(context.Context) (*bridge.UnsatisfiedCondition, error) {
+	return nil, nil
+}
+func (r *testRollup) RequireFirstUnresolvedAssertionIsRejectable(
+	context.Context, common.Address,
+) (*bridge.UnsatisfiedCondition, error) {
+	return nil, nil
+}
+
+// testL2Client serves a chain of empty blocks up to its safe head.
+// Block n is derived from L1 block 100+n, and has withdrawals root n.
+type testL2Client struct{ safe uint64 }
+
+func testHeader(blockNum uint64) *ethTypes.Header {
+	return &ethTypes.Header{Number: new(big.Int).SetUint64(blockNum), Root: common.BigToHash(big.NewInt(int64(blockNum)))}
+}
+
+func testL1BlockHash(l1BlockNum uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(l1BlockNum << 8))
+}
+
+func testStateCommitment(blockNum uint64) Bytes32 {
+	header := testHeader(blockNum)
+	return StateCommitment(&StateCommitmentV0{header.Hash(), header.Root})
+}
+
+func (c *testL2Client) EnsureDialed(context.Context) error          { return nil }
+func (c *testL2Client) BlockNumber(context.Context) (uint64, error) { return c.safe, nil }
+func (c *testL2Client) BlockByNumber(_ context.Context, number *big.Int) (*ethTypes.Block, error) {
+	return ethTypes.NewBlockWithHeader(testHeader(number.Uint64())), nil
+}
+func (c *testL2Client) HeaderByTag(context.Context, eth.BlockTag) (*ethTypes.Header, error) {
+	return testHeader(c.safe), nil
+}
+func (c *testL2Client) StorageAtHash(
+	_ context.Context, account common.Address, key common.Hash, blockHash common.Hash,
+) ([]byte, error) {
+	slots, err := getL1OracleSlots()
+	if err != nil || account != rollupTypes.L1OracleAddr {
+		return nil, err
+	}
+	l1BlockNum := 100 + testBlockNum(blockHash)
+	switch key {
+	case slots.number:
+		return common.BigToHash(new(big.Int).SetUint64(l1BlockNum)).Bytes(), nil
+	case slots.hash:
+		return testL1BlockHash(l1BlockNum).Bytes(), nil
+	}
+	return common.Hash{}.Bytes(), nil
+}
+func (c *testL2Client) StorageRoot(_ context.Context, account common.Address, blockHash common.Hash) (common.Hash, error) {
+	if account != rollupTypes.L2PortalAddr {
+		return common.Hash{}, nil
+	}
+	return common.BigToHash(new(big.Int).SetUint64(testBlockNum(blockHash))), nil
+}
+
+// Returns the number of the test block with the given hash.
+func testBlockNum(hash common.Hash) uint64 {
+	for num := uint64(0); ; num++ {
+		if testHeader(num).Hash() == hash {
+			return num
+		}
+	}
+}
+
+// testDataError is a revert of the rollup contract with the given error.
+type testDataError struct{ name string }
+
+func (e testDataError) Error() string { return "execution reverted" }
+func (e testDataError) ErrorData() interface{} {
+	return hexutil.Encode(crypto.Keccak256([]byte(e.name + "()"))[:4])
+}
+
+// testMetrics records the L2 block numbers of created assertions, and the number of resolved ones.
+type testMetrics struct {
+	created             []uint64
+	confirmed, rejected int
+}
+
+func (m *testMetrics) RecordAssertionCreated(l2BlockNum uint64) {
+	m.created = append(m.created, l2BlockNum)
+}
+func (m *testMetrics) RecordAssertionConfirmed() { m.confirmed++ }
+func (m *testMetrics) RecordAssertionRejected()  { m.rejected++ }
+
+// testBatchVerifier records the L1 blocks verified, failing with the error mapped to a block (if any).
+type testBatchVerifier struct {
+	verified []uint64
+	errs     map[uint64]error
+}
+
+func (b *testBatchVerifier) VerifyL1Block(_ context.Context, l1BlockNum uint64) error {
+	if err := b.errs[l1BlockNum]; err != nil {
+		return err
+	}
+	b.verified = append(b.verified, l1BlockNum)
+	return nil
+}
+
+func newTestValidator(t *testing.T, rollup *testRollup, safe uint64) *Validator {
+	l1State := eth.NewEthState()
+	require.NoError(t, l1State.OnLatest(context.Background(), &ethTypes.Header{Number: big.NewInt(1)}))
+	require.NoError(t, l1State.OnSafe(context.Background(), &ethTypes.Header{Number: big.NewInt(1)}))
+	v := NewValidator(testConfig{}, rollup, rollup, l1State, &testL2Client{safe}, nil, &testMetrics{}, nil)
+	require.NoError(t, v.rollback(context.Background()))
+	return v
+}
+
+func TestAdvanceStakeOntoCorrectAssertion(t *testing.T) {
+	rollup := newTestRollup()
+	rollup.create(testRivalAddr, testStateCommitment(5), 5)
+	v := newTestValidator(t, rollup, 8)
+
+	require.NoError(t, v.tryCreateAssertion(context.Background()))
+	require.Equal(t, []uint64{1}, rollup.advanced)
+	require.Len(t, rollup.assertions, 2)
+	require.Equal(t, uint64(5), v.lastCreatedAssertionAttrs.l2BlockNum)
+
+	// Once staked on the rival's assertion, new assertions build on top of it.
+	require.NoError(t, v.tryCreateAssertion(context.Background()))
+	require.Len(t, rollup.assertions, 3)
+	require.Equal(t, uint64(1), rollup.assertions[2].Parent.Uint64())
+	require.Equal(t, uint64(8), rollup.assertions[2].BlockNum.Uint64())
+	require.Equal(t, []uint64{8}, v.metrics.(*testMetrics).created)
+}
+
+func TestCreateSiblingOfIncorrectAssertion(t *testing.T) {
+	rollup := newTestRollup()
+	rollup.create(testRivalAddr, Bytes32{0xff}, 5)
+	v := newTestValidator(t, rollup, 8)
+
+	require.NoError(t, v.tryCreateAssertion(context.Background()))
+	require.Empty(t, rollup.advanced)
+	node, ok := v.assertions.get(1)
+	require.True(t, ok)
+	require.Equal(t, assertionIncorrect, node.status)
+	// The sibling must be for the same block as the rival assertion.
+	require.Len(t, rollup.assertions, 3)
+	require.Equal(t, uint64(0), rollup.assertions[2].Parent.Uint64())
+	require.Equal(t, uint64(5), rollup.assertions[2].BlockNum.Uint64())
+	require.Equal(t, testStateCommitment(5), rollup.assertions[2].StateCommitment)
+}
+
+func TestWaitForRivalAssertionBlock(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		rollup = newTestRollup()
+	)
+	rollup.create(testRivalAddr, Bytes32{0xff}, 9)
+	v := newTestValidator(t, rollup, 8)
+
+	require.NoError(t, v.tryCreateAssertion(ctx))
+	require.Empty(t, rollup.advanced)
+	require.Len(t, rollup.assertions, 2)
+
+	// Past half of the rival's challenge period, a sibling is created from the unsafe head.
+	l1Head := &ethTypes.Header{Number: big.NewInt(1 + testChallengePeriod/2)}
+	require.NoError(t, v.l1State.(*eth.EthState).OnLatest(ctx, l1Head))
+	require.NoError(t, v.tryCreateAssertion(ctx))
+	require.Len(t, rollup.assertions, 3)
+	require.Equal(t, uint64(9), rollup.assertions[2].BlockNum.Uint64())
+	require.Equal(t, testStateCommitment(9), rollup.assertions[2].StateCommitment)
+}
+
+func TestIndexAssertionsUpToSafeL1Head(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		rollup = newTestRollup()
+	)
+	rollup.l1Block = 2
+	rollup.create(testRivalAddr, testStateCommitment(5), 5)
+	v := newTestValidator(t, rollup, 8)
+	require.NoError(t, v.l1State.(*eth.EthState).OnLatest(ctx, &ethTypes.Header{Number: big.NewInt(2)}))
+
+	// The rival's assertion may still be re-orged out.
+	require.NoError(t, v.indexAssertions(ctx))
+	_, ok := v.assertions.get(1)
+	require.False(t, ok)
+
+	require.NoError(t, v.l1State.(*eth.EthState).OnSafe(ctx, &ethTypes.Header{Number: big.NewInt(2)}))
+	require.NoError(t, v.indexAssertions(ctx))
+	_, ok = v.assertions.get(1)
+	require.True(t, ok)
+}
+
+func TestVerifyBatchesUpToSafeL1Head(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		batches = &testBatchVerifier{errs: map[uint64]error{3: errors.New("l2 block not found")}}
+		v       = newTestValidator(t, newTestRollup(), 8)
+	)
+	v.batches = batches
+	require.NoError(t, v.l1State.(*eth.EthState).OnSafe(ctx, &ethTypes.Header{Number: big.NewInt(4)}))
+
+	// Other failures are retried on the next step.
+	require.NoError(t, v.verifyBatches(ctx))
+	require.Equal(t, []uint64{1, 2}, batches.verified)
+
+	// A mismatch means the local L2 chain can't be asserted from.
+	batches.errs[3] = derivation.DerivedBlockMismatchError{Msg: "mismatching tx count"}
+	require.ErrorAs(t, v.step(ctx), &unexpectedSystemStateError{})
+
+	delete(batches.errs, 3)
+	require.NoError(t, v.verifyBatches(ctx))
+	require.Equal(t, []uint64{1, 2, 3, 4}, batches.verified)
+}
+
+func TestChallengeIncorrectAssertion(t *testing.T) {
+	rollup := newTestRollup()
+	rollup.create(testRivalAddr, Bytes32{0xff}, 5)
+	v := newTestValidator(t, rollup, 8)
+
+	// The rival (created first) is challenged as the defender, by our (not yet indexed) sibling.
+	require.NoError(t, v.step(context.Background()))
+	require.Len(t, rollup.assertions, 3)
+	require.Equal(t, [][2]*big.Int{{big.NewInt(1), big.NewInt(2)}}, rollup.challenges)
+}
+
+func TestStateCommitmentEncoding(t *testing.T) {
+	v0 := &StateCommitmentV0{common.Hash{0x01}, common.Hash{0x02}}
+	v1 := &StateCommitmentV1{common.Hash{0x01}, common.Hash{0x02}, common.Hash{0x03}, 4, common.Hash{0x05}}
+	for _, commitment := range []VersionedStateCommitment{v0, v1} {
+		decoded, err := UnmarshalStateCommitment(commitment.Marshal())
+		require.NoError(t, err)
+		require.Equal(t, commitment, decoded)
+	}
+	require.NotEqual(t, StateCommitment(v0), StateCommitment(v1))
+
+	// The L1 block number is a big-endian uint256.
+	marshaled := v1.Marshal()
+	require.Len(t, marshaled, V1BufSize)
+	require.Equal(t, common.BigToHash(big.NewInt(4)).Bytes(), marshaled[V1L1BlockNumOffset:V1WithdrawalsRootOffset])
+	marshaled[V1L1BlockNumOffset] = 0x01
+	_, err := UnmarshalStateCommitment(marshaled)
+	require.ErrorIs(t, err, ErrInvalidStateCommitment)
+
+	unknown := make([]byte, V0BufSize)
+	unknown[V0VersionSize-1] = 2
+	_, err = UnmarshalStateCommitment(unknown)
+	require.ErrorIs(t, err, ErrInvalidStateCommitmentVersion)
+}
+
+func TestValidateGenesisV0(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		rollup  = newTestRollup()
+		genesis = testHeader(0)
+	)
+	// The genesis assertion is initialized with a V0 commitment, even when creating V1 assertions.
+	v := NewValidator(testConfig{stateCommitmentVersion: 1}, rollup, rollup, eth.NewEthState(), &testL2Client{}, nil, &testMetrics{}, nil)
+	require.NoError(t, v.validateGenesis(ctx))
+
+	rollup.assertions[0].StateCommitment = StateCommitment(
+		&StateCommitmentV1{genesis.Hash(), genesis.Root, testL1BlockHash(100), 100, common.Hash{}},
+	)
+	require.ErrorContains(t, v.validateGenesis(ctx), "mismatching initial state commitment")
+}
+
+func TestCreateAssertionWithL1Anchor(t *testing.T) {
+	rollup := newTestRollup()
+	v := newTestValidator(t, rollup, 8)
+
+	require.NoError(t, v.tryCreateAssertion(context.Background()))
+	require.Equal(t, []rollupTypes.BlockID{rollupTypes.NewBlockID(108, testL1BlockHash(108))}, rollup.l1Anchors)
+
+	// Anchors whose hash may no longer be available on L1 by the time the tx is included are left out.
+	rollup.l1Anchors = nil
+	l1Head := &ethTypes.Header{Number: big.NewInt(109 + maxL1BlockHashAge - l1AnchorSafetyMargin)}
+	require.NoError(t, v.l1State.(*eth.EthState).OnLatest(context.Background(), l1Head))
+	v.l2Client.(*testL2Client).safe = 9
+	require.NoError(t, v.tryCreateAssertion(context.Background()))
+	require.Equal(t, []rollupTypes.BlockID{rollupTypes.NewBlockID(0, common.Hash{})}, rollup.l1Anchors)
+}
+
+func TestCreateAssertionDetectsL1Reorg(t *testing.T) {
+	rollup := newTestRollup()
+	rollup.createErr = fmt.Errorf("failed to create the tx: %w", testDataError{bridge.MismatchingL1BlockhashesErr})
+	v := newTestValidator(t, rollup, 8)
+
+	err := v.step(context.Background())
+	require.ErrorAs(t, err, &l2ReorgDetectedError{})
+
+	// Other reverts aren't re-orgs.
+	rollup.createErr = testDataError{"EmptyAssertion"}
+	err = v.step(context.Background())
+	require.Error(t, err)
+	require.False(t, errors.As(err, &l2ReorgDetectedError{}))
+}
+
+func TestCreateAssertionWithExpiredL1Anchor(t *testing.T) {
+	rollup := newTestRollup()
+	rollup.createErr = testDataError{bridge.MismatchingL1BlockhashesErr}
+	v := newTestValidator(t, rollup, 8)
+	// The anchor's hash becomes unavailable before the tx is included.
+	rollup.onCreate = func() {
+		l1Head := &ethTypes.Header{Number: big.NewInt(108 + maxL1BlockHashAge)}
+		require.NoError(t, v.l1State.(*eth.EthState).OnLatest(context.Background(), l1Head))
+	}
+
+	err := v.step(context.Background())
+	require.ErrorAs(t, err, &l1AnchorTooOldError{})
+	require.False(t, errors.As(err, &l2ReorgDetectedError{}))
+}
@@ -0,0 +1,254 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+// Upper bound on the size of a response from an HTTP signer.
+const maxResponseSize = 1024 * 1024
+
+// Signs txs with a private key held in memory.
+type KeyProvider struct {
+	key    *ecdsa.PrivateKey
+	signer types.Signer
+}
+
+func NewKeyProvider(key *ecdsa.PrivateKey, chainID *big.Int) *KeyProvider {
+	return &KeyProvider{key, types.LatestSignerForChainID(chainID)}
+}
+
+func (p *KeyProvider) Address() common.Address { return crypto.PubkeyToAddress(p.key.PublicKey) }
+
+func (p *KeyProvider) SignTx(_ context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	return types.SignTx(tx, p.signer, p.key)
+}
+
+// Decrypts the key of an encrypted (geth-compatible) keystore file, and signs txs with it.
+func NewKeystoreProvider(path, passwordPath string, address common.Address, chainID *big.Int) (*KeyProvider, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	password, err := os.ReadFile(passwordPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore password: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, strings.TrimRight(string(password), "\r\n"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	if key.Address != address {
+		return nil, fmt.Errorf("keystore address %s does not correspond to account address %s", key.Address, address)
+	}
+	return NewKeyProvider(key.PrivateKey, chainID), nil
+}
+
+// Signs txs with Clef (`account_signTransaction`). Blob txs are unsupported.
+type ClefProvider struct {
+	clef    *external.ExternalSigner
+	address common.Address
+	signer  types.Signer
+}
+
+func NewClefProvider(endpoint string, address common.Address, chainID *big.Int) (*ClefProvider, error) {
+	clef, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize external signer from clef endpoint: %w", err)
+	}
+	return &ClefProvider{clef, address, types.LatestSignerForChainID(chainID)}, nil
+}
+
+func (p *ClefProvider) Address() common.Address { return p.address }
+
+func (p *ClefProvider) SignTx(_ context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	signed, err := p.clef.SignTx(accounts.Account{Address: p.address}, tx, p.signer.ChainID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign tx with clef: %w", err)
+	}
+	return withRemoteSignature(p.signer, tx, signed, p.address)
+}
+
+// Signs txs with Web3Signer (`eth_signTransaction`), or any signer implementing the same JSON-RPC method.
+type Web3SignerProvider struct {
+	client  *rpc.Client
+	address common.Address
+	signer  types.Signer
+}
+
+func NewWeb3SignerProvider(endpoint string, address common.Address, chainID *big.Int) (*Web3SignerProvider, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial web3signer: %w", err)
+	}
+	return &Web3SignerProvider{client, address, types.LatestSignerForChainID(chainID)}, nil
+}
+
+func (p *Web3SignerProvider) Address() common.Address { return p.address }
+
+// Arguments of `eth_signTransaction`.
+type signTxArgs struct {
+	From                 common.Address    `json:"from"`
+	To                   *common.Address   `json:"to,omitempty"`
+	Gas                  hexutil.Uint64    `json:"gas"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big      `json:"value"`
+	Nonce                hexutil.Uint64    `json:"nonce"`
+	Data                 hexutil.Bytes     `json:"data"`
+	ChainID              *hexutil.Big      `json:"chainId"`
+	AccessList           *types.AccessList `json:"accessList,omitempty"`
+	MaxFeePerBlobGas     *hexutil.Big      `json:"maxFeePerBlobGas,omitempty"`
+	BlobVersionedHashes  []common.Hash     `json:"blobVersionedHashes,omitempty"`
+}
+
+func (p *Web3SignerProvider) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	accessList := tx.AccessList()
+	args := signTxArgs{
+		From:                 p.address,
+		To:                   tx.To(),
+		Gas:                  hexutil.Uint64(tx.Gas()),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+		Value:                (*hexutil.Big)(tx.Value()),
+		Nonce:                hexutil.Uint64(tx.Nonce()),
+		Data:                 tx.Data(),
+		ChainID:              (*hexutil.Big)(p.signer.ChainID()),
+		AccessList:           &accessList,
+	}
+	if tx.Type() == types.BlobTxType {
+		args.MaxFeePerBlobGas = (*hexutil.Big)(tx.BlobGasFeeCap())
+		args.BlobVersionedHashes = tx.BlobHashes()
+	}
+	var raw hexutil.Bytes
+	if err := p.client.CallContext(ctx, &raw, "eth_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("failed to sign tx with web3signer: %w", err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode tx signed by web3signer: %w", err)
+	}
+	return withRemoteSignature(p.signer, tx, signed, p.address)
+}
+
+// Signs txs with a generic HTTP signer, which only ever sees tx hashes (e.g. a proxy to a KMS).
+// Each tx is signed by POSTing `{"address", "chainId", "hash"}` (hash being the tx's signing hash) to the
+// endpoint, which must respond with `{"signature"}`: a 65-byte secp256k1 signature in the [R || S || V] format.
+type HTTPProvider struct {
+	endpoint string
+	client   *http.Client
+	address  common.Address
+	signer   types.Signer
+}
+
+func NewHTTPProvider(endpoint string, address common.Address, chainID *big.Int) *HTTPProvider {
+	return &HTTPProvider{endpoint, http.DefaultClient, address, types.LatestSignerForChainID(chainID)}
+}
+
+func (p *HTTPProvider) Address() common.Address { return p.address }
+
+type HTTPSignRequest struct {
+	Address common.Address `json:"address"`
+	ChainID *hexutil.Big   `json:"chainId"`
+	Hash    common.Hash    `json:"hash"`
+}
+
+type HTTPSignResponse struct {
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+func (p *HTTPProvider) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	body, err := json.Marshal(HTTPSignRequest{p.address, (*hexutil.Big)(p.signer.ChainID()), p.signer.Hash(tx)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sign request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign tx: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to sign tx: unexpected status %s", resp.Status)
+	}
+	var signResp HTTPSignResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&signResp); err != nil {
+		return nil, fmt.Errorf("failed to decode sign response: %w", err)
+	}
+	sig := signResp.Signature
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 { // Tolerate Ethereum-style recovery ids.
+		sig = append(hexutil.Bytes{}, sig...)
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	signed, err := tx.WithSignature(p.signer, sig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if err := checkSender(p.signer, signed, p.address); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// Applies the signature of a tx signed remotely to the original tx, so that the remote signer can't alter the tx
+// (and the original's blob sidecar, if any, is kept).
+func withRemoteSignature(signer types.Signer, tx, signed *types.Transaction, address common.Address) (*types.Transaction, error) {
+	if signed.Type() != tx.Type() {
+		return nil, fmt.Errorf("remote signer changed tx type from %d to %d", tx.Type(), signed.Type())
+	}
+	var (
+		v, r, s = signed.RawSignatureValues()
+		sig     = make([]byte, crypto.SignatureLength)
+	)
+	if v.BitLen() > 1 || r.BitLen() > 256 || s.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid signature values (v=%s)", v)
+	}
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:64])
+	sig[crypto.RecoveryIDOffset] = byte(v.Uint64())
+	result, err := tx.WithSignature(signer, sig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if err := checkSender(signer, result, address); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Checks that the tx was signed by the given address.
+func checkSender(signer types.Signer, tx *types.Transaction, address common.Address) error {
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		return fmt.Errorf("failed to recover signer: %w", err)
+	}
+	if sender != address {
+		return fmt.Errorf("tx signed by %s instead of %s", sender, address)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var testChainID = big.NewInt(1337)
+
+func newTestTx() *types.Transaction {
+	to := common.HexToAddress("0x01")
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   testChainID,
+		Nonce:     3,
+		To:        &to,
+		Gas:       21000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Value:     big.NewInt(7),
+		Data:      []byte{0x1},
+	})
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return key
+}
+
+// Checks that the provider signs txs as its address, without altering them.
+func requireSigns(t *testing.T, p Provider) {
+	tx := newTestTx()
+	signed, err := SignerFn(p)(context.Background(), p.Address(), tx)
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(testChainID)
+	sender, err := types.Sender(signer, signed)
+	require.NoError(t, err)
+	require.Equal(t, p.Address(), sender)
+	require.Equal(t, signer.Hash(tx), signer.Hash(signed))
+
+	_, err = SignerFn(p)(context.Background(), common.HexToAddress("0x02"), tx)
+	require.Error(t, err)
+}
+
+func TestKeyProvider(t *testing.T) {
+	requireSigns(t, NewKeyProvider(newTestKey(t), testChainID))
+}
+
+func TestKeystoreProvider(t *testing.T) {
+	var (
+		dir          = t.TempDir()
+		key          = newTestKey(t)
+		address      = crypto.PubkeyToAddress(key.PublicKey)
+		keystorePath = filepath.Join(dir, "keystore.json")
+		passwordPath = filepath.Join(dir, "password.txt")
+	)
+	keyJSON, err := keystore.EncryptKey(
+		&keystore.Key{Id: uuid.New(), Address: address, PrivateKey: key}, "secret", keystore.LightScryptN, keystore.LightScryptP,
+	)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keystorePath, keyJSON, 0o600))
+	require.NoError(t, os.WriteFile(passwordPath, []byte("secret\n"), 0o600))
+
+	p, err := NewKeystoreProvider(keystorePath, passwordPath, address, testChainID)
+	require.NoError(t, err)
+	requireSigns(t, p)
+
+	_, err = NewKeystoreProvider(keystorePath, passwordPath, common.HexToAddress("0x02"), testChainID)
+	require.Error(t, err)
+	require.NoError(t, os.WriteFile(passwordPath, []byte("wrong"), 0o600))
+	_, err = NewKeystoreProvider(keystorePath, passwordPath, address, testChainID)
+	require.Error(t, err)
+}
+
+// web3Signer is a mock of Web3Signer's `eth_signTransaction`.
+type web3Signer struct{ key *ecdsa.PrivateKey }
+
+func (s *web3Signer) SignTransaction(args signTxArgs) (hexutil.Bytes, error) {
+	tx, err := types.SignNewTx(s.key, types.LatestSignerForChainID(args.ChainID.ToInt()), &types.DynamicFeeTx{
+		ChainID:   args.ChainID.ToInt(),
+		Nonce:     uint64(args.Nonce),
+		To:        args.To,
+		Gas:       uint64(args.Gas),
+		GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+		GasFeeCap: args.MaxFeePerGas.ToInt(),
+		Value:     args.Value.ToInt(),
+		Data:      args.Data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+func newWeb3Signer(t *testing.T, key *ecdsa.PrivateKey) string {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &web3Signer{key}))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return httpServer.URL
+}
+
+func TestWeb3SignerProvider(t *testing.T) {
+	key := newTestKey(t)
+	p, err := NewWeb3SignerProvider(newWeb3Signer(t, key), crypto.PubkeyToAddress(key.PublicKey), testChainID)
+	require.NoError(t, err)
+	requireSigns(t, p)
+
+	// Txs signed by another account are rejected.
+	p, err = NewWeb3SignerProvider(newWeb3Signer(t, newTestKey(t)), crypto.PubkeyToAddress(key.PublicKey), testChainID)
+	require.NoError(t, err)
+	_, err = p.SignTx(context.Background(), newTestTx())
+	require.Error(t, err)
+}
+
+// Returns the URL of a mock HTTP signer.
+func newHTTPSigner(t *testing.T, key *ecdsa.PrivateKey) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req HTTPSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Address != crypto.PubkeyToAddress(key.PublicKey) {
+			http.Error(w, "unknown address", http.StatusNotFound)
+			return
+		}
+		sig, err := crypto.Sign(req.Hash.Bytes(), key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sig[crypto.RecoveryIDOffset] += 27
+		_ = json.NewEncoder(w).Encode(HTTPSignResponse{sig})
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestHTTPProvider(t *testing.T) {
+	var (
+		key      = newTestKey(t)
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		endpoint = newHTTPSigner(t, key)
+	)
+	requireSigns(t, NewHTTPProvider(endpoint, address, testChainID))
+
+	_, err := NewHTTPProvider(endpoint, common.HexToAddress("0x02"), testChainID).SignTx(context.Background(), newTestTx())
+	require.Error(t, err)
+}
+
+func TestConfigValidate(t *testing.T) {
+	key := newTestKey(t)
+	require.NoError(t, Config{PrivateKey: key, Address: crypto.PubkeyToAddress(key.PublicKey)}.Validate())
+	require.Error(t, Config{PrivateKey: key}.Validate())
+	require.Error(t, Config{}.Validate())
+
+	// Remote signers require an address.
+	cfg := Config{Endpoint: "http://localhost:8550"}
+	require.Equal(t, ClefType, cfg.GetType())
+	require.Error(t, cfg.Validate())
+	cfg.Type, cfg.Address = Web3SignerType, common.HexToAddress("0x01")
+	require.NoError(t, cfg.Validate())
+	require.Error(t, Config{Type: KeystoreType, Address: cfg.Address}.Validate())
+	require.Error(t, Config{Type: "kms", Address: cfg.Address}.Validate())
+}
+
+func TestToPrivateKey(t *testing.T) {
+	key := newTestKey(t)
+	hexKey := common.Bytes2Hex(crypto.FromECDSA(key))
+	for _, s := range []string{hexKey, "0x" + hexKey} {
+		parsed, err := toPrivateKey(s)
+		require.NoError(t, err)
+		require.Equal(t, key.D, parsed.D)
+	}
+	parsed, err := toPrivateKey("")
+	require.NoError(t, err)
+	require.Nil(t, parsed)
+	for _, s := range []string{"0", "0x", hexKey[2:], "0xzz" + hexKey[4:]} {
+		_, err := toPrivateKey(s)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,191 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+
+	"github.com/specularL2/specular/services/sidecar/rollup/rpc/eth/txmgr"
+	"github.com/specularL2/specular/services/sidecar/utils/fmt"
+)
+
+const (
+	KeyType        = "key"        // Txs are signed with a private key held by the sidecar.
+	KeystoreType   = "keystore"   // Txs are signed with a key decrypted from an encrypted keystore file.
+	ClefType       = "clef"       // Txs are signed by Clef (`account_signTransaction`).
+	Web3SignerType = "web3signer" // Txs are signed by Web3Signer (`eth_signTransaction`).
+	HTTPType       = "http"       // Tx hashes are signed by a generic HTTP signer (e.g. a KMS proxy).
+)
+
+// Provider signs txs on behalf of a single account.
+type Provider interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error)
+}
+
+// Signer configuration
+type Config struct {
+	// How txs are signed. If empty, inferred from the other fields (clef if an endpoint is given, key otherwise).
+	Type string `toml:"type,omitempty"`
+	// The address of the signing account.
+	Address common.Address `toml:"address,omitempty"`
+	// The private key of the signing account (key signer only).
+	PrivateKey *ecdsa.PrivateKey `toml:"-"`
+	// The endpoint of the remote signer (clef, web3signer and http signers only).
+	Endpoint string `toml:"endpoint,omitempty"`
+	// The path of the encrypted keystore file (keystore signer only).
+	KeystorePath string `toml:"keystore_path,omitempty"`
+	// The path of the file holding the keystore passphrase (keystore signer only).
+	PasswordPath string `toml:"password_path,omitempty"`
+}
+
+func (c Config) GetType() string {
+	switch {
+	case c.Type != "":
+		return c.Type
+	case c.Endpoint != "":
+		return ClefType
+	default:
+		return KeyType
+	}
+}
+
+func (c Config) Validate() error {
+	switch c.GetType() {
+	case KeyType:
+		if c.PrivateKey == nil {
+			return errors.New("missing private key (or a remote signer endpoint)")
+		}
+		if c.Address != crypto.PubkeyToAddress(c.PrivateKey.PublicKey) {
+			return errors.New("private key does not correspond to account address")
+		}
+		return nil
+	case KeystoreType:
+		if c.KeystorePath == "" || c.PasswordPath == "" {
+			return errors.New("missing keystore or password path")
+		}
+	case ClefType, Web3SignerType, HTTPType:
+		if c.Endpoint == "" {
+			return fmt.Errorf("missing endpoint for %s signer", c.GetType())
+		}
+	default:
+		return fmt.Errorf("unsupported signer type: %s", c.Type)
+	}
+	if c.Address == (common.Address{}) {
+		return fmt.Errorf("missing account address for %s signer", c.GetType())
+	}
+	return nil
+}
+
+// Creates the signer provider for the given configuration.
+func NewProvider(cfg Config, chainID *big.Int) (Provider, error) {
+	switch cfg.GetType() {
+	case KeyType:
+		return NewKeyProvider(cfg.PrivateKey, chainID), nil
+	case KeystoreType:
+		return NewKeystoreProvider(cfg.KeystorePath, cfg.PasswordPath, cfg.Address, chainID)
+	case ClefType:
+		return NewClefProvider(cfg.Endpoint, cfg.Address, chainID)
+	case Web3SignerType:
+		return NewWeb3SignerProvider(cfg.Endpoint, cfg.Address, chainID)
+	case HTTPType:
+		return NewHTTPProvider(cfg.Endpoint, cfg.Address, chainID), nil
+	default:
+		return nil, fmt.Errorf("unsupported signer type: %s", cfg.Type)
+	}
+}
+
+// Adapts a provider for use by a `txmgr.TxManager`.
+func SignerFn(p Provider) txmgr.SignerFn {
+	return func(ctx context.Context, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if address != p.Address() {
+			return nil, fmt.Errorf("signer for %s cannot sign for %s", p.Address(), address)
+		}
+		return p.SignTx(ctx, tx)
+	}
+}
+
+const (
+	TypeFlagName         = "signer"
+	AddressFlagName      = "account-addr"
+	PrivateKeyFlagName   = "private-key"
+	EndpointFlagName     = "signer-endpoint"
+	ClefEndpointFlagName = "clef-endpoint"
+	KeystorePathFlagName = "keystore-path"
+	PasswordPathFlagName = "keystore-password-path"
+)
+
+func CLIFlags(namespace string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  namespace + "." + TypeFlagName,
+			Usage: "How txs are signed: key, keystore, clef, web3signer or http (inferred from the other flags if empty)",
+		},
+		&cli.StringFlag{
+			Name:  namespace + "." + AddressFlagName,
+			Usage: "The account address (required unless signing with a private key)",
+		},
+		&cli.StringFlag{
+			Name:  namespace + "." + PrivateKeyFlagName,
+			Usage: "The private key of the account",
+		},
+		&cli.StringFlag{
+			Name:  namespace + "." + EndpointFlagName,
+			Usage: "The endpoint of the remote (clef, web3signer or http) signer",
+		},
+		&cli.StringFlag{
+			Name:  namespace + "." + ClefEndpointFlagName,
+			Usage: "The endpoint of the Clef instance that should be used as a signer (alias of the clef signer endpoint)",
+		},
+		&cli.StringFlag{
+			Name:  namespace + "." + KeystorePathFlagName,
+			Usage: "The path of the encrypted keystore file",
+		},
+		&cli.StringFlag{
+			Name:  namespace + "." + PasswordPathFlagName,
+			Usage: "The path of the file holding the keystore passphrase",
+		},
+	}
+}
+
+func NewConfigFromCLI(cliCtx *cli.Context, namespace string) (Config, error) {
+	privateKey, err := toPrivateKey(cliCtx.String(namespace + "." + PrivateKeyFlagName))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := Config{
+		Type:         cliCtx.String(namespace + "." + TypeFlagName),
+		Address:      common.HexToAddress(cliCtx.String(namespace + "." + AddressFlagName)),
+		PrivateKey:   privateKey,
+		Endpoint:     cliCtx.String(namespace + "." + EndpointFlagName),
+		KeystorePath: cliCtx.String(namespace + "." + KeystorePathFlagName),
+		PasswordPath: cliCtx.String(namespace + "." + PasswordPathFlagName),
+	}
+	if clefEndpoint := cliCtx.String(namespace + "." + ClefEndpointFlagName); clefEndpoint != "" {
+		cfg.Type, cfg.Endpoint = ClefType, clefEndpoint
+	}
+	if cfg.PrivateKey != nil && cfg.GetType() == KeyType {
+		cfg.Address = crypto.PubkeyToAddress(cfg.PrivateKey.PublicKey)
+	}
+	return cfg, nil
+}
+
+// Parses a hex-encoded private key, with or without a 0x prefix.
+func toPrivateKey(keyStr string) (*ecdsa.PrivateKey, error) {
+	if keyStr == "" {
+		return nil, nil
+	}
+	keyStr = strings.TrimPrefix(strings.TrimPrefix(keyStr, "0x"), "0X")
+	secretKey, err := crypto.HexToECDSA(keyStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return secretKey, nil
+}
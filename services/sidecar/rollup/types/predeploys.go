@@ -0,0 +1,9 @@
+package types
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Addresses of the L2 predeploys read by the sidecar (as listed in `ops/predeploys`).
+var (
+	L1OracleAddr = common.HexToAddress("0x2A00000000000000000000000000000000000010")
+	L2PortalAddr = common.HexToAddress("0x2A00000000000000000000000000000000000011")
+)